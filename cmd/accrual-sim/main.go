@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mbiwapa/gophermart.git/config"
+	accrualsim "github.com/mbiwapa/gophermart.git/internal/app/accrual-sim"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func main() {
+	mainCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log := logger.NewLogger()
+
+	log.Info("Loading configuration...")
+	conf := config.MustLoadAccrualSimConfig()
+
+	server := &http.Server{
+		Addr:    conf.Addr,
+		Handler: accrualsim.New(conf, log).Router(),
+	}
+
+	g, gCtx := errgroup.WithContext(mainCtx)
+	g.Go(func() error {
+		log.Info("Starting accrual simulator: ", log.StringField("Addr", server.Addr))
+		return server.ListenAndServe()
+	})
+	g.Go(func() error {
+		<-gCtx.Done()
+		log.Info("Shutdown accrual simulator!")
+		return server.Shutdown(context.Background())
+	})
+	if err := g.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Exit reason: ", log.ErrorField(err))
+		os.Exit(1)
+	}
+	log.Info("Good bye!")
+}
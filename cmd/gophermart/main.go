@@ -11,8 +11,12 @@ import (
 
 	"github.com/mbiwapa/gophermart.git/config"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/server"
+	"github.com/mbiwapa/gophermart.git/internal/app/storage"
 	"github.com/mbiwapa/gophermart.git/internal/app/workers"
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/events"
+	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
 
@@ -27,16 +31,21 @@ func main() {
 	log.Info("Loading configuration...")
 	conf := config.MustLoadConfig()
 
-	db, err := pgxpool.New(mainCtx, conf.DB)
-	if err != nil {
-		log.Error("Failed to connect to database", log.ErrorField(err))
+	var repositories *storage.Repositories
+	switch conf.Storage {
+	case config.StoragePostgres:
+		repositories = mustOpenPostgre(mainCtx, conf, log)
+	case config.StorageMemory:
+		if len(conf.Command) > 0 {
+			log.Error("Commands need the postgres storage", log.StringField("storage", conf.Storage))
+			os.Exit(2)
+		}
+		log.Info("Using in-memory storage, the data is lost on exit")
+		repositories = storage.NewMemory(log)
+	default:
+		log.Error("Unknown storage", log.StringField("storage", conf.Storage))
 		os.Exit(1)
 	}
-	go func() {
-		<-mainCtx.Done()
-		log.Info("Closing database connection...")
-		db.Close()
-	}()
 
 	log.Info("Create order queue chanel...")
 	orderQueue := make(chan entity.Order, 100)
@@ -57,21 +66,67 @@ func main() {
 		log.Info("Error chanel is closed")
 	}()
 
+	log.Info("Create event broker...")
+	broker := events.NewBroker(log)
+
+	accrualMetrics := httpc.NewMetrics()
+
 	log.Info("Creating HTTP server...")
-	srv, err := server.New(mainCtx, conf, log, orderQueue, db)
+	srv, err := server.New(mainCtx, conf, log, orderQueue, repositories, broker, accrualMetrics)
 	if err != nil {
 		log.Error("Failed to create HTTP server", log.ErrorField(err))
 		os.Exit(1)
 	}
 	srv.Run()
 
-	orderWorker := workers.NewOrderWorker(mainCtx, log, orderQueue, errorChan, balanceQueue, db, conf.AccrualAdr)
+	accrualProviders, err := conf.LoadAccrualProviders()
+	if err != nil {
+		log.Error("Failed to load accrual providers", log.ErrorField(err))
+		os.Exit(1)
+	}
+	orderWorker := workers.NewOrderWorker(mainCtx, log, orderQueue, errorChan, balanceQueue, repositories, accrualProviders, conf.AccrualTransport, accrualMetrics, conf.AccrualEngine, broker)
 	orderWorker.Run()
 
-	balanceWorker := workers.NewBalanceWorker(mainCtx, log, balanceQueue, errorChan, db)
+	balanceWorker := workers.NewBalanceWorker(mainCtx, log, balanceQueue, errorChan, repositories, broker)
 	balanceWorker.Run()
 
+	webhookWorker := workers.NewWebhookWorker(mainCtx, log, errorChan, repositories)
+	webhookWorker.Run()
+
 	<-mainCtx.Done()
 	time.Sleep(3 * time.Second)
 	log.Info("Good bye!")
 }
+
+// mustOpenPostgre connects to the database and returns its repositories. A command given instead of
+// starting the server is run and the process exits, otherwise the schema must be current.
+func mustOpenPostgre(ctx context.Context, conf *config.Config, log *logger.Logger) *storage.Repositories {
+	db, err := pgxpool.New(ctx, conf.DB)
+	if err != nil {
+		log.Error("Failed to connect to database", log.ErrorField(err))
+		os.Exit(1)
+	}
+	go func() {
+		<-ctx.Done()
+		log.Info("Closing database connection...")
+		db.Close()
+	}()
+
+	migrator, err := postgre.NewMigrator(db, log)
+	if err != nil {
+		log.Error("Failed to load migrations", log.ErrorField(err))
+		os.Exit(1)
+	}
+	if len(conf.Command) > 0 {
+		os.Exit(runCommand(ctx, conf.Command, migrator, os.Stdout))
+	}
+
+	log.Info("Checking database schema...")
+	err = migrator.Check(ctx)
+	if err != nil {
+		log.Error("Database schema is not current, the server is not started", log.ErrorField(err))
+		os.Exit(1)
+	}
+
+	return storage.NewPostgre(db, log)
+}
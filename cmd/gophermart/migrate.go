@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
+)
+
+// commandUsage lists the commands run instead of the server.
+const commandUsage = "usage: gophermart [flags] migrate up|down|status"
+
+// runCommand runs the command given instead of starting the server and returns the exit code.
+func runCommand(ctx context.Context, command []string, migrator *postgre.Migrator, out io.Writer) int {
+	if len(command) != 2 || command[0] != "migrate" {
+		fmt.Fprintln(out, commandUsage)
+		return 2
+	}
+
+	switch command[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if errors.Is(err, postgre.ErrNoMigrationApplied) {
+			fmt.Fprintln(out, "no migration to revert")
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		fmt.Fprintf(out, "reverted %04d %s\n", reverted.Version, reverted.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Unknown {
+				appliedAt += " (unknown to this binary)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		_ = w.Flush()
+	default:
+		fmt.Fprintln(out, commandUsage)
+		return 2
+	}
+	return 0
+}
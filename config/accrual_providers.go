@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// AccrualProvider Конфигурация системы начислений партнёра
+type AccrualProvider struct {
+	// Name Имя системы для журналов
+	Name string `json:"name"`
+	// URL Адрес системы начислений
+	URL string `json:"url"`
+	// Path Шаблон пути заказа, {number} заменяется номером заказа, по умолчанию /api/orders/{number}
+	Path string `json:"path"`
+	// AuthHeader и AuthToken Заголовок и значение авторизации, передаются с каждым запросом
+	AuthHeader string `json:"auth_header"`
+	AuthToken  string `json:"auth_token"`
+	// RateLimit Количество запросов в минуту, 0 без ограничения
+	RateLimit int `json:"rate_limit"`
+	// Prefixes Префиксы номеров заказов, направляемых в систему
+	Prefixes []string `json:"prefixes"`
+	// Stores Магазины, заказы которых направляются в систему
+	Stores []string `json:"stores"`
+	// Fields Имена полей ответа, по умолчанию order, status и accrual
+	Fields AccrualProviderFields `json:"fields"`
+	// Statuses Соответствие статусов системы статусам заказа REGISTERED, PROCESSING, INVALID, PROCESSED,
+	// без него статусы берутся как есть
+	Statuses map[string]string `json:"statuses"`
+	// ClientCert, ClientKey, CACert и Proxy Настройки mTLS и прокси системы, заменяют общие флаги -accrual-*
+	ClientCert string `json:"client_cert"`
+	ClientKey  string `json:"client_key"`
+	CACert     string `json:"ca_cert"`
+	Proxy      string `json:"proxy"`
+}
+
+// AccrualProviderFields Имена полей ответа системы начислений
+type AccrualProviderFields struct {
+	Order   string `json:"order"`
+	Status  string `json:"status"`
+	Accrual string `json:"accrual"`
+}
+
+// accrualStatuses Статусы, в которые можно отобразить статусы системы начислений
+var accrualStatuses = map[string]bool{"REGISTERED": true, "PROCESSING": true, "INVALID": true, "PROCESSED": true}
+
+// LoadAccrualProviders возвращает системы начислений из файла -accrual-providers,
+// без файла единственная система по адресу -r принимает все заказы
+func (c *Config) LoadAccrualProviders() ([]AccrualProvider, error) {
+	if c.AccrualProvidersFile == "" {
+		return []AccrualProvider{{Name: "default", URL: c.AccrualAdr}}, nil
+	}
+	data, err := os.ReadFile(c.AccrualProvidersFile)
+	if err != nil {
+		return nil, fmt.Errorf("read accrual providers: %w", err)
+	}
+	var providers []AccrualProvider
+	err = json.Unmarshal(data, &providers)
+	if err != nil {
+		return nil, fmt.Errorf("parse accrual providers: %w", err)
+	}
+	err = validateAccrualProviders(providers)
+	if err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// validateAccrualProviders проверяет, что маршруты не пересекаются и статусы известны
+func validateAccrualProviders(providers []AccrualProvider) error {
+	if len(providers) == 0 {
+		return errors.New("no accrual providers configured")
+	}
+	names := make(map[string]bool, len(providers))
+	prefixes := make(map[string]string)
+	stores := make(map[string]string)
+	for _, provider := range providers {
+		if provider.Name == "" || provider.URL == "" {
+			return errors.New("accrual provider must have a name and an url")
+		}
+		if names[provider.Name] {
+			return fmt.Errorf("accrual provider %q is duplicated", provider.Name)
+		}
+		names[provider.Name] = true
+		for _, prefix := range provider.Prefixes {
+			if other, ok := prefixes[prefix]; ok {
+				return fmt.Errorf("prefix %q is routed to %q and %q", prefix, other, provider.Name)
+			}
+			prefixes[prefix] = provider.Name
+		}
+		for _, store := range provider.Stores {
+			if other, ok := stores[store]; ok {
+				return fmt.Errorf("store %q is routed to %q and %q", store, other, provider.Name)
+			}
+			stores[store] = provider.Name
+		}
+		for external, status := range provider.Statuses {
+			if !accrualStatuses[status] {
+				return fmt.Errorf("accrual provider %q maps status %q to unknown status %q", provider.Name, external, status)
+			}
+		}
+	}
+	return nil
+}
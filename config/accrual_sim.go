@@ -0,0 +1,96 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+)
+
+// AccrualSimConfig Структура с конфигурацией симулятора системы расчёта начислений
+type AccrualSimConfig struct {
+	Addr string
+
+	UnregisteredFor time.Duration
+	RegisteredFor   time.Duration
+	ProcessingFor   time.Duration
+
+	InvalidRate   float64
+	NoAccrualRate float64
+	AccrualMin    float64
+	AccrualMax    float64
+
+	LatencyMin time.Duration
+	LatencyMax time.Duration
+	RateLimit  int
+	ErrorRate  float64
+	Seed       int64
+}
+
+// MustLoadAccrualSimConfig загрузка конфигурации симулятора
+func MustLoadAccrualSimConfig() *AccrualSimConfig {
+	var config AccrualSimConfig
+	flag.StringVar(&config.Addr, "a", "localhost:8080", "Адрес порт симулятора")
+	flag.DurationVar(
+		&config.UnregisteredFor,
+		"unregistered-for",
+		0,
+		"Время с первого запроса, в течение которого заказ не зарегистрирован (ответ 204)",
+	)
+	flag.DurationVar(&config.RegisteredFor, "registered-for", time.Second, "Время в статусе REGISTERED")
+	flag.DurationVar(&config.ProcessingFor, "processing-for", 2*time.Second, "Время в статусе PROCESSING")
+	flag.Float64Var(
+		&config.InvalidRate,
+		"invalid-rate",
+		0.1,
+		"Доля заказов, получающих статус INVALID, номера не прошедшие проверку Луна всегда INVALID",
+	)
+	flag.Float64Var(&config.NoAccrualRate, "no-accrual-rate", 0.1, "Доля заказов в статусе PROCESSED без начисления")
+	flag.Float64Var(&config.AccrualMin, "accrual-min", 10, "Минимальное начисление")
+	flag.Float64Var(&config.AccrualMax, "accrual-max", 1000, "Максимальное начисление")
+	flag.DurationVar(&config.LatencyMin, "latency-min", 0, "Минимальная задержка ответа")
+	flag.DurationVar(&config.LatencyMax, "latency-max", 0, "Максимальная задержка ответа")
+	flag.IntVar(&config.RateLimit, "rate-limit", 0, "Количество запросов в минуту, после которого отвечает 429, 0 без ограничения")
+	flag.Float64Var(&config.ErrorRate, "error-rate", 0, "Доля запросов, на которые отвечает 500")
+	flag.Int64Var(&config.Seed, "seed", 1, "Зерно, от которого зависят статусы, начисления, задержки и ошибки")
+	flag.Parse()
+
+	envAddr := os.Getenv("ACCRUAL_SIM_ADDRESS")
+	if envAddr != "" {
+		config.Addr = envAddr
+	}
+	for name, target := range map[string]*time.Duration{
+		"ACCRUAL_SIM_UNREGISTERED_FOR": &config.UnregisteredFor,
+		"ACCRUAL_SIM_REGISTERED_FOR":   &config.RegisteredFor,
+		"ACCRUAL_SIM_PROCESSING_FOR":   &config.ProcessingFor,
+		"ACCRUAL_SIM_LATENCY_MIN":      &config.LatencyMin,
+		"ACCRUAL_SIM_LATENCY_MAX":      &config.LatencyMax,
+	} {
+		value, err := time.ParseDuration(os.Getenv(name))
+		if err == nil {
+			*target = value
+		}
+	}
+	for name, target := range map[string]*float64{
+		"ACCRUAL_SIM_INVALID_RATE":    &config.InvalidRate,
+		"ACCRUAL_SIM_NO_ACCRUAL_RATE": &config.NoAccrualRate,
+		"ACCRUAL_SIM_ACCRUAL_MIN":     &config.AccrualMin,
+		"ACCRUAL_SIM_ACCRUAL_MAX":     &config.AccrualMax,
+		"ACCRUAL_SIM_ERROR_RATE":      &config.ErrorRate,
+	} {
+		value, err := strconv.ParseFloat(os.Getenv(name), 64)
+		if err == nil {
+			*target = value
+		}
+	}
+	envRateLimit, err := strconv.Atoi(os.Getenv("ACCRUAL_SIM_RATE_LIMIT"))
+	if err == nil {
+		config.RateLimit = envRateLimit
+	}
+	envSeed, err := strconv.ParseInt(os.Getenv("ACCRUAL_SIM_SEED"), 10, 64)
+	if err == nil {
+		config.Seed = envSeed
+	}
+
+	return &config
+}
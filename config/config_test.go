@@ -24,3 +24,37 @@ func TestMustLoadConfig(t *testing.T) {
 	assert.Equal(t, "host=example.com port=5432", cfg.DB)
 	assert.Equal(t, "testSecretKey", cfg.SecretKey)
 }
+
+func TestConfig_LoadAccrualProviders(t *testing.T) {
+	cfg := &config.Config{AccrualAdr: "http://localhost:8080"}
+	providers, err := cfg.LoadAccrualProviders()
+	assert.NoError(t, err)
+	assert.Equal(t, []config.AccrualProvider{{Name: "default", URL: "http://localhost:8080"}}, providers)
+
+	file := t.TempDir() + "/providers.json"
+	err = os.WriteFile(file, []byte(`[
+		{"name": "default", "url": "http://localhost:8080"},
+		{"name": "partner", "url": "http://partner", "prefixes": ["42"], "statuses": {"done": "PROCESSED"}}
+	]`), 0o600)
+	assert.NoError(t, err)
+	cfg.AccrualProvidersFile = file
+	providers, err = cfg.LoadAccrualProviders()
+	assert.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.Equal(t, []string{"42"}, providers[1].Prefixes)
+
+	err = os.WriteFile(file, []byte(`[
+		{"name": "partner", "url": "http://partner", "statuses": {"done": "FINISHED"}}
+	]`), 0o600)
+	assert.NoError(t, err)
+	_, err = cfg.LoadAccrualProviders()
+	assert.Error(t, err)
+
+	err = os.WriteFile(file, []byte(`[
+		{"name": "a", "url": "http://a", "prefixes": ["42"]},
+		{"name": "b", "url": "http://b", "prefixes": ["42"]}
+	]`), 0o600)
+	assert.NoError(t, err)
+	_, err = cfg.LoadAccrualProviders()
+	assert.Error(t, err)
+}
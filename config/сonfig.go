@@ -3,6 +3,20 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
+)
+
+// Хранилища данных
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Системы расчёта начислений
+const (
+	AccrualEngineExternal = "external"
+	AccrualEngineBuiltin  = "builtin"
 )
 
 // Config Структура со всеми конфигурациями сервера
@@ -11,6 +25,43 @@ type Config struct {
 	DB         string
 	SecretKey  string
 	AccrualAdr string
+	Storage    string
+
+	PasswordMinLength      int
+	PasswordMinClasses     int
+	PasswordCommonListFile string
+
+	PasswordResetTTL  time.Duration
+	PasswordResetFile string
+
+	AdminLogin    string
+	AdminPassword string
+
+	AccrualPushSecret    string
+	AccrualEngine        string
+	AccrualProvidersFile string
+	AccrualTransport     AccrualTransport
+
+	// Command Команда вместо запуска сервера, например migrate up
+	Command []string
+}
+
+// AccrualTransport Настройки HTTP клиента систем начислений
+type AccrualTransport struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	RequestTimeout        time.Duration
+
+	ClientCert string
+	ClientKey  string
+	CACert     string
+	Proxy      string
 }
 
 // MustLoadConfig загрузка конфигурации
@@ -24,13 +75,121 @@ func MustLoadConfig() *Config {
 		"user=postgres password=postgres host=localhost port=5432 database=postgres sslmode=disable pool_max_conns=10",
 		"DSN строка для соединения с базой данных",
 	)
+	flag.StringVar(
+		&config.Storage,
+		"storage",
+		StoragePostgres,
+		"Хранилище данных: postgres — база данных по DSN -d, memory — память процесса, данные теряются при остановке",
+	)
 	flag.StringVar(
 		&config.AccrualAdr,
 		"r",
 		"http://localhost:8080",
 		"Адрес системы расчёта начислений",
 	)
+	flag.IntVar(&config.PasswordMinLength, "password-min-length", 8, "Минимальная длина пароля")
+	flag.IntVar(
+		&config.PasswordMinClasses,
+		"password-min-classes",
+		2,
+		"Минимальное количество классов символов в пароле (строчные, заглавные, цифры, прочие)",
+	)
+	flag.StringVar(
+		&config.PasswordCommonListFile,
+		"password-common-list",
+		"",
+		"Файл со списком распространённых паролей (по одному на строку), дополняет встроенный список",
+	)
+	flag.DurationVar(&config.PasswordResetTTL, "password-reset-ttl", 30*time.Minute, "Время жизни токена сброса пароля")
+	flag.StringVar(
+		&config.PasswordResetFile,
+		"password-reset-file",
+		"",
+		"Файл для доставки токенов сброса пароля, по умолчанию stdout",
+	)
+	flag.StringVar(&config.AdminLogin, "admin-login", "", "Логин администратора, создаваемого при старте")
+	flag.StringVar(
+		&config.AdminPassword,
+		"admin-password",
+		"",
+		"Пароль администратора, используется только если пользователя ещё нет",
+	)
+	flag.StringVar(
+		&config.AccrualPushSecret,
+		"accrual-push-secret",
+		"",
+		"Секрет подписи результатов, присылаемых системой начислений, без него приём выключен",
+	)
+	flag.StringVar(
+		&config.AccrualEngine,
+		"accrual-engine",
+		AccrualEngineExternal,
+		"Система расчёта начислений: external — внешняя по адресу -r, builtin — встроенная по правилам вознаграждения",
+	)
+	flag.StringVar(
+		&config.AccrualProvidersFile,
+		"accrual-providers",
+		"",
+		"JSON файл с системами начислений партнёров и маршрутами заказов, без него все заказы идут по адресу -r",
+	)
+	flag.IntVar(&config.AccrualTransport.MaxIdleConns, "accrual-max-idle-conns", 100, "Максимум простаивающих соединений с системами начислений")
+	flag.IntVar(
+		&config.AccrualTransport.MaxIdleConnsPerHost,
+		"accrual-max-idle-conns-per-host",
+		10,
+		"Максимум простаивающих соединений с одной системой начислений",
+	)
+	flag.IntVar(
+		&config.AccrualTransport.MaxConnsPerHost,
+		"accrual-max-conns-per-host",
+		0,
+		"Максимум соединений с одной системой начислений, 0 без ограничения",
+	)
+	flag.DurationVar(
+		&config.AccrualTransport.IdleConnTimeout,
+		"accrual-idle-conn-timeout",
+		90*time.Second,
+		"Время, после которого простаивающее соединение закрывается",
+	)
+	flag.DurationVar(&config.AccrualTransport.DialTimeout, "accrual-dial-timeout", 5*time.Second, "Таймаут установки соединения")
+	flag.DurationVar(
+		&config.AccrualTransport.TLSHandshakeTimeout,
+		"accrual-tls-handshake-timeout",
+		5*time.Second,
+		"Таймаут TLS рукопожатия",
+	)
+	flag.DurationVar(
+		&config.AccrualTransport.ResponseHeaderTimeout,
+		"accrual-response-header-timeout",
+		10*time.Second,
+		"Таймаут ожидания заголовков ответа",
+	)
+	flag.DurationVar(
+		&config.AccrualTransport.RequestTimeout,
+		"accrual-request-timeout",
+		15*time.Second,
+		"Таймаут всего запроса к системе начислений, включая чтение ответа",
+	)
+	flag.StringVar(&config.AccrualTransport.ClientCert, "accrual-client-cert", "", "PEM файл клиентского сертификата для mTLS")
+	flag.StringVar(&config.AccrualTransport.ClientKey, "accrual-client-key", "", "PEM файл ключа клиентского сертификата для mTLS")
+	flag.StringVar(
+		&config.AccrualTransport.CACert,
+		"accrual-ca-cert",
+		"",
+		"PEM файл сертификатов для проверки сервера вместо системных",
+	)
+	flag.StringVar(
+		&config.AccrualTransport.Proxy,
+		"accrual-proxy",
+		"",
+		"Адрес прокси, по умолчанию берётся из HTTP_PROXY, HTTPS_PROXY и NO_PROXY",
+	)
 	flag.Parse()
+	// флаги могут следовать за командой: gophermart migrate up -d <dsn>
+	for args := flag.Args(); len(args) > 0; args = flag.Args() {
+		config.Command = append(config.Command, args[0])
+		_ = flag.CommandLine.Parse(args[1:])
+	}
 
 	envAddr := os.Getenv("RUN_ADDRESS")
 	if envAddr != "" {
@@ -40,10 +199,91 @@ func MustLoadConfig() *Config {
 	if envDB != "" {
 		config.DB = envDB
 	}
+	envStorage := os.Getenv("STORAGE")
+	if envStorage != "" {
+		config.Storage = envStorage
+	}
 	envAccrualAdr := os.Getenv("ACCRUAL_SYSTEM_ADDRESS")
 	if envAccrualAdr != "" {
 		config.AccrualAdr = envAccrualAdr
 	}
+	envSecretKey := os.Getenv("SECRET_KEY")
+	if envSecretKey != "" {
+		config.SecretKey = envSecretKey
+	}
+	envPasswordMinLength, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err == nil {
+		config.PasswordMinLength = envPasswordMinLength
+	}
+	envPasswordMinClasses, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_CLASSES"))
+	if err == nil {
+		config.PasswordMinClasses = envPasswordMinClasses
+	}
+	envPasswordCommonListFile := os.Getenv("PASSWORD_COMMON_LIST_FILE")
+	if envPasswordCommonListFile != "" {
+		config.PasswordCommonListFile = envPasswordCommonListFile
+	}
+	envPasswordResetTTL, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL"))
+	if err == nil {
+		config.PasswordResetTTL = envPasswordResetTTL
+	}
+	envPasswordResetFile := os.Getenv("PASSWORD_RESET_FILE")
+	if envPasswordResetFile != "" {
+		config.PasswordResetFile = envPasswordResetFile
+	}
+	envAdminLogin := os.Getenv("ADMIN_LOGIN")
+	if envAdminLogin != "" {
+		config.AdminLogin = envAdminLogin
+	}
+	envAdminPassword := os.Getenv("ADMIN_PASSWORD")
+	if envAdminPassword != "" {
+		config.AdminPassword = envAdminPassword
+	}
+	envAccrualPushSecret := os.Getenv("ACCRUAL_PUSH_SECRET")
+	if envAccrualPushSecret != "" {
+		config.AccrualPushSecret = envAccrualPushSecret
+	}
+	envAccrualEngine := os.Getenv("ACCRUAL_ENGINE")
+	if envAccrualEngine != "" {
+		config.AccrualEngine = envAccrualEngine
+	}
+	envAccrualProvidersFile := os.Getenv("ACCRUAL_PROVIDERS_FILE")
+	if envAccrualProvidersFile != "" {
+		config.AccrualProvidersFile = envAccrualProvidersFile
+	}
+	for name, target := range map[string]*int{
+		"ACCRUAL_MAX_IDLE_CONNS":          &config.AccrualTransport.MaxIdleConns,
+		"ACCRUAL_MAX_IDLE_CONNS_PER_HOST": &config.AccrualTransport.MaxIdleConnsPerHost,
+		"ACCRUAL_MAX_CONNS_PER_HOST":      &config.AccrualTransport.MaxConnsPerHost,
+	} {
+		value, err := strconv.Atoi(os.Getenv(name))
+		if err == nil {
+			*target = value
+		}
+	}
+	for name, target := range map[string]*time.Duration{
+		"ACCRUAL_IDLE_CONN_TIMEOUT":       &config.AccrualTransport.IdleConnTimeout,
+		"ACCRUAL_DIAL_TIMEOUT":            &config.AccrualTransport.DialTimeout,
+		"ACCRUAL_TLS_HANDSHAKE_TIMEOUT":   &config.AccrualTransport.TLSHandshakeTimeout,
+		"ACCRUAL_RESPONSE_HEADER_TIMEOUT": &config.AccrualTransport.ResponseHeaderTimeout,
+		"ACCRUAL_REQUEST_TIMEOUT":         &config.AccrualTransport.RequestTimeout,
+	} {
+		value, err := time.ParseDuration(os.Getenv(name))
+		if err == nil {
+			*target = value
+		}
+	}
+	for name, target := range map[string]*string{
+		"ACCRUAL_CLIENT_CERT": &config.AccrualTransport.ClientCert,
+		"ACCRUAL_CLIENT_KEY":  &config.AccrualTransport.ClientKey,
+		"ACCRUAL_CA_CERT":     &config.AccrualTransport.CACert,
+		"ACCRUAL_PROXY":       &config.AccrualTransport.Proxy,
+	} {
+		value := os.Getenv(name)
+		if value != "" {
+			*target = value
+		}
+	}
 
 	return &config
 }
@@ -19,19 +19,19 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/user/orders": {
-            "get": {
-                "description": "Эндпоинт для получение списка загруженных номеров заказов и информации по ним\nВ заголовке Authorization необходимо передавать JWT токен.\nНомера заказа в выдаче должны быть отсортированы по времени загрузки от самых старых к самым новым. Формат даты — RFC3339.\nДоступные статусы обработки расчётов:\nNEW — заказ загружен в систему, но не попал в обработку;\nPROCESSING — вознаграждение за заказ рассчитывается;\nINVALID — система расчёта вознаграждений отказала в расчёте;\nPROCESSED — данные по заказу проверены и информация о расчёте успешно",
+        "/admin/accrual/goods": {
+            "post": {
+                "description": "Эндпоинт встроенной системы расчёта начислений. Правило вознаграждает товары, в описании которых\nвстречается строка match без учёта регистра: reward_type % — процент от цены, pt — фиксированные баллы.\nТовар вознаграждается первым подходящим правилом, правило действует на заказы, зарегистрированные после него.\nТребуется роль admin, в заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Admin"
                 ],
-                "summary": "Получение списка загруженных заказов",
+                "summary": "Добавление правила вознаграждения.",
                 "parameters": [
                     {
                         "type": "string",
@@ -39,41 +39,55 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "Reward Rule Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rewards.RuleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully fetched orders",
+                    "201": {
+                        "description": "Rule added",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/orders.Response"
-                            }
+                            "$ref": "#/definitions/rewards.RuleResponse"
                         }
                     },
-                    "204": {
-                        "description": "No content"
+                    "400": {
+                        "description": "Invalid request"
                     },
                     "401": {
                         "description": "User is not authorized"
                     },
+                    "403": {
+                        "description": "Role has no permission"
+                    },
+                    "409": {
+                        "description": "Rule with the match already exists"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            },
+            }
+        },
+        "/admin/accrual/orders": {
             "post": {
-                "description": "Эндпоинт используется для добавления нового заказа для начисления средств.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "description": "Эндпоинт встроенной системы расчёта начислений. Начисление рассчитывается при регистрации\nпо текущим правилам вознаграждения, после чего заказ получает статус PROCESSED при проверке.\nТребуется роль admin, в заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Admin"
                 ],
-                "summary": "Добавление нового заказа для начисления средств",
+                "summary": "Регистрация заказа с товарами.",
                 "parameters": [
                     {
                         "type": "string",
@@ -83,22 +97,21 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "example": 123124551,
-                        "description": "Order Number",
-                        "name": "Order",
+                        "description": "Order Request",
+                        "name": "Request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/rewards.OrderRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Order already added from current user"
-                    },
-                    "202": {
-                        "description": "Order successfully added to process"
+                    "201": {
+                        "description": "Order registered",
+                        "schema": {
+                            "$ref": "#/definitions/rewards.OrderResponse"
+                        }
                     },
                     "400": {
                         "description": "Invalid request"
@@ -106,8 +119,11 @@ const docTemplate = `{
                     "401": {
                         "description": "User is not authorized"
                     },
+                    "403": {
+                        "description": "Role has no permission"
+                    },
                     "409": {
-                        "description": "Order already added from another user"
+                        "description": "Order already registered"
                     },
                     "422": {
                         "description": "Order number is not valid"
@@ -118,19 +134,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/withdrawals": {
+        "/admin/metrics/accrual-client": {
             "get": {
-                "description": "Эндпоинт используется для получения списка операций снятия баланса пользователя\nВ заголовке Authorization необходимо передавать JWT токен.",
-                "consumes": [
-                    "text/plain"
-                ],
+                "description": "Эндпоинт возвращает счётчики запросов каждой системы начислений с запуска сервера:\nотправленные и ожидающие ответа запросы, ответы по кодам, ошибки и таймауты,\nзапросы, не отправленные из-за ограничения частоты, суммарное и наибольшее время запроса.\nТребуется роль support или admin, в заголовке Authorization необходимо передавать JWT токен.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Balance"
+                    "Admin"
                 ],
-                "summary": "Получение списка операций снятия баланса.",
+                "summary": "Метрики запросов к системам начислений.",
                 "parameters": [
                     {
                         "type": "string",
@@ -142,29 +155,26 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "User balance successfully returned",
+                        "description": "Metrics",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/withdrawals.Response"
+                                "$ref": "#/definitions/metrics.AccrualClientResponse"
                             }
                         }
                     },
-                    "204": {
-                        "description": "No withdrawal operations found"
-                    },
                     "401": {
                         "description": "User is not authorized"
                     },
-                    "500": {
-                        "description": "Internal server error"
+                    "403": {
+                        "description": "Role has no permission"
                     }
                 }
             }
         },
-        "/user/balance": {
+        "/admin/users/{userID}": {
             "get": {
-                "description": "Эндпоинт используется для получения текущего балaнаса пользователя.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "description": "Эндпоинт возвращает данные пользователя для поддержки и администраторов.\nТребуется роль support или admin, в заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
                     "text/plain"
                 ],
@@ -172,9 +182,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Balance"
+                    "Admin"
                 ],
-                "summary": "Получение баланса пользователя.",
+                "summary": "Получение пользователя.",
                 "parameters": [
                     {
                         "type": "string",
@@ -182,42 +192,98 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User balance successfully returned",
+                        "description": "Successfully fetched user",
                         "schema": {
-                            "$ref": "#/definitions/balance.Response"
+                            "$ref": "#/definitions/users.Response"
                         }
                     },
+                    "400": {
+                        "description": "Invalid user ID"
+                    },
                     "401": {
                         "description": "User is not authorized"
                     },
+                    "403": {
+                        "description": "Role has no permission"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
             }
         },
-        "/user/balance/withdraw": {
-            "post": {
-                "security": [
+        "/admin/users/{userID}/api-keys": {
+            "get": {
+                "description": "Эндпоинт возвращает действующие API ключи пользователя без самих ключей.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Получение списка API ключей.",
+                "parameters": [
                     {
-                        "ApiKeyAuth": []
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
-                "description": "Эндпоинт используется для снятия средств с баланса пользователя в пользу заказа\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched API keys",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apikeys.Response"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Эндпоинт создаёт API ключ для терминалов и других сервисов, работающих от имени пользователя.\nКлюч показывается один раз, хранится только его хэш.\nКлюч передаётся в заголовке Authorization вместо JWT и действует только на маршрутах с выданными ему правами.\nДоступные права: orders:read, orders:write, balance:read, balance:write.\nАдминистратор может создать ключ для другого пользователя через /admin/users/{userID}/api-keys.\nВ заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Balance"
+                    "User"
                 ],
-                "summary": "Cнятие средств с баланса пользователя в пользу заказа",
+                "summary": "Создание API ключа.",
                 "parameters": [
                     {
                         "type": "string",
@@ -227,30 +293,33 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Withdraw Request",
+                        "description": "API Key Request",
                         "name": "Request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/withdraw.Request"
+                            "$ref": "#/definitions/apikeys.Request"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Withdrawal request successfully sent"
+                    "201": {
+                        "description": "API key created",
+                        "schema": {
+                            "$ref": "#/definitions/apikeys.CreateResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid request"
+                        "description": "Bad request or unknown scope"
                     },
                     "401": {
                         "description": "User is not authorized"
                     },
-                    "402": {
-                        "description": "Balance is insufficient"
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
                     },
-                    "422": {
-                        "description": "Order number is not valid"
+                    "404": {
+                        "description": "User not found"
                     },
                     "500": {
                         "description": "Internal server error"
@@ -258,11 +327,11 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/login": {
-            "post": {
-                "description": "Эндпоинт используется для аутентификации пользователя.\nЛогин приводится к нижнему регистру на стороне сервера\nВ заголовке Authorization возвращается JWT токен для авторизации",
+        "/admin/users/{userID}/api-keys/{id}": {
+            "delete": {
+                "description": "Эндпоинт отзывает API ключ пользователя, ключ перестаёт приниматься сразу.\nВ заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "produces": [
                     "text/plain"
@@ -270,33 +339,38 @@ const docTemplate = `{
                 "tags": [
                     "User"
                 ],
-                "summary": "Аутентификация пользователя.",
+                "summary": "Отзыв API ключа.",
                 "parameters": [
                     {
-                        "description": "Login Request",
-                        "name": "Request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/login.Request"
-                        }
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User successfully authenticated",
-                        "headers": {
-                            "Authorization": {
-                                "type": "string",
-                                "description": "JWT Token"
-                            }
-                        }
+                    "204": {
+                        "description": "API key revoked"
                     },
                     "400": {
-                        "description": "Bad request"
+                        "description": "Invalid API key or user ID"
                     },
                     "401": {
-                        "description": "Login or password is wrong"
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
+                    },
+                    "404": {
+                        "description": "API key not found"
                     },
                     "500": {
                         "description": "Internal server error"
@@ -304,9 +378,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/register": {
-            "post": {
-                "description": "Эндпоинт используется для регистрации нового пользователя.\nЛогин приводится к нижнему регистру на стороне сервера\nВ заголовке Authorization возвращается JWT токен авторизации",
+        "/admin/users/{userID}/role": {
+            "put": {
+                "description": "Эндпоинт меняет роль пользователя, все сессии пользователя завершаются.\nТребуется роль admin, в заголовке Authorization необходимо передавать JWT токен.",
                 "consumes": [
                     "application/json"
                 ],
@@ -314,58 +388,2175 @@ const docTemplate = `{
                     "text/plain"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Регистрация нового пользователя.",
+                "summary": "Изменение роли пользователя.",
                 "parameters": [
                     {
-                        "description": "Register Request",
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role Request",
                         "name": "Request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/register.Request"
+                            "$ref": "#/definitions/users.RoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User registered successfully",
+                        "description": "Role changed"
+                    },
+                    "400": {
+                        "description": "Invalid user ID or unknown role"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "Role has no permission"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/user/orders": {
+            "get": {
+                "description": "Эндпоинт для получение списка загруженных номеров заказов и информации по ним\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:read.\nНомера заказа в выдаче отсортированы по времени загрузки от самых старых к самым новым, sort=-uploaded_at меняет порядок на обратный. Формат даты — RFC3339.\nВыдача разбита на страницы размером limit, курсор следующей страницы возвращается в заголовке X-Next-Cursor и передаётся в параметре cursor.\nКоличество заказов, подходящих под фильтр, на всех страницах возвращается в заголовке X-Total-Count.\nДоступные статусы обработки расчётов:\nNEW — заказ загружен в систему, но не попал в обработку;\nPROCESSING — вознаграждение за заказ рассчитывается;\nINVALID — система расчёта вознаграждений отказала в расчёте;\nPROCESSED — данные по заказу проверены и информация о расчёте успешно",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Получение списка загруженных заказов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "NEW",
+                                "PROCESSING",
+                                "INVALID",
+                                "PROCESSED"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Order statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2020-12-01T00:00:00+03:00",
+                        "description": "Uploaded at or after, RFC3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-01-01T00:00:00+03:00",
+                        "description": "Uploaded before, RFC3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "uploaded_at",
+                            "-uploaded_at"
+                        ],
+                        "type": "string",
+                        "default": "uploaded_at",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orders.Response"
+                            }
+                        },
                         "headers": {
-                            "Authorization": {
+                            "X-Next-Cursor": {
                                 "type": "string",
-                                "description": "JWT Token"
+                                "description": "Cursor of the next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of orders matching the filter"
                             }
                         }
                     },
+                    "204": {
+                        "description": "No content"
+                    },
                     "400": {
-                        "description": "Bad request"
+                        "description": "Invalid query parameters"
                     },
-                    "409": {
-                        "description": "User already exists"
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
                     },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            }
-        }
-    },
+            },
+            "post": {
+                "description": "Эндпоинт используется для добавления нового заказа для начисления средств.\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.\nНеобязательный параметр store указывает магазин заказа, заказ направляется в систему начислений этого магазина.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Добавление нового заказа для начисления средств",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "example": "123124551",
+                        "description": "Order Number, digits or a JSON string",
+                        "name": "Order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store the order was made in, routes it to the accrual system of the store",
+                        "name": "store",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order already added from current user"
+                    },
+                    "202": {
+                        "description": "Order successfully added to process"
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "409": {
+                        "description": "Order already added from another user"
+                    },
+                    "422": {
+                        "description": "Order number is not valid"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/user/orders/batch": {
+            "post": {
+                "description": "Эндпоинт принимает JSON массив номеров заказов или номера заказов, разделённые переводом строки.\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.\nДля каждого номера возвращается результат загрузки:\naccepted — заказ принят в обработку;\nalready_uploaded — заказ уже загружен текущим пользователем;\nowned_by_another_user — заказ уже загружен другим пользователем;\ninvalid — номер заказа не прошёл проверку по алгоритму Луна.\nНеобязательный параметр store указывает магазин заказов, заказы направляются в систему начислений этого магазина.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Пакетная загрузка заказов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Order Numbers",
+                        "name": "Orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store the orders were made in, routes them to the accrual system of the store",
+                        "name": "store",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload result of every number",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orders.BatchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "413": {
+                        "description": "Request body is too large"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/user/orders/{number}": {
+            "get": {
+                "description": "Эндпоинт возвращает текущее состояние заказа и историю смены его статусов с временем и суммой начисления.\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:read.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Получение заказа с историей статусов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order Number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched order",
+                        "schema": {
+                            "$ref": "#/definitions/orders.DetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid order number"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Эндпоинт удаляет заказ пользователя, пока он в статусе NEW или после того, как получил статус INVALID.\nЗаказ исключается из обработки, номер снова можно загрузить. Отзыв сохраняется в журнале аудита.\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Отзыв загруженного заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order Number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Order retracted"
+                    },
+                    "400": {
+                        "description": "Invalid order number"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "409": {
+                        "description": "Order is already processing or processed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/api/user/withdrawals": {
+            "get": {
+                "description": "Эндпоинт используется для получения списка операций снятия баланса пользователя\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:read.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Balance"
+                ],
+                "summary": "Получение списка операций снятия баланса.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User balance successfully returned",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/withdrawals.Response"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No withdrawal operations found"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/internal/accrual/orders": {
+            "post": {
+                "description": "Эндпоинт для системы расчёта начислений, альтернатива опросу. Результаты применяются так же, как при опросе.\nПовторная отправка результата безопасна: начисление по заказу выполняется один раз.\nЗаголовок X-Accrual-Timestamp содержит unix время, отличающееся от текущего не более чем на 5 минут.\nЗаголовок X-Accrual-Signature содержит sha256=HMAC-SHA256 общего секрета от строки \"\u003ctimestamp\u003e.\u003cтело запроса\u003e\" в hex.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accrual"
+                ],
+                "summary": "Приём результата расчёта начислений.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unix timestamp",
+                        "name": "X-Accrual-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sha256=\u003chex\u003e",
+                        "name": "X-Accrual-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Order result",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/accrual.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result applied",
+                        "schema": {
+                            "$ref": "#/definitions/accrual.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "401": {
+                        "description": "Invalid signature"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user": {
+            "delete": {
+                "description": "Эндпоинт удаляет персональные данные пользователя и все его сессии и API ключи, войти в аккаунт больше нельзя.\nЗаказы и операции по балансу сохраняются для учёта под случайным псевдонимом.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Удаление аккаунта.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Account deleted"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot delete the account"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/2fa": {
+            "delete": {
+                "description": "Эндпоинт отключает двухфакторную аутентификацию по TOTP или резервному коду.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Отключение двухфакторной аутентификации.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "TOTP or Recovery Code",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/twofactor.CodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled"
+                    },
+                    "400": {
+                        "description": "Bad request or two-factor authentication is not enabled"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "422": {
+                        "description": "Code is invalid"
+                    },
+                    "429": {
+                        "description": "Too many attempts, try again later"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/2fa/confirm": {
+            "post": {
+                "description": "Эндпоинт включает двухфакторную аутентификацию по первому TOTP коду и возвращает резервные коды.\nРезервные коды показываются один раз, каждый код можно использовать один раз.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Подтверждение двухфакторной аутентификации.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "TOTP Code",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/twofactor.CodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "$ref": "#/definitions/twofactor.ConfirmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or enrollment was not started"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled"
+                    },
+                    "422": {
+                        "description": "TOTP code is invalid"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/2fa/enroll": {
+            "post": {
+                "description": "Эндпоинт создаёт новый TOTP секрет и otpauth URI для приложения-аутентификатора.\nДвухфакторная аутентификация включается только после подтверждения кодом.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Подключение двухфакторной аутентификации.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pending TOTP secret",
+                        "schema": {
+                            "$ref": "#/definitions/twofactor.EnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/api-keys": {
+            "get": {
+                "description": "Эндпоинт возвращает действующие API ключи пользователя без самих ключей.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Получение списка API ключей.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched API keys",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apikeys.Response"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Эндпоинт создаёт API ключ для терминалов и других сервисов, работающих от имени пользователя.\nКлюч показывается один раз, хранится только его хэш.\nКлюч передаётся в заголовке Authorization вместо JWT и действует только на маршрутах с выданными ему правами.\nДоступные права: orders:read, orders:write, balance:read, balance:write.\nАдминистратор может создать ключ для другого пользователя через /admin/users/{userID}/api-keys.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Создание API ключа.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "API Key Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apikeys.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "API key created",
+                        "schema": {
+                            "$ref": "#/definitions/apikeys.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or unknown scope"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/api-keys/{id}": {
+            "delete": {
+                "description": "Эндпоинт отзывает API ключ пользователя, ключ перестаёт приниматься сразу.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Отзыв API ключа.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "API key revoked"
+                    },
+                    "400": {
+                        "description": "Invalid API key or user ID"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage API keys or role has no permission"
+                    },
+                    "404": {
+                        "description": "API key not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/balance": {
+            "get": {
+                "description": "Эндпоинт используется для получения текущего балaнаса пользователя.\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:read.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Balance"
+                ],
+                "summary": "Получение баланса пользователя.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User balance successfully returned",
+                        "schema": {
+                            "$ref": "#/definitions/balance.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/balance/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Эндпоинт используется для снятия средств с баланса пользователя в пользу заказа\nВ заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:write.\nЗаказ можно оплачивать несколькими списаниями.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Balance"
+                ],
+                "summary": "Cнятие средств с баланса пользователя в пользу заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token or API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdraw Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/withdraw.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal request successfully sent"
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "402": {
+                        "description": "Balance is insufficient"
+                    },
+                    "403": {
+                        "description": "API key has no required scope"
+                    },
+                    "422": {
+                        "description": "Order number is not valid"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/events": {
+            "get": {
+                "description": "Эндпоинт отдаёт поток Server-Sent Events со сменой статусов заказов (order_status) и изменениями баланса (balance).\nВ заголовке Authorization необходимо передавать JWT токен.\nЗаголовок Last-Event-ID позволяет получить события, пропущенные после переподключения.\nПока событий нет, сервер периодически отправляет комментарий heartbeat.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Поток событий пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events, data of balance events is events.BalanceResponse",
+                        "schema": {
+                            "$ref": "#/definitions/events.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Last-Event-ID"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "500": {
+                        "description": "Streaming is not supported"
+                    }
+                }
+            }
+        },
+        "/user/export": {
+            "get": {
+                "description": "Эндпоинт возвращает логин, заказы, баланс и операции по балансу пользователя.\nПо умолчанию ответ в JSON, с параметром format=zip или заголовком Accept: application/zip — ZIP архив с JSON файлами.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Выгрузка персональных данных.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "zip"
+                        ],
+                        "type": "string",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Personal data",
+                        "schema": {
+                            "$ref": "#/definitions/account.ExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot export personal data"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "Эндпоинт используется для аутентификации пользователя.\nЛогин приводится к нижнему регистру на стороне сервера\nВ заголовке Authorization возвращается JWT токен для авторизации\nЕсли у пользователя включена двухфакторная аутентификация, возвращается 202 и токен\nподтверждения, который передаётся в /user/login/2fa вместе с кодом",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Аутентификация пользователя.",
+                "parameters": [
+                    {
+                        "description": "Login Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/login.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User successfully authenticated",
+                        "headers": {
+                            "Authorization": {
+                                "type": "string",
+                                "description": "JWT Token"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/login.ChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "401": {
+                        "description": "Login or password is wrong"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/login/2fa": {
+            "post": {
+                "description": "Эндпоинт принимает токен подтверждения из /user/login и TOTP или резервный код.\nВ заголовке Authorization возвращается JWT токен для авторизации\nКаждый TOTP код принимается один раз, после 5 неверных попыток за 15 минут вход блокируется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Второй шаг двухфакторной аутентификации.",
+                "parameters": [
+                    {
+                        "description": "Second Factor Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/twofactor.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User successfully authenticated",
+                        "headers": {
+                            "Authorization": {
+                                "type": "string",
+                                "description": "JWT Token"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "401": {
+                        "description": "Challenge token or code is invalid"
+                    },
+                    "429": {
+                        "description": "Too many attempts, try again later"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "put": {
+                "description": "Эндпоинт используется для смены пароля пользователя.\nВ заголовке Authorization необходимо передавать JWT токен.\nНовый пароль проверяется политикой паролей, все остальные сессии пользователя завершаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Смена пароля пользователя.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Change Password Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/password.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "Bad request or new password does not satisfy the policy"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "Old password is wrong"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/password/reset": {
+            "post": {
+                "description": "Эндпоинт используется для установки нового пароля по одноразовому токену сброса.\nНовый пароль проверяется политикой паролей, все сессии пользователя завершаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Сброс пароля по токену.",
+                "parameters": [
+                    {
+                        "description": "Password Reset Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reset.ResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "Bad request or new password does not satisfy the policy"
+                    },
+                    "401": {
+                        "description": "Token is unknown, used or expired"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/password/reset-request": {
+            "post": {
+                "description": "Эндпоинт используется для получения одноразового токена сброса пароля.\nЛогин приводится к нижнему регистру на стороне сервера\nТокен доставляется пользователю через канал уведомлений, ответ не зависит от существования логина.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Запрос на сброс пароля.",
+                "parameters": [
+                    {
+                        "description": "Password Reset Token Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reset.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Request accepted"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "Эндпоинт используется для регистрации нового пользователя.\nЛогин приводится к нижнему регистру на стороне сервера\nПароль проверяется политикой паролей: длина, классы символов, отсутствие логина, распространённые пароли\nВ заголовке Authorization возвращается JWT токен авторизации",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Регистрация нового пользователя.",
+                "parameters": [
+                    {
+                        "description": "Register Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/register.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User registered successfully",
+                        "headers": {
+                            "Authorization": {
+                                "type": "string",
+                                "description": "JWT Token"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or password does not satisfy the policy"
+                    },
+                    "409": {
+                        "description": "User already exists"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/sessions": {
+            "get": {
+                "description": "Эндпоинт возвращает активные сессии пользователя: время создания и последнего использования, IP и User-Agent.\nСессия, которой выполнен запрос, отмечена полем current.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Получение списка активных сессий.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched sessions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/sessions.Response"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage sessions"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/sessions/{id}": {
+            "delete": {
+                "description": "Эндпоинт завершает одну сессию пользователя, её JWT перестаёт приниматься сразу.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Завершение сессии.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "400": {
+                        "description": "Invalid session ID"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage sessions"
+                    },
+                    "404": {
+                        "description": "Session not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/webhooks": {
+            "get": {
+                "description": "Эндпоинт возвращает вебхуки пользователя без секретов.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Получение списка вебхуков.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Response"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage webhooks"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Эндпоинт подписывает URL партнёра на события пользователя: order_status и balance.\nКаждая доставка отправляется POST запросом с заголовками X-Gophermart-Event, X-Gophermart-Delivery и X-Gophermart-Timestamp.\nЗаголовок X-Gophermart-Signature содержит sha256=HMAC-SHA256 секрета от строки \"\u003ctimestamp\u003e.\u003cтело запроса\u003e\" в hex.\nНеуспешные доставки повторяются с экспоненциальной задержкой. Секрет показывается один раз.\nАдреса loopback, частных и link-local сетей не принимаются, перенаправления не выполняются.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Создание вебхука.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid or internal URL or unknown event"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage webhooks"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/webhooks/{id}": {
+            "delete": {
+                "description": "Эндпоинт удаляет вебхук пользователя вместе с журналом доставок, недоставленные события отбрасываются.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Удаление вебхука.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook deleted"
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage webhooks"
+                    },
+                    "404": {
+                        "description": "Webhook not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Эндпоинт возвращает последние доставки вебхука: статус, число попыток, код ответа и последнюю ошибку.\nВ заголовке Authorization необходимо передавать JWT токен.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Журнал доставок вебхука.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully fetched deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.DeliveryResponse"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    },
+                    "403": {
+                        "description": "API keys cannot manage webhooks"
+                    },
+                    "404": {
+                        "description": "Webhook not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/ws": {
+            "get": {
+                "description": "Эндпоинт открывает WebSocket соединение, по которому отправляются те же события, что и в /api/user/events.\nВ заголовке Authorization при подключении необходимо передавать JWT токен.\nСервер периодически отправляет ping и закрывает соединение, если клиент не отвечает pong или не успевает читать события.\nПараметр last_event_id позволяет получить события, пропущенные после переподключения.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Уведомления через WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the last received event",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching to WebSocket",
+                        "schema": {
+                            "$ref": "#/definitions/events.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "401": {
+                        "description": "User is not authorized"
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
+        "account.BalanceResponse": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "number",
+                    "example": 500.5
+                },
+                "withdrawn": {
+                    "type": "number",
+                    "example": 42
+                }
+            }
+        },
+        "account.ExportResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/account.BalanceResponse"
+                },
+                "exported_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "login": {
+                    "type": "string",
+                    "example": "test@test.com"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/account.OperationResponse"
+                    }
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/account.OrderResponse"
+                    }
+                }
+            }
+        },
+        "account.OperationResponse": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "order": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "processed_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "accrual",
+                        "withdrawal"
+                    ],
+                    "example": "accrual"
+                },
+                "withdrawal": {
+                    "type": "number",
+                    "example": 0
+                }
+            }
+        },
+        "account.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "number": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSED"
+                },
+                "uploaded_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                }
+            }
+        },
+        "accrual.Request": {
+            "type": "object",
+            "required": [
+                "order",
+                "status"
+            ],
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 500
+                },
+                "order": {
+                    "type": "string",
+                    "example": "12345678903"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "REGISTERED",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSED"
+                }
+            }
+        },
+        "accrual.Response": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "number": {
+                    "type": "string",
+                    "example": "12345678903"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSED"
+                }
+            }
+        },
+        "apikeys.CreateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "gm_mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "POS terminal #1"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "orders:write",
+                        "balance:read"
+                    ]
+                }
+            }
+        },
+        "apikeys.Request": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "POS terminal #1"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "orders:write",
+                        "balance:read"
+                    ]
+                }
+            }
+        },
+        "apikeys.Response": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "POS terminal #1"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "orders:write",
+                        "balance:read"
+                    ]
+                }
+            }
+        },
         "balance.Response": {
             "type": "object",
             "properties": {
-                "current": {
+                "current": {
+                    "type": "number",
+                    "example": 500.5
+                },
+                "withdrawn": {
+                    "type": "number",
+                    "example": 42
+                }
+            }
+        },
+        "events.Message": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is events.OrderResponse or events.BalanceResponse according to Type."
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1700000000000000001
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "order_status",
+                        "balance"
+                    ],
+                    "example": "order_status"
+                }
+            }
+        },
+        "events.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "number": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSED"
+                },
+                "uploaded_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                }
+            }
+        },
+        "login.ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
+                }
+            }
+        },
+        "login.Request": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "example": "test@test.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "test_Password"
+                }
+            }
+        },
+        "metrics.AccrualClientResponse": {
+            "type": "object",
+            "properties": {
+                "duration_seconds": {
+                    "type": "number",
+                    "example": 4.2
+                },
+                "errors": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "in_flight": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_duration_seconds": {
+                    "type": "number",
+                    "example": 0.35
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "rate_limited": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "responses": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "timeouts": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "orders.BatchResult": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "result": {
+                    "type": "string",
+                    "enum": [
+                        "accepted",
+                        "already_uploaded",
+                        "owned_by_another_user",
+                        "invalid"
+                    ],
+                    "example": "accepted"
+                }
+            }
+        },
+        "orders.DetailResponse": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orders.StatusChangeResponse"
+                    }
+                },
+                "number": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSING"
+                },
+                "uploaded_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                }
+            }
+        },
+        "orders.Response": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number",
+                    "example": 500
+                },
+                "number": {
+                    "type": "string",
+                    "example": "123124551"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSING"
+                },
+                "uploaded_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                }
+            }
+        },
+        "orders.StatusChangeResponse": {
+            "type": "object",
+            "properties": {
+                "accrual": {
                     "type": "number",
-                    "example": 500.5
+                    "example": 500
                 },
-                "withdrawn": {
-                    "type": "number",
-                    "example": 42
+                "changed_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "NEW",
+                        "PROCESSING",
+                        "INVALID",
+                        "PROCESSED"
+                    ],
+                    "example": "PROCESSED"
                 }
             }
         },
-        "login.Request": {
+        "password.Request": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "new_test_Password"
+                },
+                "old_password": {
+                    "type": "string",
+                    "example": "test_Password"
+                }
+            }
+        },
+        "register.Request": {
             "type": "object",
             "required": [
                 "login",
@@ -382,47 +2573,398 @@ const docTemplate = `{
                 }
             }
         },
-        "orders.Response": {
+        "reset.Request": {
+            "type": "object",
+            "required": [
+                "login"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "example": "test@test.com"
+                }
+            }
+        },
+        "reset.ResetRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "new_test_Password"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"
+                }
+            }
+        },
+        "rewards.Good": {
+            "type": "object",
+            "required": [
+                "description"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Чайник Bork"
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 7000
+                }
+            }
+        },
+        "rewards.OrderRequest": {
+            "type": "object",
+            "required": [
+                "goods",
+                "order"
+            ],
+            "properties": {
+                "goods": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/rewards.Good"
+                    }
+                },
+                "order": {
+                    "type": "string",
+                    "example": "12345678903"
+                }
+            }
+        },
+        "rewards.OrderResponse": {
             "type": "object",
             "properties": {
                 "accrual": {
                     "type": "number",
-                    "example": 500
+                    "example": 700
                 },
-                "number": {
+                "order": {
                     "type": "string",
-                    "example": "123124551"
+                    "example": "12345678903"
+                }
+            }
+        },
+        "rewards.RuleRequest": {
+            "type": "object",
+            "required": [
+                "match",
+                "reward_type"
+            ],
+            "properties": {
+                "match": {
+                    "type": "string",
+                    "example": "Bork"
                 },
-                "status": {
+                "reward": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "reward_type": {
                     "type": "string",
                     "enum": [
-                        "NEW",
-                        "PROCESSING",
-                        "INVALID",
-                        "PROCESSED"
+                        "%",
+                        "pt"
                     ],
-                    "example": "PROCESSING"
+                    "example": "%"
+                }
+            }
+        },
+        "rewards.RuleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
                 },
-                "uploaded_at": {
+                "match": {
+                    "type": "string",
+                    "example": "Bork"
+                },
+                "reward": {
+                    "type": "number",
+                    "example": 10
+                },
+                "reward_type": {
+                    "type": "string",
+                    "enum": [
+                        "%",
+                        "pt"
+                    ],
+                    "example": "%"
+                }
+            }
+        },
+        "sessions.Response": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2020-12-11T15:15:45+03:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "192.0.2.1"
+                },
+                "last_used_at": {
                     "type": "string",
                     "example": "2020-12-10T15:15:45+03:00"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
                 }
             }
         },
-        "register.Request": {
+        "twofactor.CodeRequest": {
             "type": "object",
             "required": [
-                "login",
-                "password"
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "twofactor.ConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcde-fghij",
+                        "klmno-pqrst"
+                    ]
+                }
+            }
+        },
+        "twofactor.EnrollResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Gophermart:test@test.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP\u0026issuer=Gophermart"
+                }
+            }
+        },
+        "twofactor.LoginRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
             ],
             "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "users.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
                 "login": {
                     "type": "string",
                     "example": "test@test.com"
                 },
-                "password": {
+                "role": {
                     "type": "string",
-                    "example": "test_Password"
+                    "enum": [
+                        "user",
+                        "support",
+                        "admin"
+                    ],
+                    "example": "user"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "users.RoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "support",
+                        "admin"
+                    ],
+                    "example": "support"
+                }
+            }
+        },
+        "webhooks.CreateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order_status",
+                        "balance"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example/hooks/gophermart"
+                }
+            }
+        },
+        "webhooks.DeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:46+03:00"
+                },
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "order_status",
+                        "balance"
+                    ],
+                    "example": "order_status"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0f6f2c1e-3d4b-4c5a-9e8f-7a6b5c4d3e2f"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "unexpected response status 500"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:16:45+03:00"
+                },
+                "response_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "PENDING",
+                        "DELIVERED",
+                        "FAILED"
+                    ],
+                    "example": "DELIVERED"
+                }
+            }
+        },
+        "webhooks.Request": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order_status",
+                        "balance"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example/hooks/gophermart"
+                }
+            }
+        },
+        "webhooks.Response": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2020-12-10T15:15:45+03:00"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order_status",
+                        "balance"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example/hooks/gophermart"
                 }
             }
         },
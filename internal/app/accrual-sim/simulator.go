@@ -0,0 +1,179 @@
+package accrualsim
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"github.com/mbiwapa/gophermart.git/config"
+	mwLogger "github.com/mbiwapa/gophermart.git/internal/app/http-server/middleware/logger"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/luna"
+)
+
+// rateLimitWindow is the window the request limit is counted in, as in the accrual system.
+const rateLimitWindow = time.Minute
+
+// Response is an order in the accrual system.
+type Response struct {
+	Order   string   `json:"order"`
+	Status  string   `json:"status"`
+	Accrual *float64 `json:"accrual,omitempty"`
+}
+
+// Simulator imitates the accrual system: every order goes through REGISTERED and PROCESSING
+// to a final status after the configured delays, counted from the first request of the order.
+// The final status and the accrual depend on the order number and the seed only,
+// so repeated runs give the same results.
+type Simulator struct {
+	config *config.AccrualSimConfig
+	logger *logger.Logger
+
+	mu          sync.Mutex
+	firstSeen   map[string]time.Time
+	random      *rand.Rand
+	windowStart time.Time
+	windowCount int
+}
+
+// New returns a new Simulator.
+func New(config *config.AccrualSimConfig, logger *logger.Logger) *Simulator {
+	return &Simulator{
+		config:    config,
+		logger:    logger,
+		firstSeen: make(map[string]time.Time),
+		random:    rand.New(rand.NewSource(config.Seed)),
+	}
+}
+
+// Router returns the handler of the accrual system API.
+func (s *Simulator) Router() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(mwLogger.New(s.logger))
+	r.Use(middleware.Recoverer)
+	r.Get("/api/orders/{number}", s.getOrder)
+	return r
+}
+
+// getOrder serves GET /api/orders/{number}.
+func (s *Simulator) getOrder(w http.ResponseWriter, r *http.Request) {
+	const op = "app.accrual-sim.Simulator.getOrder"
+	number := chi.URLParam(r, "number")
+	logWith := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", middleware.GetReqID(r.Context())),
+		s.logger.StringField("order_number", number),
+	)
+
+	now := time.Now()
+	s.mu.Lock()
+	retryAfter, limited := s.limit(now)
+	latency := s.latency()
+	failed := s.config.ErrorRate > 0 && s.random.Float64() < s.config.ErrorRate
+	firstSeen, ok := s.firstSeen[number]
+	if !ok && !limited && !failed {
+		firstSeen = now
+		s.firstSeen[number] = now
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(latency):
+	case <-r.Context().Done():
+		return
+	}
+
+	if limited {
+		logWith.Info("Rate limit exceeded")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("No more than " + strconv.Itoa(s.config.RateLimit) + " requests per minute allowed"))
+		return
+	}
+	if failed {
+		logWith.Info("Injected failure")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	elapsed := now.Sub(firstSeen)
+	if elapsed < s.config.UnregisteredFor {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	elapsed -= s.config.UnregisteredFor
+
+	response := Response{Order: number}
+	switch {
+	case elapsed < s.config.RegisteredFor:
+		response.Status = string(entity.OrderRegistered)
+	case elapsed < s.config.RegisteredFor+s.config.ProcessingFor:
+		response.Status = string(entity.OrderProcessing)
+	case !luna.Valid(number) || s.fraction(number, "invalid") < s.config.InvalidRate:
+		response.Status = string(entity.OrderInvalid)
+	default:
+		response.Status = string(entity.OrderProcessed)
+		if s.fraction(number, "no-accrual") >= s.config.NoAccrualRate {
+			accrual := s.accrual(number)
+			response.Accrual = &accrual
+		}
+	}
+
+	render.JSON(w, r, response)
+	logWith.Info("Order status sent", s.logger.StringField("status", response.Status))
+}
+
+// limit counts the request in the current window and reports whether it is over the limit
+// with the seconds left until the window resets. The caller holds the lock.
+func (s *Simulator) limit(now time.Time) (int, bool) {
+	if s.config.RateLimit <= 0 {
+		return 0, false
+	}
+	if now.Sub(s.windowStart) >= rateLimitWindow {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+	if s.windowCount >= s.config.RateLimit {
+		left := rateLimitWindow - now.Sub(s.windowStart)
+		return int(math.Ceil(left.Seconds())), true
+	}
+	s.windowCount++
+	return 0, false
+}
+
+// latency returns a random response delay within the configured bounds. The caller holds the lock.
+func (s *Simulator) latency() time.Duration {
+	if s.config.LatencyMax <= s.config.LatencyMin {
+		return s.config.LatencyMin
+	}
+	return s.config.LatencyMin + time.Duration(s.random.Int63n(int64(s.config.LatencyMax-s.config.LatencyMin)))
+}
+
+// accrual returns the accrual of the order within the configured bounds, rounded to cents.
+func (s *Simulator) accrual(number string) float64 {
+	accrual := s.config.AccrualMin + s.fraction(number, "accrual")*(s.config.AccrualMax-s.config.AccrualMin)
+	return math.Round(accrual*100) / 100
+}
+
+// fraction returns a number in [0, 1) derived from the order number, the seed and the salt.
+func (s *Simulator) fraction(number, salt string) float64 {
+	h := fnv.New64a()
+	seed := make([]byte, 8)
+	binary.LittleEndian.PutUint64(seed, uint64(s.config.Seed))
+	h.Write(seed)
+	h.Write([]byte(salt))
+	h.Write([]byte(number))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,141 @@
+package accrualsim_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/config"
+	accrualsim "github.com/mbiwapa/gophermart.git/internal/app/accrual-sim"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func get(t *testing.T, handler http.Handler, number string) (*httptest.ResponseRecorder, accrualsim.Response) {
+	req, err := http.NewRequest(http.MethodGet, "/api/orders/"+number, nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var response accrualsim.Response
+	if rr.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	}
+	return rr, response
+}
+
+func TestSimulator(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     config.AccrualSimConfig
+		number     string
+		statusCode int
+		status     string
+		accrual    bool
+	}{
+		{
+			name:       "Processed with accrual",
+			config:     config.AccrualSimConfig{AccrualMin: 100, AccrualMax: 100, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusOK,
+			status:     "PROCESSED",
+			accrual:    true,
+		},
+		{
+			name:       "Processed without accrual",
+			config:     config.AccrualSimConfig{NoAccrualRate: 1, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusOK,
+			status:     "PROCESSED",
+		},
+		{
+			name:       "Invalid by rate",
+			config:     config.AccrualSimConfig{InvalidRate: 1, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusOK,
+			status:     "INVALID",
+		},
+		{
+			name:       "Invalid by checksum",
+			config:     config.AccrualSimConfig{Seed: 1},
+			number:     "12345678904",
+			statusCode: http.StatusOK,
+			status:     "INVALID",
+		},
+		{
+			name:       "Registered",
+			config:     config.AccrualSimConfig{RegisteredFor: time.Hour, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusOK,
+			status:     "REGISTERED",
+		},
+		{
+			name:       "Processing",
+			config:     config.AccrualSimConfig{ProcessingFor: time.Hour, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusOK,
+			status:     "PROCESSING",
+		},
+		{
+			name:       "Not registered",
+			config:     config.AccrualSimConfig{UnregisteredFor: time.Hour, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "Injected failure",
+			config:     config.AccrualSimConfig{ErrorRate: 1, Seed: 1},
+			number:     "12345678903",
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			handler := accrualsim.New(&tc.config, logger.NewLogger()).Router()
+
+			rr, response := get(t, handler, tc.number)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.status, response.Status)
+			if tc.statusCode == http.StatusOK {
+				require.Equal(t, tc.number, response.Order)
+			}
+			require.Equal(t, tc.accrual, response.Accrual != nil)
+			if tc.accrual {
+				require.Equal(t, 100.0, *response.Accrual)
+			}
+		})
+	}
+}
+
+func TestSimulator_RateLimit(t *testing.T) {
+	handler := accrualsim.New(&config.AccrualSimConfig{RateLimit: 2, Seed: 1}, logger.NewLogger()).Router()
+
+	for i := 0; i < 2; i++ {
+		rr, _ := get(t, handler, "12345678903")
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr, _ := get(t, handler, "12345678903")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.NotEmpty(t, rr.Header().Get("Retry-After"))
+	require.Equal(t, "No more than 2 requests per minute allowed", rr.Body.String())
+}
+
+func TestSimulator_Deterministic(t *testing.T) {
+	cfg := config.AccrualSimConfig{InvalidRate: 0.5, AccrualMin: 10, AccrualMax: 1000, Seed: 42}
+	numbers := []string{"12345678903", "79927398713", "4561261212345467", "0079927398713"}
+
+	// the same seed gives the same results in another run
+	first := accrualsim.New(&cfg, logger.NewLogger()).Router()
+	second := accrualsim.New(&cfg, logger.NewLogger()).Router()
+	for _, number := range numbers {
+		_, a := get(t, first, number)
+		_, b := get(t, second, number)
+		require.Equal(t, a, b)
+	}
+}
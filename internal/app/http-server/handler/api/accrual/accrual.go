@@ -0,0 +1,155 @@
+package accrual
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+const (
+	// TimestampHeader is the unix time the accrual system signed the result at.
+	TimestampHeader = "X-Accrual-Timestamp"
+	// SignatureHeader is the HMAC-SHA256 signature of the timestamp and the body.
+	SignatureHeader = "X-Accrual-Signature"
+
+	signaturePrefix = "sha256="
+	// timestampTolerance bounds the clock skew and the replay window.
+	timestampTolerance = 5 * time.Minute
+	maxBodySize        = 1 << 16
+)
+
+// OrderReceiver is an interface for applying the order results pushed by the accrual system.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderReceiver
+type OrderReceiver interface {
+	Receive(ctx context.Context, external entity.Order) (entity.Order, error)
+}
+
+// Request struct for HTTP order result Request in JSON
+type Request struct {
+	Order   string  `json:"order" validate:"required" example:"12345678903"`
+	Status  string  `json:"status" validate:"required,oneof=REGISTERED PROCESSING INVALID PROCESSED" example:"PROCESSED"`
+	Accrual float64 `json:"accrual,omitempty" validate:"gte=0" example:"500"`
+}
+
+// Response is an order state after the result is applied.
+type Response struct {
+	Number  string  `json:"number" example:"12345678903"`
+	Status  string  `json:"status" example:"PROCESSED" enums:"NEW,PROCESSING,INVALID,PROCESSED"`
+	Accrual float64 `json:"accrual,omitempty" example:"500"`
+}
+
+// NewReceiver returned func for receiving an order result from the accrual system.
+//
+//	@Tags			Accrual
+//	@Summary		Приём результата расчёта начислений.
+//	@Description	Эндпоинт для системы расчёта начислений, альтернатива опросу. Результаты применяются так же, как при опросе.
+//	@Description	Повторная отправка результата безопасна: начисление по заказу выполняется один раз.
+//	@Description	Заголовок X-Accrual-Timestamp содержит unix время, отличающееся от текущего не более чем на 5 минут.
+//	@Description	Заголовок X-Accrual-Signature содержит sha256=HMAC-SHA256 общего секрета от строки "<timestamp>.<тело запроса>" в hex.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/internal/accrual/orders [post]
+//	@Param			X-Accrual-Timestamp	header		string				true	"Unix timestamp"
+//	@Param			X-Accrual-Signature	header		string				true	"sha256=<hex>"
+//	@Param			Request				body		accrual.Request		true	"Order result"
+//	@Success		200					{object}	accrual.Response	"Result applied"
+//	@Failure		400					"Bad request"
+//	@Failure		401					"Invalid signature"
+//	@Failure		404					"Order not found"
+//	@Failure		500					"Internal server error"
+func NewReceiver(log *logger.Logger, receiver OrderReceiver, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.accrual.NewReceiver"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			logWith.Info("Failed to read request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			logWith.Info("Failed to parse timestamp", log.ErrorField(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		skew := time.Since(time.Unix(timestamp, 0))
+		if skew > timestampTolerance || skew < -timestampTolerance {
+			logWith.Info("Timestamp is out of tolerance", log.AnyField("timestamp", timestamp))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		signature, ok := strings.CutPrefix(r.Header.Get(SignatureHeader), signaturePrefix)
+		if !ok || !tool.ValidWebhookSignature(secret, timestamp, body, signature) {
+			logWith.Info("Invalid signature")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req Request
+		err = render.DecodeJSON(bytes.NewReader(body), &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		number := entity.OrderNumber(req.Order)
+		if !number.Digits() {
+			logWith.Info("Invalid order number", log.StringField("order_number", req.Order))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		order, err := receiver.Receive(ctx, entity.Order{
+			Number:  number,
+			Status:  entity.Status(req.Status),
+			Accrual: req.Accrual,
+		})
+		if err != nil {
+			if errors.Is(err, entity.ErrOrderNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Number:  string(order.Number),
+			Status:  string(order.Status),
+			Accrual: order.Accrual,
+		})
+		logWith.Info("Order result applied", log.AnyField("order_number", order.Number))
+	}
+}
@@ -0,0 +1,104 @@
+package accrual_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/accrual"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/accrual/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNewReceiver(t *testing.T) {
+	const secret = "push-secret"
+	external := entity.Order{Number: "12345678903", Status: entity.OrderProcessed, Accrual: 500}
+
+	tests := []struct {
+		name       string
+		body       string
+		secret     string
+		skew       time.Duration
+		mockError  error
+		statusCode int
+		noCall     bool
+	}{
+		{
+			name:       "Receive: Success",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			secret:     secret,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Receive: Wrong secret",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			secret:     "other",
+			noCall:     true,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Receive: Stale timestamp",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			secret:     secret,
+			skew:       -10 * time.Minute,
+			noCall:     true,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Receive: Unknown status",
+			body:       `{"order":"12345678903","status":"NEW"}`,
+			secret:     secret,
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Receive: Order not found",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			secret:     secret,
+			mockError:  entity.ErrOrderNotFound,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Receive: Service error",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			secret:     secret,
+			mockError:  errors.New("service error"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			receiverMock := mocks.NewOrderReceiver(t)
+			if !tc.noCall {
+				receiverMock.On("Receive", mock.Anything, external).
+					Return(external, tc.mockError).
+					Once()
+			}
+
+			handler := accrual.NewReceiver(logger.NewLogger(), receiverMock, secret)
+
+			timestamp := time.Now().Add(tc.skew).Unix()
+			req, err := http.NewRequest(http.MethodPost, "/api/internal/accrual/orders", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set(accrual.TimestampHeader, strconv.FormatInt(timestamp, 10))
+			req.Header.Set(accrual.SignatureHeader, "sha256="+tool.SignWebhook(tc.secret, timestamp, []byte(tc.body)))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+		})
+	}
+}
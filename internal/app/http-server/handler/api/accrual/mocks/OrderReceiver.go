@@ -0,0 +1,54 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderReceiver is an autogenerated mock type for the OrderReceiver type
+type OrderReceiver struct {
+	mock.Mock
+}
+
+// Receive provides a mock function with given fields: ctx, external
+func (_m *OrderReceiver) Receive(ctx context.Context, external entity.Order) (entity.Order, error) {
+	ret := _m.Called(ctx, external)
+
+	var r0 entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Order) (entity.Order, error)); ok {
+		return rf(ctx, external)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Order) entity.Order); ok {
+		r0 = rf(ctx, external)
+	} else {
+		r0 = ret.Get(0).(entity.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Order) error); ok {
+		r1 = rf(ctx, external)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewOrderReceiver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderReceiver creates a new instance of OrderReceiver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderReceiver(t mockConstructorTestingTNewOrderReceiver) *OrderReceiver {
+	mock := &OrderReceiver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccrualClientMetricsGetter is an interface for getting the request metrics of the accrual system clients.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccrualClientMetricsGetter
+type AccrualClientMetricsGetter interface {
+	AccrualClientMetrics() []entity.AccrualClientMetrics
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// AccrualClientResponse is the request metrics of the client of an accrual provider.
+type AccrualClientResponse struct {
+	Provider           string           `json:"provider" example:"default"`
+	Requests           int64            `json:"requests" example:"120"`
+	InFlight           int64            `json:"in_flight" example:"1"`
+	Responses          map[string]int64 `json:"responses"`
+	Errors             int64            `json:"errors" example:"3"`
+	Timeouts           int64            `json:"timeouts" example:"2"`
+	RateLimited        int64            `json:"rate_limited" example:"0"`
+	DurationSeconds    float64          `json:"duration_seconds" example:"4.2"`
+	MaxDurationSeconds float64          `json:"max_duration_seconds" example:"0.35"`
+}
+
+// NewAccrualClientGetter returned func for getting the request metrics of the accrual system clients.
+//
+//	@Tags			Admin
+//	@Summary		Метрики запросов к системам начислений.
+//	@Description	Эндпоинт возвращает счётчики запросов каждой системы начислений с запуска сервера:
+//	@Description	отправленные и ожидающие ответа запросы, ответы по кодам, ошибки и таймауты,
+//	@Description	запросы, не отправленные из-за ограничения частоты, суммарное и наибольшее время запроса.
+//	@Description	Требуется роль support или admin, в заголовке Authorization необходимо передавать JWT токен.
+//	@Produce		json
+//	@Router			/admin/metrics/accrual-client [get]
+//	@Param			Authorization	header	string							true	"JWT Token"
+//	@Success		200				{array}	metrics.AccrualClientResponse	"Metrics"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Role has no permission"
+func NewAccrualClientGetter(log *logger.Logger, getter AccrualClientMetricsGetter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.admin.metrics.NewAccrualClientGetter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		_, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		metrics := getter.AccrualClientMetrics()
+		response := make([]AccrualClientResponse, 0, len(metrics))
+		for _, m := range metrics {
+			responses := make(map[string]int64, len(m.Responses))
+			for code, count := range m.Responses {
+				responses[strconv.Itoa(code)] = count
+			}
+			response = append(response, AccrualClientResponse{
+				Provider:           m.Provider,
+				Requests:           m.Requests,
+				InFlight:           m.InFlight,
+				Responses:          responses,
+				Errors:             m.Errors,
+				Timeouts:           m.Timeouts,
+				RateLimited:        m.RateLimited,
+				DurationSeconds:    m.Duration.Seconds(),
+				MaxDurationSeconds: m.MaxDuration.Seconds(),
+			})
+		}
+		render.JSON(w, r, response)
+	}
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/metrics"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/metrics/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNewAccrualClientGetter(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		authError  error
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "Metrics: Success",
+			statusCode: http.StatusOK,
+			body: `[{"provider":"default","requests":3,"in_flight":1,"responses":{"200":1,"204":1},
+				"errors":0,"timeouts":0,"rate_limited":2,"duration_seconds":1.5,"max_duration_seconds":1}]`,
+		},
+		{
+			name:       "Metrics: Forbidden",
+			authError:  entity.ErrAccessForbidden,
+			statusCode: http.StatusForbidden,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").
+				Return(&entity.User{UUID: uuid.New(), Role: entity.RoleSupport}, tc.authError).
+				Once()
+
+			getterMock := mocks.NewAccrualClientMetricsGetter(t)
+			if tc.authError == nil {
+				getterMock.On("AccrualClientMetrics").Return([]entity.AccrualClientMetrics{{
+					Provider:    "default",
+					Requests:    3,
+					InFlight:    1,
+					Responses:   map[int]int64{200: 1, 204: 1},
+					RateLimited: 2,
+					Duration:    1500 * time.Millisecond,
+					MaxDuration: time.Second,
+				}}).Once()
+			}
+
+			handler := metrics.NewAccrualClientGetter(logger.NewLogger(), getterMock, authorizerMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/admin/metrics/accrual-client", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.body != "" {
+				require.JSONEq(t, tc.body, rr.Body.String())
+			}
+		})
+	}
+}
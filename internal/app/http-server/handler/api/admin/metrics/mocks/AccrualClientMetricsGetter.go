@@ -0,0 +1,45 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AccrualClientMetricsGetter is an autogenerated mock type for the AccrualClientMetricsGetter type
+type AccrualClientMetricsGetter struct {
+	mock.Mock
+}
+
+// AccrualClientMetrics provides a mock function with given fields:
+func (_m *AccrualClientMetricsGetter) AccrualClientMetrics() []entity.AccrualClientMetrics {
+	ret := _m.Called()
+
+	var r0 []entity.AccrualClientMetrics
+	if rf, ok := ret.Get(0).(func() []entity.AccrualClientMetrics); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AccrualClientMetrics)
+		}
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAccrualClientMetricsGetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccrualClientMetricsGetter creates a new instance of AccrualClientMetricsGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccrualClientMetricsGetter(t mockConstructorTestingTNewAccrualClientMetricsGetter) *AccrualClientMetricsGetter {
+	mock := &AccrualClientMetricsGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
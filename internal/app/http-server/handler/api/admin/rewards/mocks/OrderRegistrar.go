@@ -0,0 +1,54 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderRegistrar is an autogenerated mock type for the OrderRegistrar type
+type OrderRegistrar struct {
+	mock.Mock
+}
+
+// RegisterOrder provides a mock function with given fields: ctx, number, goods
+func (_m *OrderRegistrar) RegisterOrder(ctx context.Context, number entity.OrderNumber, goods []entity.Good) (entity.AccrualOrder, error) {
+	ret := _m.Called(ctx, number, goods)
+
+	var r0 entity.AccrualOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber, []entity.Good) (entity.AccrualOrder, error)); ok {
+		return rf(ctx, number, goods)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber, []entity.Good) entity.AccrualOrder); ok {
+		r0 = rf(ctx, number, goods)
+	} else {
+		r0 = ret.Get(0).(entity.AccrualOrder)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.OrderNumber, []entity.Good) error); ok {
+		r1 = rf(ctx, number, goods)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewOrderRegistrar interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderRegistrar creates a new instance of OrderRegistrar. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderRegistrar(t mockConstructorTestingTNewOrderRegistrar) *OrderRegistrar {
+	mock := &OrderRegistrar{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
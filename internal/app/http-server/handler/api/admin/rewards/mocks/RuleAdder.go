@@ -0,0 +1,54 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RuleAdder is an autogenerated mock type for the RuleAdder type
+type RuleAdder struct {
+	mock.Mock
+}
+
+// AddRule provides a mock function with given fields: ctx, match, reward, rewardType
+func (_m *RuleAdder) AddRule(ctx context.Context, match string, reward float64, rewardType entity.RewardType) (entity.RewardRule, error) {
+	ret := _m.Called(ctx, match, reward, rewardType)
+
+	var r0 entity.RewardRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, entity.RewardType) (entity.RewardRule, error)); ok {
+		return rf(ctx, match, reward, rewardType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, entity.RewardType) entity.RewardRule); ok {
+		r0 = rf(ctx, match, reward, rewardType)
+	} else {
+		r0 = ret.Get(0).(entity.RewardRule)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64, entity.RewardType) error); ok {
+		r1 = rf(ctx, match, reward, rewardType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewRuleAdder interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewRuleAdder creates a new instance of RuleAdder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewRuleAdder(t mockConstructorTestingTNewRuleAdder) *RuleAdder {
+	mock := &RuleAdder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
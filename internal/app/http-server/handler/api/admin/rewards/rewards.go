@@ -0,0 +1,237 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/luna"
+)
+
+// RuleAdder is an interface for adding reward rules to the built-in accrual engine.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=RuleAdder
+type RuleAdder interface {
+	AddRule(ctx context.Context, match string, reward float64, rewardType entity.RewardType) (entity.RewardRule, error)
+}
+
+// OrderRegistrar is an interface for registering orders with goods in the built-in accrual engine.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderRegistrar
+type OrderRegistrar interface {
+	RegisterOrder(ctx context.Context, number entity.OrderNumber, goods []entity.Good) (entity.AccrualOrder, error)
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// RuleRequest struct for HTTP reward rule Request in JSON
+type RuleRequest struct {
+	Match      string  `json:"match" validate:"required" example:"Bork"`
+	Reward     float64 `json:"reward" validate:"gte=0" example:"10"`
+	RewardType string  `json:"reward_type" validate:"required,oneof=% pt" example:"%" enums:"%,pt"`
+}
+
+// RuleResponse is a reward rule response.
+type RuleResponse struct {
+	Match      string  `json:"match" example:"Bork"`
+	Reward     float64 `json:"reward" example:"10"`
+	RewardType string  `json:"reward_type" example:"%" enums:"%,pt"`
+	CreatedAt  string  `json:"created_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// Good is a goods item of an order.
+type Good struct {
+	Description string  `json:"description" validate:"required" example:"Чайник Bork"`
+	Price       float64 `json:"price" validate:"gte=0" example:"7000"`
+}
+
+// OrderRequest struct for HTTP order registration Request in JSON
+type OrderRequest struct {
+	Order string `json:"order" validate:"required" example:"12345678903"`
+	Goods []Good `json:"goods" validate:"required,min=1,dive"`
+}
+
+// OrderResponse is a registered order response.
+type OrderResponse struct {
+	Order   string  `json:"order" example:"12345678903"`
+	Accrual float64 `json:"accrual" example:"700"`
+}
+
+// NewRuleAdder returned func for adding a reward rule.
+//
+//	@Tags			Admin
+//	@Summary		Добавление правила вознаграждения.
+//	@Description	Эндпоинт встроенной системы расчёта начислений. Правило вознаграждает товары, в описании которых
+//	@Description	встречается строка match без учёта регистра: reward_type % — процент от цены, pt — фиксированные баллы.
+//	@Description	Товар вознаграждается первым подходящим правилом, правило действует на заказы, зарегистрированные после него.
+//	@Description	Требуется роль admin, в заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/admin/accrual/goods [post]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Request			body		rewards.RuleRequest		true	"Reward Rule Request"
+//	@Success		201				{object}	rewards.RuleResponse	"Rule added"
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Role has no permission"
+//	@Failure		409				"Rule with the match already exists"
+//	@Failure		500				"Internal server error"
+func NewRuleAdder(log *logger.Logger, adder RuleAdder, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.admin.rewards.NewRuleAdder"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		_, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req RuleRequest
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rule, err := adder.AddRule(ctx, req.Match, req.Reward, entity.RewardType(req.RewardType))
+		if err != nil {
+			if errors.Is(err, entity.ErrRewardRuleInvalid) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, entity.ErrRewardRuleAlreadyExists) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, RuleResponse{
+			Match:      rule.Match,
+			Reward:     rule.Reward,
+			RewardType: string(rule.RewardType),
+			CreatedAt:  rule.CreatedAt.Format(time.RFC3339),
+		})
+		logWith.Info("Reward rule added", log.StringField("match", rule.Match))
+	}
+}
+
+// NewOrderRegistrar returned func for registering an order with its goods.
+//
+//	@Tags			Admin
+//	@Summary		Регистрация заказа с товарами.
+//	@Description	Эндпоинт встроенной системы расчёта начислений. Начисление рассчитывается при регистрации
+//	@Description	по текущим правилам вознаграждения, после чего заказ получает статус PROCESSED при проверке.
+//	@Description	Требуется роль admin, в заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/admin/accrual/orders [post]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Request			body		rewards.OrderRequest	true	"Order Request"
+//	@Success		201				{object}	rewards.OrderResponse	"Order registered"
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Role has no permission"
+//	@Failure		409				"Order already registered"
+//	@Failure		422				"Order number is not valid"
+//	@Failure		500				"Internal server error"
+func NewOrderRegistrar(log *logger.Logger, registrar OrderRegistrar, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.admin.rewards.NewOrderRegistrar"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		_, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req OrderRequest
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		number := entity.OrderNumber(req.Order)
+		if !number.Digits() || !luna.Valid(req.Order) {
+			logWith.Info("Invalid order number", log.AnyField("order_id", req.Order))
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		goods := make([]entity.Good, 0, len(req.Goods))
+		for _, good := range req.Goods {
+			goods = append(goods, entity.Good{Description: good.Description, Price: good.Price})
+		}
+
+		order, err := registrar.RegisterOrder(ctx, number, goods)
+		if err != nil {
+			if errors.Is(err, entity.ErrAccrualOrderAlreadyRegistered) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, OrderResponse{Order: string(order.Number), Accrual: order.Accrual})
+		logWith.Info("Accrual order registered", log.AnyField("order_id", order.Number))
+	}
+}
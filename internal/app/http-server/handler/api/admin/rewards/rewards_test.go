@@ -0,0 +1,92 @@
+package rewards_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/rewards"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/rewards/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNewOrderRegistrar(t *testing.T) {
+
+	admin := &entity.User{UUID: uuid.New(), Role: entity.RoleAdmin}
+	goods := []entity.Good{{Description: "Чайник Bork", Price: 7000}}
+
+	tests := []struct {
+		name       string
+		body       string
+		mockError  error
+		statusCode int
+		noCall     bool
+	}{
+		{
+			name:       "Register: Success",
+			body:       `{"order":"12345678903","goods":[{"description":"Чайник Bork","price":7000}]}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Register: No goods",
+			body:       `{"order":"12345678903","goods":[]}`,
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Register: Invalid number",
+			body:       `{"order":"12345678904","goods":[{"description":"Чайник Bork","price":7000}]}`,
+			noCall:     true,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "Register: Already registered",
+			body:       `{"order":"12345678903","goods":[{"description":"Чайник Bork","price":7000}]}`,
+			mockError:  entity.ErrAccrualOrderAlreadyRegistered,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Register: Repository error",
+			body:       `{"order":"12345678903","goods":[{"description":"Чайник Bork","price":7000}]}`,
+			mockError:  errors.New("repository error"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(admin, nil).Once()
+
+			registrarMock := mocks.NewOrderRegistrar(t)
+			if !tc.noCall {
+				registrarMock.On("RegisterOrder", mock.Anything, entity.OrderNumber("12345678903"), goods).
+					Return(entity.AccrualOrder{Number: "12345678903", Goods: goods, Accrual: 700}, tc.mockError).
+					Once()
+			}
+
+			handler := rewards.NewOrderRegistrar(logger.NewLogger(), registrarMock, authorizerMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/admin/accrual/orders", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.statusCode == http.StatusCreated {
+				require.JSONEq(t, `{"order":"12345678903","accrual":700}`, rr.Body.String())
+			}
+		})
+	}
+}
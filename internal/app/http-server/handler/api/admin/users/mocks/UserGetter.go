@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// UserGetter is an autogenerated mock type for the UserGetter type
+type UserGetter struct {
+	mock.Mock
+}
+
+// GetUser provides a mock function with given fields: ctx, userUUID
+func (_m *UserGetter) GetUser(ctx context.Context, userUUID uuid.UUID) (*entity.User, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*entity.User, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *entity.User); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewUserGetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUserGetter creates a new instance of UserGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUserGetter(t mockConstructorTestingTNewUserGetter) *UserGetter {
+	mock := &UserGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// UserRoleSetter is an autogenerated mock type for the UserRoleSetter type
+type UserRoleSetter struct {
+	mock.Mock
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userUUID, role
+func (_m *UserRoleSetter) SetUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error {
+	ret := _m.Called(ctx, userUUID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.Role) error); ok {
+		r0 = rf(ctx, userUUID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewUserRoleSetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUserRoleSetter creates a new instance of UserRoleSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUserRoleSetter(t mockConstructorTestingTNewUserRoleSetter) *UserRoleSetter {
+	mock := &UserRoleSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,210 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// UserGetter is an interface for getting a user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserGetter
+type UserGetter interface {
+	GetUser(ctx context.Context, userUUID uuid.UUID) (*entity.User, error)
+}
+
+// UserRoleSetter is an interface for changing the role of a user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserRoleSetter
+type UserRoleSetter interface {
+	SetUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// Response is a user response.
+type Response struct {
+	ID               string `json:"id" example:"5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"`
+	Login            string `json:"login" example:"test@test.com"`
+	Role             string `json:"role" example:"user" enums:"user,support,admin"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled" example:"false"`
+}
+
+// RoleRequest struct for HTTP role change Request in JSON
+type RoleRequest struct {
+	Role string `json:"role" validate:"required" example:"support" enums:"user,support,admin"`
+}
+
+// NewGetter returned func for getting a user.
+//
+//	@Tags			Admin
+//	@Summary		Получение пользователя.
+//	@Description	Эндпоинт возвращает данные пользователя для поддержки и администраторов.
+//	@Description	Требуется роль support или admin, в заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/admin/users/{userID} [get]
+//	@Param			Authorization	header		string			true	"JWT Token"
+//	@Param			userID			path		string			true	"User ID"
+//	@Success		200				{object}	users.Response	"Successfully fetched user"
+//	@Failure		400				"Invalid user ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Role has no permission"
+//	@Failure		404				"User not found"
+//	@Failure		500				"Internal server error"
+func NewGetter(log *logger.Logger, getter UserGetter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.admin.users.NewGetter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		_, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		userUUID, err := uuid.Parse(chi.URLParam(r, "userID"))
+		if err != nil {
+			logWith.Info("Invalid user ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		user, err := getter.GetUser(ctx, userUUID)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, Response{
+			ID:               user.UUID.String(),
+			Login:            user.Login,
+			Role:             string(user.Role),
+			TwoFactorEnabled: user.TOTPEnabled,
+		})
+		logWith.Info("User successfully retrieved", log.StringField("user_uuid", user.UUID.String()))
+	}
+}
+
+// NewRoleSetter returned func for changing the role of a user.
+//
+//	@Tags			Admin
+//	@Summary		Изменение роли пользователя.
+//	@Description	Эндпоинт меняет роль пользователя, все сессии пользователя завершаются.
+//	@Description	Требуется роль admin, в заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/admin/users/{userID}/role [put]
+//	@Param			Authorization	header	string				true	"JWT Token"
+//	@Param			userID			path	string				true	"User ID"
+//	@Param			Request			body	users.RoleRequest	true	"Role Request"
+//	@Success		200				"Role changed"
+//	@Failure		400				"Invalid user ID or unknown role"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Role has no permission"
+//	@Failure		404				"User not found"
+//	@Failure		500				"Internal server error"
+func NewRoleSetter(log *logger.Logger, setter UserRoleSetter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.admin.users.NewRoleSetter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		admin, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		userUUID, err := uuid.Parse(chi.URLParam(r, "userID"))
+		if err != nil {
+			logWith.Info("Invalid user ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req RoleRequest
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		role, err := entity.ParseRole(req.Role)
+		if err != nil {
+			logWith.Info("Unknown role", log.StringField("role", req.Role))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = setter.SetUserRole(ctx, userUUID, role)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info(
+			"User role changed",
+			log.StringField("admin_uuid", admin.UUID.String()),
+			log.StringField("user_uuid", userUUID.String()),
+			log.StringField("role", string(role)),
+		)
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,259 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccountExporter is an interface for exporting the personal data of a user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccountExporter
+type AccountExporter interface {
+	Export(ctx context.Context, userUUID uuid.UUID) (*entity.UserExport, error)
+}
+
+// AccountDeleter is an interface for deleting the account of a user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccountDeleter
+type AccountDeleter interface {
+	Delete(ctx context.Context, userUUID uuid.UUID) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// ExportResponse is the personal data export of a user.
+type ExportResponse struct {
+	Login      string              `json:"login" example:"test@test.com"`
+	ExportedAt string              `json:"exported_at" example:"2020-12-10T15:15:45+03:00"`
+	Balance    BalanceResponse     `json:"balance"`
+	Orders     []OrderResponse     `json:"orders"`
+	Operations []OperationResponse `json:"operations"`
+}
+
+// BalanceResponse is the balance in the export.
+type BalanceResponse struct {
+	Current   float64 `json:"current" example:"500.5"`
+	Withdrawn float64 `json:"withdrawn" example:"42"`
+}
+
+// OrderResponse is an order in the export.
+type OrderResponse struct {
+	Number     string  `json:"number" example:"123124551"`
+	Status     string  `json:"status" example:"PROCESSED" enums:"NEW,PROCESSING,INVALID,PROCESSED"`
+	Accrual    float64 `json:"accrual" example:"500"`
+	UploadedAt string  `json:"uploaded_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// OperationResponse is a balance operation in the export.
+type OperationResponse struct {
+	Type        string  `json:"type" example:"accrual" enums:"accrual,withdrawal"`
+	OrderNumber string  `json:"order" example:"123124551"`
+	Accrual     float64 `json:"accrual" example:"500"`
+	Withdrawal  float64 `json:"withdrawal" example:"0"`
+	ProcessedAt string  `json:"processed_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// NewExporter returned func for exporting the personal data of the user.
+//
+//	@Tags			User
+//	@Summary		Выгрузка персональных данных.
+//	@Description	Эндпоинт возвращает логин, заказы, баланс и операции по балансу пользователя.
+//	@Description	По умолчанию ответ в JSON, с параметром format=zip или заголовком Accept: application/zip — ZIP архив с JSON файлами.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json,application/zip
+//	@Router			/user/export [get]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			format			query		string					false	"Export format"	Enums(json, zip)
+//	@Success		200				{object}	account.ExportResponse	"Personal data"
+//	@Failure		400				"Unknown format"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot export personal data"
+//	@Failure		500				"Internal server error"
+func NewExporter(log *logger.Logger, exporter AccountExporter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.account.NewExporter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" && strings.Contains(r.Header.Get("Accept"), "application/zip") {
+			format = "zip"
+		}
+		if format != "" && format != "json" && format != "zip" {
+			logWith.Info("Unknown export format", log.StringField("format", format))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		export, err := exporter.Export(ctx, user.UUID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		response := newExportResponse(export)
+
+		if format != "zip" {
+			render.JSON(w, r, response)
+			logWith.Info("Personal data exported")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="gophermart-export.zip"`)
+		err = writeZip(w, response)
+		if err != nil {
+			// the headers are already sent, so the client gets a broken archive
+			logWith.Error("Failed to write export archive", log.ErrorField(err))
+			return
+		}
+		logWith.Info("Personal data exported as ZIP")
+	}
+}
+
+// NewDeleter returned func for deleting the account of the user.
+//
+//	@Tags			User
+//	@Summary		Удаление аккаунта.
+//	@Description	Эндпоинт удаляет персональные данные пользователя и все его сессии и API ключи, войти в аккаунт больше нельзя.
+//	@Description	Заказы и операции по балансу сохраняются для учёта под случайным псевдонимом.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		plain
+//	@Router			/user [delete]
+//	@Param			Authorization	header	string	true	"JWT Token"
+//	@Success		204				"Account deleted"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot delete the account"
+//	@Failure		500				"Internal server error"
+func NewDeleter(log *logger.Logger, deleter AccountDeleter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.account.NewDeleter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		err = deleter.Delete(ctx, user.UUID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Account deleted", log.StringField("user_uuid", user.UUID.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// newExportResponse converts the export to the response.
+func newExportResponse(export *entity.UserExport) ExportResponse {
+	response := ExportResponse{
+		Login:      export.Login,
+		ExportedAt: export.ExportedAt.Format(time.RFC3339),
+		Balance: BalanceResponse{
+			Current:   export.Balance.Current,
+			Withdrawn: export.Balance.Withdraw,
+		},
+		Orders:     make([]OrderResponse, 0, len(export.Orders)),
+		Operations: make([]OperationResponse, 0, len(export.Operations)),
+	}
+	for _, order := range export.Orders {
+		response.Orders = append(response.Orders, OrderResponse{
+			Number:     string(order.Number),
+			Status:     string(order.Status),
+			Accrual:    order.Accrual,
+			UploadedAt: order.UploadedAt.Format(time.RFC3339),
+		})
+	}
+	for _, operation := range export.Operations {
+		response.Operations = append(response.Operations, OperationResponse{
+			Type:        string(operation.Type),
+			OrderNumber: string(operation.OrderNumber),
+			Accrual:     operation.Accrual,
+			Withdrawal:  operation.Withdrawal,
+			ProcessedAt: operation.ProcessedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
+
+// writeZip writes the export as a ZIP archive with a JSON file per section.
+func writeZip(w http.ResponseWriter, response ExportResponse) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", map[string]string{"login": response.Login, "exported_at": response.ExportedAt}},
+		{"balance.json", response.Balance},
+		{"orders.json", response.Orders},
+		{"operations.json", response.Operations},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.data)
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AccountDeleter is an autogenerated mock type for the AccountDeleter type
+type AccountDeleter struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, userUUID
+func (_m *AccountDeleter) Delete(ctx context.Context, userUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAccountDeleter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccountDeleter creates a new instance of AccountDeleter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccountDeleter(t mockConstructorTestingTNewAccountDeleter) *AccountDeleter {
+	mock := &AccountDeleter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
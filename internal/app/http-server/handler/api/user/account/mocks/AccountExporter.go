@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AccountExporter is an autogenerated mock type for the AccountExporter type
+type AccountExporter struct {
+	mock.Mock
+}
+
+// Export provides a mock function with given fields: ctx, userUUID
+func (_m *AccountExporter) Export(ctx context.Context, userUUID uuid.UUID) (*entity.UserExport, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 *entity.UserExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*entity.UserExport, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *entity.UserExport); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAccountExporter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccountExporter creates a new instance of AccountExporter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccountExporter(t mockConstructorTestingTNewAccountExporter) *AccountExporter {
+	mock := &AccountExporter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
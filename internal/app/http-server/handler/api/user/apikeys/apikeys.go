@@ -0,0 +1,322 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// APIKeyCreator is an interface for creating API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=APIKeyCreator
+type APIKeyCreator interface {
+	CreateAPIKey(ctx context.Context, user *entity.User, name string, scopes []string) (*entity.APIKey, string, error)
+}
+
+// APIKeyLister is an interface for listing API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=APIKeyLister
+type APIKeyLister interface {
+	ListAPIKeys(ctx context.Context, user *entity.User) ([]entity.APIKey, error)
+}
+
+// APIKeyRevoker is an interface for revoking API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=APIKeyRevoker
+type APIKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, user *entity.User, keyUUID uuid.UUID) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// Request struct for HTTP API key creation Request in JSON
+type Request struct {
+	Name   string   `json:"name" validate:"required,max=100" example:"POS terminal #1"`
+	Scopes []string `json:"scopes" validate:"required,min=1" example:"orders:write,balance:read"`
+}
+
+// Response is an API key response.
+type Response struct {
+	ID         string   `json:"id" example:"5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"`
+	Name       string   `json:"name" example:"POS terminal #1"`
+	Scopes     []string `json:"scopes" example:"orders:write,balance:read"`
+	CreatedAt  string   `json:"created_at" example:"2020-12-10T15:15:45+03:00"`
+	LastUsedAt string   `json:"last_used_at,omitempty" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// CreateResponse is a response with a new API key.
+type CreateResponse struct {
+	Response
+	Key string `json:"key" example:"gm_mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"`
+}
+
+// NewCreator returned func for creating an API key.
+//
+//	@Tags			User
+//	@Summary		Создание API ключа.
+//	@Description	Эндпоинт создаёт API ключ для терминалов и других сервисов, работающих от имени пользователя.
+//	@Description	Ключ показывается один раз, хранится только его хэш.
+//	@Description	Ключ передаётся в заголовке Authorization вместо JWT и действует только на маршрутах с выданными ему правами.
+//	@Description	Доступные права: orders:read, orders:write, balance:read, balance:write.
+//	@Description	Администратор может создать ключ для другого пользователя через /admin/users/{userID}/api-keys.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/user/api-keys [post]
+//	@Router			/admin/users/{userID}/api-keys [post]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Request			body		apikeys.Request			true	"API Key Request"
+//	@Success		201				{object}	apikeys.CreateResponse	"API key created"
+//	@Failure		400				"Bad request or unknown scope"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage API keys or role has no permission"
+//	@Failure		404				"User not found"
+//	@Failure		500				"Internal server error"
+func NewCreator(log *logger.Logger, creator APIKeyCreator, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.apikeys.NewCreator"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		owner, err := keyOwner(r, user)
+		if err != nil {
+			logWith.Info("Invalid user ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req Request
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		apiKey, key, err := creator.CreateAPIKey(ctx, owner, req.Name, req.Scopes)
+		if err != nil {
+			if errors.Is(err, entity.ErrAPIKeyScopeInvalid) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, entity.ErrUserNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateResponse{Response: newResponse(apiKey), Key: key})
+		logWith.Info("API key created")
+	}
+}
+
+// NewLister returned func for listing the API keys of the user.
+//
+//	@Tags			User
+//	@Summary		Получение списка API ключей.
+//	@Description	Эндпоинт возвращает действующие API ключи пользователя без самих ключей.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/api-keys [get]
+//	@Router			/admin/users/{userID}/api-keys [get]
+//	@Param			Authorization	header		string				true	"JWT Token"
+//	@Success		200				{object}	[]apikeys.Response	"Successfully fetched API keys"
+//	@Success		204				"No content"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage API keys or role has no permission"
+//	@Failure		500				"Internal server error"
+func NewLister(log *logger.Logger, lister APIKeyLister, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.apikeys.NewLister"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		owner, err := keyOwner(r, user)
+		if err != nil {
+			logWith.Info("Invalid user ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		keys, err := lister.ListAPIKeys(ctx, owner)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		result := make([]Response, 0, len(keys))
+		for i := range keys {
+			result = append(result, newResponse(&keys[i]))
+		}
+
+		render.JSON(w, r, result)
+		logWith.Info("API keys successfully retrieved")
+	}
+}
+
+// NewRevoker returned func for revoking an API key.
+//
+//	@Tags			User
+//	@Summary		Отзыв API ключа.
+//	@Description	Эндпоинт отзывает API ключ пользователя, ключ перестаёт приниматься сразу.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		plain
+//	@Router			/user/api-keys/{id} [delete]
+//	@Router			/admin/users/{userID}/api-keys/{id} [delete]
+//	@Param			Authorization	header	string	true	"JWT Token"
+//	@Param			id				path	string	true	"API Key ID"
+//	@Success		204				"API key revoked"
+//	@Failure		400				"Invalid API key or user ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage API keys or role has no permission"
+//	@Failure		404				"API key not found"
+//	@Failure		500				"Internal server error"
+func NewRevoker(log *logger.Logger, revoker APIKeyRevoker, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.apikeys.NewRevoker"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		owner, err := keyOwner(r, user)
+		if err != nil {
+			logWith.Info("Invalid user ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		keyUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			logWith.Info("Invalid API key ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = revoker.RevokeAPIKey(ctx, owner, keyUUID)
+		if err != nil {
+			if errors.Is(err, entity.ErrAPIKeyNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("API key revoked", log.StringField("api_key_uuid", keyUUID.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// keyOwner returns the owner of the API keys: the user from the {userID} URL parameter on the admin routes,
+// or the authorized user otherwise.
+func keyOwner(r *http.Request, user *entity.User) (*entity.User, error) {
+	userID := chi.URLParam(r, "userID")
+	if userID == "" {
+		return user, nil
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.User{UUID: userUUID}, nil
+}
+
+// newResponse converts an API key to the response without the key hash.
+func newResponse(key *entity.APIKey) Response {
+	response := Response{
+		ID:        key.UUID.String(),
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.LastUsedAt != nil {
+		response.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,63 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyCreator is an autogenerated mock type for the APIKeyCreator type
+type APIKeyCreator struct {
+	mock.Mock
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, user, name, scopes
+func (_m *APIKeyCreator) CreateAPIKey(ctx context.Context, user *entity.User, name string, scopes []string) (*entity.APIKey, string, error) {
+	ret := _m.Called(ctx, user, name, scopes)
+
+	var r0 *entity.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string, []string) (*entity.APIKey, string, error)); ok {
+		return rf(ctx, user, name, scopes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string, []string) *entity.APIKey); ok {
+		r0 = rf(ctx, user, name, scopes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User, string, []string) string); ok {
+		r1 = rf(ctx, user, name, scopes)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *entity.User, string, []string) error); ok {
+		r2 = rf(ctx, user, name, scopes)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewAPIKeyCreator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAPIKeyCreator creates a new instance of APIKeyCreator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAPIKeyCreator(t mockConstructorTestingTNewAPIKeyCreator) *APIKeyCreator {
+	mock := &APIKeyCreator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyLister is an autogenerated mock type for the APIKeyLister type
+type APIKeyLister struct {
+	mock.Mock
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx, user
+func (_m *APIKeyLister) ListAPIKeys(ctx context.Context, user *entity.User) ([]entity.APIKey, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 []entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) ([]entity.APIKey, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) []entity.APIKey); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAPIKeyLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAPIKeyLister creates a new instance of APIKeyLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAPIKeyLister(t mockConstructorTestingTNewAPIKeyLister) *APIKeyLister {
+	mock := &APIKeyLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
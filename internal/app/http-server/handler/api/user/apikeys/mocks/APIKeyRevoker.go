@@ -0,0 +1,46 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// APIKeyRevoker is an autogenerated mock type for the APIKeyRevoker type
+type APIKeyRevoker struct {
+	mock.Mock
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, user, keyUUID
+func (_m *APIKeyRevoker) RevokeAPIKey(ctx context.Context, user *entity.User, keyUUID uuid.UUID) error {
+	ret := _m.Called(ctx, user, keyUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, uuid.UUID) error); ok {
+		r0 = rf(ctx, user, keyUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAPIKeyRevoker interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAPIKeyRevoker creates a new instance of APIKeyRevoker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAPIKeyRevoker(t mockConstructorTestingTNewAPIKeyRevoker) *APIKeyRevoker {
+	mock := &APIKeyRevoker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,6 +2,7 @@ package balance
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -33,13 +34,14 @@ type UserAuthorizer interface {
 //	@Tags			Balance
 //	@Summary		Получение баланса пользователя.
 //	@Description	Эндпоинт используется для получения текущего балaнаса пользователя.
-//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:read.
 //	@Produce		json
 //	@Accept			plain
 //	@Router			/user/balance [get]
-//	@Param			Authorization	header		string				true	"JWT Token"
+//	@Param			Authorization	header		string				true	"JWT Token or API key"
 //	@Success		200				{object}	balance.Response	"User balance successfully returned"
 //	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
 //	@Failure		500				"Internal server error"
 func New(log *logger.Logger, getter UserBalanceGetter, authorizer UserAuthorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -58,6 +60,10 @@ func New(log *logger.Logger, getter UserBalanceGetter, authorizer UserAuthorizer
 		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
 		if err != nil {
 			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -42,15 +41,17 @@ type Request struct {
 //	@Tags			Balance
 //	@Summary		Cнятие средств с баланса пользователя в пользу заказа
 //	@Description	Эндпоинт используется для снятия средств с баланса пользователя в пользу заказа
-//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:write.
+//	@Description	Заказ можно оплачивать несколькими списаниями.
 //	@Accept			json
 //	@Produce		plain
 //	@Router			/user/balance/withdraw [post]
 //	@Security		ApiKeyAuth
-//	@Param			Authorization	header	string				true	"JWT Token"
+//	@Param			Authorization	header	string				true	"JWT Token or API key"
 //	@Param			Request			body	withdraw.Request	true	"Withdraw Request"
 //	@Success		200				"Withdrawal request successfully sent"
 //	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
 //	@Failure		402				"Balance is insufficient"
 //	@Failure		422				"Order number is not valid"
 //	@Failure		400				"Invalid request"
@@ -72,6 +73,10 @@ func New(log *logger.Logger, executor BalanceOperationExecutor, authorizer UserA
 		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
 		if err != nil {
 			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -89,15 +94,8 @@ func New(log *logger.Logger, executor BalanceOperationExecutor, authorizer UserA
 			return
 		}
 
-		var orderNumber int
-		orderNumber, err = strconv.Atoi(request.OrderNumber)
-		if err != nil {
-			logWith.Info("Invalid order number", log.AnyField("order_id", request.OrderNumber))
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			return
-		}
-
-		if !luna.Valid(orderNumber) {
+		orderNumber := entity.OrderNumber(request.OrderNumber)
+		if !orderNumber.Digits() || !luna.Valid(request.OrderNumber) {
 			logWith.Info("Invalid order number", log.AnyField("order_id", request.OrderNumber))
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			return
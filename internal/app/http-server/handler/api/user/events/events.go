@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// EventSubscriber is an interface for subscribing to the events of the user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=EventSubscriber
+type EventSubscriber interface {
+	Subscribe(userUUID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event, func())
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// OrderResponse is the data of an order status event.
+type OrderResponse struct {
+	Number     string  `json:"number" example:"123124551"`
+	Status     string  `json:"status" example:"PROCESSED" enums:"NEW,PROCESSING,INVALID,PROCESSED"`
+	Accrual    float64 `json:"accrual,omitempty" example:"500"`
+	UploadedAt string  `json:"uploaded_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// BalanceResponse is the data of a balance event.
+type BalanceResponse struct {
+	Current   float64 `json:"current" example:"500.5"`
+	Withdrawn float64 `json:"withdrawn" example:"42"`
+}
+
+// New  returned func for streaming the events of the user as Server-Sent Events.
+//
+//	@Tags			Events
+//	@Summary		Поток событий пользователя
+//	@Description	Эндпоинт отдаёт поток Server-Sent Events со сменой статусов заказов (order_status) и изменениями баланса (balance).
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	Заголовок Last-Event-ID позволяет получить события, пропущенные после переподключения.
+//	@Description	Пока событий нет, сервер периодически отправляет комментарий heartbeat.
+//	@Accept			plain
+//	@Produce		text/event-stream
+//	@Router			/user/events [get]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Last-Event-ID	header		integer					false	"ID of the last received event"
+//	@Success		200				{object}	events.OrderResponse	"Stream of events, data of balance events is events.BalanceResponse"
+//	@Failure		400				"Invalid Last-Event-ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		500				"Streaming is not supported"
+func New(log *logger.Logger, subscriber EventSubscriber, authorizer UserAuthorizer, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.events.New"
+
+		reqID := middleware.GetReqID(r.Context())
+		ctx := context.WithValue(r.Context(), contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		// only the authorization is limited in time, the stream lives until the client leaves
+		authCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		user, err := authorizer.Authorize(authCtx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var lastEventID int64
+		if value := r.Header.Get("Last-Event-ID"); value != "" {
+			lastEventID, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				logWith.Info("Invalid Last-Event-ID", log.ErrorField(err))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			logWith.Error("Streaming is not supported")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		backlog, live, unsubscribe := subscriber.Subscribe(user.UUID, lastEventID)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		logWith.Info("Event stream started", log.AnyField("user_uuid", user.UUID))
+
+		for _, event := range backlog {
+			if err = writeEvent(w, event); err != nil {
+				logWith.Info("Failed to write event", log.ErrorField(err))
+				return
+			}
+		}
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logWith.Info("Event stream closed by client")
+				return
+			case <-ticker.C:
+				_, err = io.WriteString(w, ": heartbeat\n\n")
+			case event, ok := <-live:
+				if !ok {
+					// dropped as a slow consumer, the client resumes with Last-Event-ID
+					logWith.Info("Event stream dropped")
+					return
+				}
+				err = writeEvent(w, event)
+			}
+			if err != nil {
+				logWith.Info("Failed to write event", log.ErrorField(err))
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// eventData returns the response with the data of the event, nil for an empty event.
+func eventData(event entity.Event) any {
+	switch {
+	case event.Order != nil:
+		return OrderResponse{
+			Number:     string(event.Order.Number),
+			Status:     string(event.Order.Status),
+			Accrual:    event.Order.Accrual,
+			UploadedAt: event.Order.UploadedAt.Format(time.RFC3339),
+		}
+	case event.Balance != nil:
+		return BalanceResponse{
+			Current:   event.Balance.Current,
+			Withdrawn: event.Balance.Withdraw,
+		}
+	default:
+		return nil
+	}
+}
+
+// writeEvent writes the event in the Server-Sent Events format.
+func writeEvent(w io.Writer, event entity.Event) error {
+	data := eventData(event)
+	if data == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+	return err
+}
@@ -0,0 +1,83 @@
+package events_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/events"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/events/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNew(t *testing.T) {
+
+	user := &entity.User{UUID: uuid.New()}
+	uploadedAt := time.Date(2020, 12, 10, 15, 15, 45, 0, time.UTC)
+
+	t.Run("Events: Backlog, live events and heartbeat", func(t *testing.T) {
+		authorizerMock := mocks.NewUserAuthorizer(t)
+		authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+		live := make(chan entity.Event, 1)
+		live <- entity.Event{ID: 12, Type: entity.EventBalance, Balance: &entity.Balance{Current: 500, Withdraw: 42}}
+		subscriberMock := mocks.NewEventSubscriber(t)
+		subscriberMock.On("Subscribe", user.UUID, int64(10)).
+			Return(
+				[]entity.Event{{ID: 11, Type: entity.EventOrderStatus, Order: &entity.Order{
+					Number: "12345678903", Status: entity.OrderProcessed, Accrual: 500, UploadedAt: uploadedAt,
+				}}},
+				(<-chan entity.Event)(live),
+				func() {},
+			).
+			Once()
+
+		handler := events.New(logger.NewLogger(), subscriberMock, authorizerMock, 10*time.Millisecond)
+
+		req, err := http.NewRequest(http.MethodGet, "/api/user/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "JWT_test")
+		req.Header.Set("Last-Event-ID", "10")
+
+		// the dropped subscription ends the stream
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(live)
+		}()
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		require.Contains(t, body, "id: 11\nevent: order_status\n"+
+			`data: {"number":"12345678903","status":"PROCESSED","accrual":500,"uploaded_at":"2020-12-10T15:15:45Z"}`+"\n\n")
+		require.Contains(t, body, "id: 12\nevent: balance\n"+`data: {"current":500,"withdrawn":42}`+"\n\n")
+		require.Contains(t, body, ": heartbeat\n\n")
+	})
+
+	t.Run("Events: Invalid Last-Event-ID", func(t *testing.T) {
+		authorizerMock := mocks.NewUserAuthorizer(t)
+		authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+		subscriberMock := mocks.NewEventSubscriber(t)
+
+		handler := events.New(logger.NewLogger(), subscriberMock, authorizerMock, time.Second)
+
+		req, err := http.NewRequest(http.MethodGet, "/api/user/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "JWT_test")
+		req.Header.Set("Last-Event-ID", "abc")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,68 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// EventSubscriber is an autogenerated mock type for the EventSubscriber type
+type EventSubscriber struct {
+	mock.Mock
+}
+
+// Subscribe provides a mock function with given fields: userUUID, lastEventID
+func (_m *EventSubscriber) Subscribe(userUUID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event, func()) {
+	ret := _m.Called(userUUID, lastEventID)
+
+	var r0 []entity.Event
+	var r1 <-chan entity.Event
+	var r2 func()
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int64) ([]entity.Event, <-chan entity.Event, func())); ok {
+		return rf(userUUID, lastEventID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int64) []entity.Event); ok {
+		r0 = rf(userUUID, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int64) <-chan entity.Event); ok {
+		r1 = rf(userUUID, lastEventID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(<-chan entity.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uuid.UUID, int64) func()); ok {
+		r2 = rf(userUUID, lastEventID)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(func())
+		}
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewEventSubscriber interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewEventSubscriber creates a new instance of EventSubscriber. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewEventSubscriber(t mockConstructorTestingTNewEventSubscriber) *EventSubscriber {
+	mock := &EventSubscriber{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
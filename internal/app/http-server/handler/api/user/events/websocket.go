@@ -0,0 +1,159 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/websocket"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// writeWait is the time allowed to write a message to the client.
+const writeWait = 10 * time.Second
+
+// Message is an event sent over WebSocket.
+type Message struct {
+	ID   int64  `json:"id" example:"1700000000000000001"`
+	Type string `json:"type" example:"order_status" enums:"order_status,balance"`
+	// Data is events.OrderResponse or events.BalanceResponse according to Type.
+	Data any `json:"data"`
+}
+
+// NewWebSocket  returned func for pushing the events of the user over WebSocket.
+//
+//	@Tags			Events
+//	@Summary		Уведомления через WebSocket
+//	@Description	Эндпоинт открывает WebSocket соединение, по которому отправляются те же события, что и в /api/user/events.
+//	@Description	В заголовке Authorization при подключении необходимо передавать JWT токен.
+//	@Description	Сервер периодически отправляет ping и закрывает соединение, если клиент не отвечает pong или не успевает читать события.
+//	@Description	Параметр last_event_id позволяет получить события, пропущенные после переподключения.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/ws [get]
+//	@Param			Authorization	header		string			true	"JWT Token"
+//	@Param			last_event_id	query		integer			false	"ID of the last received event"
+//	@Success		101				{object}	events.Message	"Switching to WebSocket"
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"User is not authorized"
+func NewWebSocket(log *logger.Logger, subscriber EventSubscriber, authorizer UserAuthorizer, pingPeriod time.Duration) http.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+	// the client has a whole ping period to answer before the connection is considered dead
+	pongWait := 2 * pingPeriod
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.events.NewWebSocket"
+
+		reqID := middleware.GetReqID(r.Context())
+		ctx := context.WithValue(r.Context(), contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		authCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		user, err := authorizer.Authorize(authCtx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var lastEventID int64
+		if value := r.URL.Query().Get("last_event_id"); value != "" {
+			lastEventID, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				logWith.Info("Invalid last_event_id", log.ErrorField(err))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader has already replied with an error
+			logWith.Info("Failed to upgrade connection", log.ErrorField(err))
+			return
+		}
+		defer conn.Close()
+
+		backlog, live, unsubscribe := subscriber.Subscribe(user.UUID, lastEventID)
+		defer unsubscribe()
+		logWith.Info("WebSocket connection opened", log.AnyField("user_uuid", user.UUID))
+
+		// the reader handles pongs and close frames, the client is not expected to send messages
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			conn.SetReadLimit(512)
+			_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+			conn.SetPongHandler(func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(pongWait))
+			})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for _, event := range backlog {
+			if err = writeMessage(conn, event); err != nil {
+				logWith.Info("Failed to write event", log.ErrorField(err))
+				return
+			}
+		}
+
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closed:
+				logWith.Info("WebSocket connection closed by client")
+				return
+			case <-ticker.C:
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			case event, ok := <-live:
+				if !ok {
+					// dropped as a slow consumer, the client reconnects with last_event_id
+					logWith.Info("WebSocket connection dropped")
+					_ = conn.WriteControl(
+						websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"),
+						time.Now().Add(writeWait),
+					)
+					return
+				}
+				err = writeMessage(conn, event)
+			}
+			if err != nil {
+				logWith.Info("Failed to write to WebSocket", log.ErrorField(err))
+				return
+			}
+		}
+	}
+}
+
+// writeMessage writes the event as a JSON message.
+func writeMessage(conn *websocket.Conn, event entity.Event) error {
+	data := eventData(event)
+	if data == nil {
+		return nil
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(Message{ID: event.ID, Type: string(event.Type), Data: data})
+}
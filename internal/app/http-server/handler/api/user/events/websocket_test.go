@@ -0,0 +1,134 @@
+package events_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/events"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/events/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNewWebSocket(t *testing.T) {
+
+	user := &entity.User{UUID: uuid.New()}
+
+	t.Run("WebSocket: Backlog, live events, ping and drop", func(t *testing.T) {
+		authorizerMock := mocks.NewUserAuthorizer(t)
+		authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+		live := make(chan entity.Event, 1)
+		unsubscribed := make(chan struct{})
+		subscriberMock := mocks.NewEventSubscriber(t)
+		subscriberMock.On("Subscribe", user.UUID, int64(10)).
+			Return(
+				[]entity.Event{{ID: 11, Type: entity.EventBalance, Balance: &entity.Balance{Current: 500}}},
+				(<-chan entity.Event)(live),
+				func() { close(unsubscribed) },
+			).
+			Once()
+
+		srv := httptest.NewServer(events.NewWebSocket(logger.NewLogger(), subscriberMock, authorizerMock, 20*time.Millisecond))
+		defer srv.Close()
+
+		header := http.Header{}
+		header.Set("Authorization", "JWT_test")
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"?last_event_id=10", header)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		defer conn.Close()
+
+		pinged := make(chan struct{}, 1)
+		conn.SetPingHandler(func(data string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+
+		var message struct {
+			ID   int64          `json:"id"`
+			Type string         `json:"type"`
+			Data map[string]any `json:"data"`
+		}
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, int64(11), message.ID)
+		require.Equal(t, "balance", message.Type)
+		require.Equal(t, float64(500), message.Data["current"])
+
+		live <- entity.Event{ID: 12, Type: entity.EventOrderStatus, Order: &entity.Order{Number: "12345678903", Status: entity.OrderProcessed}}
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, int64(12), message.ID)
+		require.Equal(t, "12345678903", message.Data["number"])
+
+		// pings arrive while the client waits, then the dropped subscription closes the connection
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			close(live)
+		}()
+		_, _, err = conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+		<-unsubscribed
+		require.Len(t, pinged, 1)
+	})
+
+	t.Run("WebSocket: Client without pongs is disconnected", func(t *testing.T) {
+		authorizerMock := mocks.NewUserAuthorizer(t)
+		authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+		unsubscribed := make(chan struct{})
+		subscriberMock := mocks.NewEventSubscriber(t)
+		subscriberMock.On("Subscribe", user.UUID, int64(0)).
+			Return([]entity.Event(nil), (<-chan entity.Event)(make(chan entity.Event)), func() { close(unsubscribed) }).
+			Once()
+
+		srv := httptest.NewServer(events.NewWebSocket(logger.NewLogger(), subscriberMock, authorizerMock, 20*time.Millisecond))
+		defer srv.Close()
+
+		header := http.Header{}
+		header.Set("Authorization", "JWT_test")
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		defer conn.Close()
+		conn.SetPingHandler(func(string) error { return nil })
+
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		select {
+		case <-unsubscribed:
+		case <-time.After(time.Second):
+			t.Fatal("connection is not closed")
+		}
+	})
+
+	t.Run("WebSocket: Unauthorized", func(t *testing.T) {
+		authorizerMock := mocks.NewUserAuthorizer(t)
+		authorizerMock.On("Authorize", mock.Anything, "").Return(nil, entity.ErrUserSessionNotFound).Once()
+		subscriberMock := mocks.NewEventSubscriber(t)
+
+		srv := httptest.NewServer(events.NewWebSocket(logger.NewLogger(), subscriberMock, authorizerMock, time.Second))
+		defer srv.Close()
+
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+		require.Error(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
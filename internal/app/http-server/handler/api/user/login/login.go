@@ -29,6 +29,11 @@ type Request struct {
 	Password string `json:"password" validate:"required" example:"test_Password"`
 }
 
+// ChallengeResponse is a response when the second factor is required.
+type ChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"`
+}
+
 // New returned func for logging in a user.
 //
 //	@Tags			User
@@ -36,11 +41,15 @@ type Request struct {
 //	@Description	Эндпоинт используется для аутентификации пользователя.
 //	@Description	Логин приводится к нижнему регистру на стороне сервера
 //	@Description	В заголовке Authorization возвращается JWT токен для авторизации
+//	@Description	Если у пользователя включена двухфакторная аутентификация, возвращается 202 и токен
+//	@Description	подтверждения, который передаётся в /user/login/2fa вместе с кодом
 //	@Accept			json
 //	@Produce		plain
+//	@Produce		json
 //	@Router			/user/login [post]
 //	@Param			Request	body	login.Request	true	"Login Request"
 //	@Success		200		"User successfully authenticated"
+//	@Success		202		{object}	login.ChallengeResponse	"Second factor required"
 //	@Failure		401		"Login or password is wrong"
 //	@Failure		400		"Bad request"
 //	@Failure		500		"Internal server error"
@@ -78,6 +87,12 @@ func New(log *logger.Logger, service UserAuthenticator) http.HandlerFunc {
 
 		jwtString, err := service.Authenticate(ctx, req.Login, req.Password)
 		if err != nil {
+			if errors.Is(err, entity.ErrUserTwoFactorRequired) {
+				logWith.Info("Second factor required")
+				render.Status(r, http.StatusAccepted)
+				render.JSON(w, r, ChallengeResponse{ChallengeToken: jwtString})
+				return
+			}
 			if errors.Is(err, entity.ErrUserWrongPasswordOrLogin) {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
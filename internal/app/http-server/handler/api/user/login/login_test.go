@@ -46,6 +46,15 @@ func TestNew(t *testing.T) {
 			incorrectJSON: false,
 			statusCode:    http.StatusUnauthorized,
 		},
+		{
+			name:          "Login: Second factor required",
+			login:         "test_user",
+			password:      "TestPassword",
+			mockError:     entity.ErrUserTwoFactorRequired,
+			incorrectJSON: false,
+			jwt:           "challenge",
+			statusCode:    http.StatusAccepted,
+		},
 		{
 			name:          "Login: Incorrect JSON",
 			incorrectJSON: true,
@@ -96,7 +105,10 @@ func TestNew(t *testing.T) {
 
 			jwtResponse := rr.Header().Get("Authorization")
 
-			if !tc.incorrectJSON {
+			if tc.mockError == entity.ErrUserTwoFactorRequired {
+				require.Empty(t, jwtResponse)
+				require.JSONEq(t, fmt.Sprintf(`{"challenge_token": "%s"}`, tc.jwt), rr.Body.String())
+			} else if !tc.incorrectJSON {
 				require.Equal(t, tc.jwt, jwtResponse)
 			}
 
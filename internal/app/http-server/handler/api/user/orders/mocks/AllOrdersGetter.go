@@ -16,25 +16,23 @@ type AllOrdersGetter struct {
 	mock.Mock
 }
 
-// GetAll provides a mock function with given fields: ctx, userUUID
-func (_m *AllOrdersGetter) GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
-	ret := _m.Called(ctx, userUUID)
+// GetPage provides a mock function with given fields: ctx, userUUID, filter
+func (_m *AllOrdersGetter) GetPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error) {
+	ret := _m.Called(ctx, userUUID, filter)
 
-	var r0 []entity.Order
+	var r0 entity.OrderPage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Order, error)); ok {
-		return rf(ctx, userUUID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderFilter) (entity.OrderPage, error)); ok {
+		return rf(ctx, userUUID, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Order); ok {
-		r0 = rf(ctx, userUUID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderFilter) entity.OrderPage); ok {
+		r0 = rf(ctx, userUUID, filter)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]entity.Order)
-		}
+		r0 = ret.Get(0).(entity.OrderPage)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
-		r1 = rf(ctx, userUUID)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.OrderFilter) error); ok {
+		r1 = rf(ctx, userUUID, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	mock "github.com/stretchr/testify/mock"
 
 	uuid "github.com/google/uuid"
@@ -15,13 +16,13 @@ type OrderAdder struct {
 	mock.Mock
 }
 
-// Add provides a mock function with given fields: ctx, orderNumber, userUUID
-func (_m *OrderAdder) Add(ctx context.Context, orderNumber int, userUUID uuid.UUID) error {
-	ret := _m.Called(ctx, orderNumber, userUUID)
+// Add provides a mock function with given fields: ctx, orderNumber, userUUID, store
+func (_m *OrderAdder) Add(ctx context.Context, orderNumber entity.OrderNumber, userUUID uuid.UUID, store string) error {
+	ret := _m.Called(ctx, orderNumber, userUUID, store)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, uuid.UUID) error); ok {
-		r0 = rf(ctx, orderNumber, userUUID)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, orderNumber, userUUID, store)
 	} else {
 		r0 = ret.Error(0)
 	}
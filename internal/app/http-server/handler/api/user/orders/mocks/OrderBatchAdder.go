@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// OrderBatchAdder is an autogenerated mock type for the OrderBatchAdder type
+type OrderBatchAdder struct {
+	mock.Mock
+}
+
+// AddBatch provides a mock function with given fields: ctx, orderNumbers, userUUID, store
+func (_m *OrderBatchAdder) AddBatch(ctx context.Context, orderNumbers []entity.OrderNumber, userUUID uuid.UUID, store string) (map[entity.OrderNumber]entity.OrderUploadResult, error) {
+	ret := _m.Called(ctx, orderNumbers, userUUID, store)
+
+	var r0 map[entity.OrderNumber]entity.OrderUploadResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.OrderNumber, uuid.UUID, string) (map[entity.OrderNumber]entity.OrderUploadResult, error)); ok {
+		return rf(ctx, orderNumbers, userUUID, store)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.OrderNumber, uuid.UUID, string) map[entity.OrderNumber]entity.OrderUploadResult); ok {
+		r0 = rf(ctx, orderNumbers, userUUID, store)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[entity.OrderNumber]entity.OrderUploadResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []entity.OrderNumber, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, orderNumbers, userUUID, store)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewOrderBatchAdder interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderBatchAdder creates a new instance of OrderBatchAdder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderBatchAdder(t mockConstructorTestingTNewOrderBatchAdder) *OrderBatchAdder {
+	mock := &OrderBatchAdder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
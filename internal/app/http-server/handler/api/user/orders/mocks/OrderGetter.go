@@ -0,0 +1,65 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// OrderGetter is an autogenerated mock type for the OrderGetter type
+type OrderGetter struct {
+	mock.Mock
+}
+
+// GetWithHistory provides a mock function with given fields: ctx, userUUID, number
+func (_m *OrderGetter) GetWithHistory(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, []entity.OrderStatusChange, error) {
+	ret := _m.Called(ctx, userUUID, number)
+
+	var r0 entity.Order
+	var r1 []entity.OrderStatusChange
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) (entity.Order, []entity.OrderStatusChange, error)); ok {
+		return rf(ctx, userUUID, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) entity.Order); ok {
+		r0 = rf(ctx, userUUID, number)
+	} else {
+		r0 = ret.Get(0).(entity.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.OrderNumber) []entity.OrderStatusChange); ok {
+		r1 = rf(ctx, userUUID, number)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]entity.OrderStatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uuid.UUID, entity.OrderNumber) error); ok {
+		r2 = rf(ctx, userUUID, number)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewOrderGetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderGetter creates a new instance of OrderGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderGetter(t mockConstructorTestingTNewOrderGetter) *OrderGetter {
+	mock := &OrderGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// OrderRetractor is an autogenerated mock type for the OrderRetractor type
+type OrderRetractor struct {
+	mock.Mock
+}
+
+// Retract provides a mock function with given fields: ctx, userUUID, number
+func (_m *OrderRetractor) Retract(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) error {
+	ret := _m.Called(ctx, userUUID, number)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) error); ok {
+		r0 = rf(ctx, userUUID, number)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewOrderRetractor interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderRetractor creates a new instance of OrderRetractor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderRetractor(t mockConstructorTestingTNewOrderRetractor) *OrderRetractor {
+	mock := &OrderRetractor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,11 +2,17 @@ package orders
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
@@ -21,9 +27,12 @@ import (
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderAdder
 type OrderAdder interface {
-	Add(ctx context.Context, orderNumber int, userUUID uuid.UUID) error
+	Add(ctx context.Context, orderNumber entity.OrderNumber, userUUID uuid.UUID, store string) error
 }
 
+// storeMaxLength is the longest store name accepted with an order.
+const storeMaxLength = 128
+
 // UserAuthorizer is an interface for authorizing users.
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
@@ -36,16 +45,19 @@ type UserAuthorizer interface {
 //	@Tags			Order
 //	@Summary		Добавление нового заказа для начисления средств
 //	@Description	Эндпоинт используется для добавления нового заказа для начисления средств.
-//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.
+//	@Description	Необязательный параметр store указывает магазин заказа, заказ направляется в систему начислений этого магазина.
 //	@Accept			plain
 //	@Produce		plain
 //	@Router			/api/user/orders [post]
-//	@Param			Authorization	header	string	true	"JWT Token"
-//	@Param			Order			body	integer	true	"Order Number"	example(123124551)
+//	@Param			Authorization	header	string	true	"JWT Token or API key"
+//	@Param			Order			body	string	true	"Order Number, digits or a JSON string"	example(123124551)
+//	@Param			store			query	string	false	"Store the order was made in, routes it to the accrual system of the store"
 //	@Success		200				"Order already added from current user"
 //	@Success		202				"Order successfully added to process"
 //	@Failure		400				"Invalid request"
 //	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
 //	@Failure		409				"Order already added from another user"
 //	@Failure		422				"Order number is not valid"
 //	@Failure		500				"Internal server error"
@@ -66,25 +78,35 @@ func NewAdder(log *logger.Logger, adder OrderAdder, authorizer UserAuthorizer) h
 		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
 		if err != nil {
 			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		var orderID int
-		err = render.DecodeJSON(r.Body, &orderID)
+		orderID, err := decodeNumber(r)
 		if err != nil {
 			logWith.Info("Failed to decode request body", log.ErrorField(err))
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		if !luna.Valid(orderID) {
+		if !luna.Valid(string(orderID)) {
 			logWith.Info("Invalid order number", log.AnyField("order_id", orderID))
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			return
 		}
 
-		err = adder.Add(ctx, orderID, user.UUID)
+		store := r.URL.Query().Get("store")
+		if len(store) > storeMaxLength {
+			logWith.Info("Store is too long", log.AnyField("store_length", len(store)))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = adder.Add(ctx, orderID, user.UUID, store)
 		if err != nil {
 			if errors.Is(err, entity.ErrOrderAlreadyUploadedByAnotherUser) {
 				w.WriteHeader(http.StatusConflict)
@@ -106,20 +128,209 @@ func NewAdder(log *logger.Logger, adder OrderAdder, authorizer UserAuthorizer) h
 	}
 }
 
+// decodeNumber reads an order number sent as plain digits or as a JSON string.
+// Digits are kept as they are, so leading zeros and numbers of any length survive.
+func decodeNumber(r *http.Request) (entity.OrderNumber, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, entity.OrderNumberMaxLength+16))
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(body))
+	if strings.HasPrefix(value, `"`) {
+		err = json.Unmarshal([]byte(value), &value)
+		if err != nil {
+			return "", err
+		}
+	}
+	number := entity.OrderNumber(value)
+	if !number.Digits() {
+		return "", fmt.Errorf("invalid order number %q", value)
+	}
+	return number, nil
+}
+
+// batchMaxBodySize is the largest batch body accepted: a full batch of the longest numbers,
+// every number quoted and followed by a separator.
+const batchMaxBodySize = entity.OrderBatchMaxSize * (entity.OrderNumberMaxLength + 4)
+
+// OrderBatchAdder is an interface for adding a batch of orders to the user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderBatchAdder
+type OrderBatchAdder interface {
+	AddBatch(ctx context.Context, orderNumbers []entity.OrderNumber, userUUID uuid.UUID, store string) (map[entity.OrderNumber]entity.OrderUploadResult, error)
+}
+
+// NewBatchAdder  returned func for adding a batch of orders to the user.
+//
+//	@Tags			Order
+//	@Summary		Пакетная загрузка заказов
+//	@Description	Эндпоинт принимает JSON массив номеров заказов или номера заказов, разделённые переводом строки.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.
+//	@Description	Для каждого номера возвращается результат загрузки:
+//	@Description	accepted — заказ принят в обработку;
+//	@Description	already_uploaded — заказ уже загружен текущим пользователем;
+//	@Description	owned_by_another_user — заказ уже загружен другим пользователем;
+//	@Description	invalid — номер заказа не прошёл проверку по алгоритму Луна.
+//	@Description	Необязательный параметр store указывает магазин заказов, заказы направляются в систему начислений этого магазина.
+//	@Accept			json,plain
+//	@Produce		json
+//	@Router			/api/user/orders/batch [post]
+//	@Param			Authorization	header		string					true	"JWT Token or API key"
+//	@Param			Orders			body		[]string				true	"Order Numbers"
+//	@Param			store			query		string					false	"Store the orders were made in, routes them to the accrual system of the store"
+//	@Success		200				{object}	[]orders.BatchResult	"Upload result of every number"
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
+//	@Failure		413				"Request body is too large"
+//	@Failure		500				"Internal server error"
+func NewBatchAdder(log *logger.Logger, adder OrderBatchAdder, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.orders.NewBatchAdder"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		store := r.URL.Query().Get("store")
+		if len(store) > storeMaxLength {
+			logWith.Info("Store is too long", log.AnyField("store_length", len(store)))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, batchMaxBodySize)
+		input, err := decodeBatch(r)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(input) == 0 || len(input) > entity.OrderBatchMaxSize {
+			logWith.Info("Invalid batch size", log.AnyField("count", len(input)))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		numbers := make([]entity.OrderNumber, 0, len(input))
+		for _, value := range input {
+			number := entity.OrderNumber(value)
+			if number.Digits() && luna.Valid(value) {
+				numbers = append(numbers, number)
+			}
+		}
+
+		results := map[entity.OrderNumber]entity.OrderUploadResult{}
+		if len(numbers) > 0 {
+			results, err = adder.AddBatch(ctx, numbers, user.UUID, store)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		response := make([]BatchResult, 0, len(input))
+		for _, value := range input {
+			result := entity.OrderUploadInvalid
+			if res, ok := results[entity.OrderNumber(value)]; ok {
+				result = res
+			}
+			response = append(response, BatchResult{Number: value, Result: string(result)})
+		}
+
+		render.JSON(w, r, response)
+		logWith.Info(
+			"Orders batch successfully processed",
+			log.AnyField("count", len(input)),
+			log.AnyField("user_uuid", user.UUID),
+		)
+	}
+}
+
+// decodeBatch reads order numbers from a JSON array of numbers or strings, or from newline-separated text.
+func decodeBatch(r *http.Request) ([]string, error) {
+	if render.GetRequestContentType(r) == render.ContentTypeJSON {
+		var raw []json.RawMessage
+		err := render.DecodeJSON(r.Body, &raw)
+		if err != nil {
+			return nil, err
+		}
+		numbers := make([]string, 0, len(raw))
+		for _, item := range raw {
+			var number string
+			if json.Unmarshal(item, &number) != nil {
+				number = string(item)
+			}
+			numbers = append(numbers, strings.TrimSpace(number))
+		}
+		return numbers, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []string
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			numbers = append(numbers, line)
+		}
+	}
+	return numbers, nil
+}
+
+// BatchResult is an upload result of an order number.
+type BatchResult struct {
+	Number string `json:"number" example:"123124551"`
+	Result string `json:"result" example:"accepted" enums:"accepted,already_uploaded,owned_by_another_user,invalid"`
+}
+
 // AllOrdersGetter is an interface for getting an orders from the user.
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AllOrdersGetter
 type AllOrdersGetter interface {
-	GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error)
+	GetPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error)
 }
 
+const (
+	// TotalCountHeader is the header with the number of orders matching the filter on all pages.
+	TotalCountHeader = "X-Total-Count"
+	// NextCursorHeader is the header with the cursor of the next page, absent on the last page.
+	NextCursorHeader = "X-Next-Cursor"
+)
+
 // NewAllGetter  returned func for getting all orders from the user.
 //
 //	@Tags			Order
 //	@Summary		Получение списка загруженных заказов
 //	@Description	Эндпоинт для получение списка загруженных номеров заказов и информации по ним
-//	@Description	В заголовке Authorization необходимо передавать JWT токен.
-//	@Description	Номера заказа в выдаче должны быть отсортированы по времени загрузки от самых старых к самым новым. Формат даты — RFC3339.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:read.
+//	@Description	Номера заказа в выдаче отсортированы по времени загрузки от самых старых к самым новым, sort=-uploaded_at меняет порядок на обратный. Формат даты — RFC3339.
+//	@Description	Выдача разбита на страницы размером limit, курсор следующей страницы возвращается в заголовке X-Next-Cursor и передаётся в параметре cursor.
+//	@Description	Количество заказов, подходящих под фильтр, на всех страницах возвращается в заголовке X-Total-Count.
 //	@Description	Доступные статусы обработки расчётов:
 //	@Description	NEW — заказ загружен в систему, но не попал в обработку;
 //	@Description	PROCESSING — вознаграждение за заказ рассчитывается;
@@ -128,10 +339,20 @@ type AllOrdersGetter interface {
 //	@Accept			plain
 //	@Produce		json
 //	@Router			/api/user/orders [get]
-//	@Param			Authorization	header		string				true	"JWT Token"
+//	@Param			Authorization	header		string				true	"JWT Token or API key"
+//	@Param			limit			query		integer				false	"Page size"	minimum(1)	maximum(1000)	default(1000)
+//	@Param			cursor			query		string				false	"Cursor of the page from X-Next-Cursor"
+//	@Param			status			query		[]string			false	"Order statuses"	collectionFormat(csv)	Enums(NEW,PROCESSING,INVALID,PROCESSED)
+//	@Param			from			query		string				false	"Uploaded at or after, RFC3339"	example(2020-12-01T00:00:00+03:00)
+//	@Param			to				query		string				false	"Uploaded before, RFC3339"		example(2021-01-01T00:00:00+03:00)
+//	@Param			sort			query		string				false	"Sort order"	Enums(uploaded_at,-uploaded_at)	default(uploaded_at)
 //	@Success		200				{object}	[]orders.Response	"Successfully fetched orders"
+//	@Header			200				{integer}	X-Total-Count		"Number of orders matching the filter"
+//	@Header			200				{string}	X-Next-Cursor		"Cursor of the next page"
 //	@Success		204				"No content"
+//	@Failure		400				"Invalid query parameters"
 //	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
 //	@Failure		500				"Internal server error"
 func NewAllGetter(log *logger.Logger, getter AllOrdersGetter, authorizer UserAuthorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -150,24 +371,40 @@ func NewAllGetter(log *logger.Logger, getter AllOrdersGetter, authorizer UserAut
 		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
 		if err != nil {
 			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		orders, err := getter.GetAll(ctx, user.UUID)
+		filter, err := parseFilter(r)
+		if err != nil {
+			logWith.Info("Invalid query parameters", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		page, err := getter.GetPage(ctx, user.UUID, filter)
 		if err != nil {
-			if errors.Is(err, entity.ErrOrderNotFound) {
-				w.WriteHeader(http.StatusNoContent)
-				return
-			}
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		result := make([]Response, 0, len(orders))
-		for _, t := range orders {
+		w.Header().Set(TotalCountHeader, strconv.Itoa(page.Total))
+		if page.Next != nil {
+			w.Header().Set(NextCursorHeader, encodeCursor(*page.Next))
+		}
+		if len(page.Orders) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		result := make([]Response, 0, len(page.Orders))
+		for _, t := range page.Orders {
 			order := Response{
-				Number:     fmt.Sprintf("%d", t.Number),
+				Number:     string(t.Number),
 				Status:     string(t.Status),
 				Accrual:    t.Accrual,
 				UploadedAt: t.UploadedAt.Format(time.RFC3339),
@@ -181,6 +418,91 @@ func NewAllGetter(log *logger.Logger, getter AllOrdersGetter, authorizer UserAut
 	}
 }
 
+// parseFilter reads the order list filter from the query parameters.
+func parseFilter(r *http.Request) (entity.OrderFilter, error) {
+	query := r.URL.Query()
+	filter := entity.OrderFilter{Limit: entity.OrdersPageMaxLimit}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > entity.OrdersPageMaxLimit {
+			return entity.OrderFilter{}, fmt.Errorf("invalid limit %q", value)
+		}
+		filter.Limit = limit
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err != nil {
+			return entity.OrderFilter{}, err
+		}
+		filter.After = &cursor
+	}
+
+	if value := query.Get("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			switch entity.Status(status) {
+			case entity.OrderNew, entity.OrderProcessing, entity.OrderInvalid, entity.OrderProcessed:
+				filter.Statuses = append(filter.Statuses, entity.Status(status))
+			default:
+				return entity.OrderFilter{}, fmt.Errorf("invalid status %q", status)
+			}
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.UploadedFrom, "to": &filter.UploadedTo} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return entity.OrderFilter{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		// uploaded_at is stored without a zone as the server wall clock,
+		// the bound is compared in the same clock whatever offset the client sent
+		*target = t.In(time.Local)
+	}
+
+	switch query.Get("sort") {
+	case "", "uploaded_at":
+	case "-uploaded_at":
+		filter.Descending = true
+	default:
+		return entity.OrderFilter{}, fmt.Errorf("invalid sort %q", query.Get("sort"))
+	}
+
+	return filter, nil
+}
+
+// encodeCursor returns an opaque string for the cursor.
+func encodeCursor(cursor entity.OrderCursor) string {
+	raw := fmt.Sprintf("%d:%s", cursor.UploadedAt.UnixNano(), cursor.Number)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor returned by encodeCursor.
+func decodeCursor(value string) (entity.OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return entity.OrderCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	uploadedAt, number, found := strings.Cut(string(raw), ":")
+	if !found {
+		return entity.OrderCursor{}, errors.New("invalid cursor")
+	}
+	nanos, err := strconv.ParseInt(uploadedAt, 10, 64)
+	if err != nil {
+		return entity.OrderCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	n := entity.OrderNumber(number)
+	if !n.Digits() {
+		return entity.OrderCursor{}, errors.New("invalid cursor")
+	}
+	// the cursor keeps uploaded_at as it was read from the storage, a zoneless timestamp comes back in UTC
+	return entity.OrderCursor{UploadedAt: time.Unix(0, nanos).UTC(), Number: n}, nil
+}
+
 // Response is an order response.
 type Response struct {
 	Number     string  `json:"number" example:"123124551"`
@@ -188,3 +510,181 @@ type Response struct {
 	Accrual    float64 `json:"accrual,omitempty" example:"500"`
 	UploadedAt string  `json:"uploaded_at" example:"2020-12-10T15:15:45+03:00"`
 }
+
+// OrderGetter is an interface for getting an order of the user with its status history.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderGetter
+type OrderGetter interface {
+	GetWithHistory(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, []entity.OrderStatusChange, error)
+}
+
+// NewGetter  returned func for getting an order of the user with its status history.
+//
+//	@Tags			Order
+//	@Summary		Получение заказа с историей статусов
+//	@Description	Эндпоинт возвращает текущее состояние заказа и историю смены его статусов с временем и суммой начисления.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:read.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/api/user/orders/{number} [get]
+//	@Param			Authorization	header		string					true	"JWT Token or API key"
+//	@Param			number			path		string					true	"Order Number"
+//	@Success		200				{object}	orders.DetailResponse	"Successfully fetched order"
+//	@Failure		400				"Invalid order number"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
+//	@Failure		404				"Order not found"
+//	@Failure		500				"Internal server error"
+func NewGetter(log *logger.Logger, getter OrderGetter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.orders.NewGetter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		number := entity.OrderNumber(chi.URLParam(r, "number"))
+		if !number.Digits() {
+			logWith.Info("Invalid order number", log.AnyField("order_id", number))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		order, history, err := getter.GetWithHistory(ctx, user.UUID, number)
+		if err != nil {
+			if errors.Is(err, entity.ErrOrderNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		result := DetailResponse{
+			Response: Response{
+				Number:     string(order.Number),
+				Status:     string(order.Status),
+				Accrual:    order.Accrual,
+				UploadedAt: order.UploadedAt.Format(time.RFC3339),
+			},
+			History: make([]StatusChangeResponse, 0, len(history)),
+		}
+		for _, change := range history {
+			result.History = append(result.History, StatusChangeResponse{
+				Status:    string(change.Status),
+				Accrual:   change.Accrual,
+				ChangedAt: change.ChangedAt.Format(time.RFC3339),
+			})
+		}
+
+		render.JSON(w, r, result)
+		logWith.Info("Order successfully retrieved", log.AnyField("order_id", number))
+	}
+}
+
+// DetailResponse is an order response with the status history.
+type DetailResponse struct {
+	Response
+	History []StatusChangeResponse `json:"history"`
+}
+
+// StatusChangeResponse is a status transition of an order.
+type StatusChangeResponse struct {
+	Status    string  `json:"status" example:"PROCESSED" enums:"NEW,PROCESSING,INVALID,PROCESSED"`
+	Accrual   float64 `json:"accrual,omitempty" example:"500"`
+	ChangedAt string  `json:"changed_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// OrderRetractor is an interface for retracting an order of the user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderRetractor
+type OrderRetractor interface {
+	Retract(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) error
+}
+
+// NewRetractor  returned func for retracting an order of the user.
+//
+//	@Tags			Order
+//	@Summary		Отзыв загруженного заказа
+//	@Description	Эндпоинт удаляет заказ пользователя, пока он в статусе NEW или после того, как получил статус INVALID.
+//	@Description	Заказ исключается из обработки, номер снова можно загрузить. Отзыв сохраняется в журнале аудита.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом orders:write.
+//	@Accept			plain
+//	@Produce		plain
+//	@Router			/api/user/orders/{number} [delete]
+//	@Param			Authorization	header	string	true	"JWT Token or API key"
+//	@Param			number			path	string	true	"Order Number"
+//	@Success		204				"Order retracted"
+//	@Failure		400				"Invalid order number"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
+//	@Failure		404				"Order not found"
+//	@Failure		409				"Order is already processing or processed"
+//	@Failure		500				"Internal server error"
+func NewRetractor(log *logger.Logger, retractor OrderRetractor, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.orders.NewRetractor"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		number := entity.OrderNumber(chi.URLParam(r, "number"))
+		if !number.Digits() {
+			logWith.Info("Invalid order number", log.AnyField("order_id", number))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = retractor.Retract(ctx, user.UUID, number)
+		if err != nil {
+			if errors.Is(err, entity.ErrOrderNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if errors.Is(err, entity.ErrOrderNotRetractable) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Order successfully retracted", log.AnyField("order_id", number))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
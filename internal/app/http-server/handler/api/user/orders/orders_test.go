@@ -0,0 +1,380 @@
+package orders_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/orders"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/orders/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNewAllGetter(t *testing.T) {
+
+	user := &entity.User{UUID: uuid.New()}
+	uploadedAt := time.Date(2020, 12, 10, 15, 15, 45, 0, time.UTC)
+	next := &entity.OrderCursor{UploadedAt: uploadedAt, Number: "12345678903"}
+
+	tests := []struct {
+		name       string
+		query      string
+		filter     entity.OrderFilter
+		page       entity.OrderPage
+		mockError  error
+		statusCode int
+		noCall     bool
+	}{
+		{
+			name:   "Orders: Default filter",
+			filter: entity.OrderFilter{Limit: entity.OrdersPageMaxLimit},
+			page: entity.OrderPage{
+				Orders: []entity.Order{{Number: "12345678903", Status: entity.OrderNew, UploadedAt: uploadedAt}},
+				Total:  1,
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:  "Orders: Full filter with next page",
+			query: "?limit=1&status=NEW,PROCESSED&from=2020-12-01T00:00:00Z&to=2021-01-01T00:00:00Z&sort=-uploaded_at",
+			filter: entity.OrderFilter{
+				Statuses:     []entity.Status{entity.OrderNew, entity.OrderProcessed},
+				UploadedFrom: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC).In(time.Local),
+				UploadedTo:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).In(time.Local),
+				Descending:   true,
+				Limit:        1,
+			},
+			page: entity.OrderPage{
+				Orders: []entity.Order{{Number: "12345678903", Status: entity.OrderNew, UploadedAt: uploadedAt}},
+				Total:  2,
+				Next:   next,
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Orders: Empty page",
+			filter:     entity.OrderFilter{Limit: entity.OrdersPageMaxLimit},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "Orders: Invalid limit",
+			query:      "?limit=0",
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Orders: Invalid status",
+			query:      "?status=REGISTERED",
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Orders: Invalid cursor",
+			query:      "?cursor=invalid",
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Orders: Invalid sort",
+			query:      "?sort=number",
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Orders: Repository error",
+			filter:     entity.OrderFilter{Limit: entity.OrdersPageMaxLimit},
+			mockError:  errors.New("repository error"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+			getterMock := mocks.NewAllOrdersGetter(t)
+			if !tc.noCall {
+				getterMock.On("GetPage", mock.Anything, user.UUID, tc.filter).
+					Return(tc.page, tc.mockError).
+					Once()
+			}
+
+			handler := orders.NewAllGetter(logger.NewLogger(), getterMock, authorizerMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/user/orders"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.page.Next == nil {
+				require.Empty(t, rr.Header().Get(orders.NextCursorHeader))
+				return
+			}
+
+			// the returned cursor is accepted back as the next page position
+			cursor := rr.Header().Get(orders.NextCursorHeader)
+			require.NotEmpty(t, cursor)
+			require.Equal(t, "2", rr.Header().Get(orders.TotalCountHeader))
+
+			getterMock.On("GetPage", mock.Anything, user.UUID, mock.MatchedBy(func(f entity.OrderFilter) bool {
+				return f.After != nil && f.After.Number == next.Number && f.After.UploadedAt.Equal(next.UploadedAt) &&
+					f.Limit == tc.filter.Limit && f.Descending == tc.filter.Descending
+			})).Return(entity.OrderPage{Total: 2}, nil).Once()
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+			req, err = http.NewRequest(http.MethodGet, "/api/user/orders"+tc.query+"&cursor="+cursor, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+		})
+	}
+}
+
+func TestNewAllGetter_NonUTCLocal(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	// the storage writes the wall clock of a time without its zone and reads it back in UTC
+	const stored = "2006-01-02 15:04:05.999999999"
+	user := &entity.User{UUID: uuid.New()}
+	last := entity.Order{Number: "12345678903", Status: entity.OrderNew, UploadedAt: time.Date(2020, 12, 10, 15, 15, 45, 123456000, time.UTC)}
+
+	authorizerMock := mocks.NewUserAuthorizer(t)
+	authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Twice()
+	getterMock := mocks.NewAllOrdersGetter(t)
+	getterMock.On("GetPage", mock.Anything, user.UUID, mock.MatchedBy(func(f entity.OrderFilter) bool {
+		return f.After == nil && f.UploadedFrom.Format(stored) == "2020-12-10 15:00:00"
+	})).Return(entity.OrderPage{
+		Orders: []entity.Order{last},
+		Total:  2,
+		Next:   &entity.OrderCursor{UploadedAt: last.UploadedAt, Number: last.Number},
+	}, nil).Once()
+	getterMock.On("GetPage", mock.Anything, user.UUID, mock.MatchedBy(func(f entity.OrderFilter) bool {
+		return f.After != nil && f.After.Number == last.Number && f.After.UploadedAt.Format(stored) == last.UploadedAt.Format(stored)
+	})).Return(entity.OrderPage{Total: 2}, nil).Once()
+
+	handler := orders.NewAllGetter(logger.NewLogger(), getterMock, authorizerMock)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/user/orders?limit=1&from=2020-12-10T12:00:00Z", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "JWT_test")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	cursor := rr.Header().Get(orders.NextCursorHeader)
+	require.NotEmpty(t, cursor)
+	req, err = http.NewRequest(http.MethodGet, "/api/user/orders?limit=1&from=2020-12-10T12:00:00Z&cursor="+cursor, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "JWT_test")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestNewBatchAdder(t *testing.T) {
+
+	user := &entity.User{UUID: uuid.New()}
+
+	tests := []struct {
+		name        string
+		contentType string
+		query       string
+		body        string
+		numbers     []entity.OrderNumber
+		store       string
+		results     map[entity.OrderNumber]entity.OrderUploadResult
+		mockError   error
+		statusCode  int
+		response    string
+	}{
+		{
+			name:        "Batch: JSON array",
+			contentType: "application/json",
+			body:        `["12345678903", "79927398713", "79927398710", "abc"]`,
+			numbers:     []entity.OrderNumber{"12345678903", "79927398713"},
+			results: map[entity.OrderNumber]entity.OrderUploadResult{
+				"12345678903": entity.OrderUploadAccepted,
+				"79927398713": entity.OrderUploadOwnedByAnotherUser,
+			},
+			statusCode: http.StatusOK,
+			response: `[{"number":"12345678903","result":"accepted"},{"number":"79927398713","result":"owned_by_another_user"},` +
+				`{"number":"79927398710","result":"invalid"},{"number":"abc","result":"invalid"}]`,
+		},
+		{
+			name:        "Batch: Newline-separated text with store",
+			contentType: "text/plain",
+			query:       "?store=shop",
+			body:        "12345678903\r\n\n79927398713\n",
+			numbers:     []entity.OrderNumber{"12345678903", "79927398713"},
+			store:       "shop",
+			results: map[entity.OrderNumber]entity.OrderUploadResult{
+				"12345678903": entity.OrderUploadAlreadyUploaded,
+				"79927398713": entity.OrderUploadAccepted,
+			},
+			statusCode: http.StatusOK,
+			response:   `[{"number":"12345678903","result":"already_uploaded"},{"number":"79927398713","result":"accepted"}]`,
+		},
+		{
+			name:        "Batch: Only invalid numbers",
+			contentType: "text/plain",
+			body:        "79927398710",
+			statusCode:  http.StatusOK,
+			response:    `[{"number":"79927398710","result":"invalid"}]`,
+		},
+		{
+			name:        "Batch: Empty",
+			contentType: "application/json",
+			body:        `[]`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Batch: Incorrect JSON",
+			contentType: "application/json",
+			body:        `{"number": "12345678903"}`,
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Batch: Store too long",
+			contentType: "text/plain",
+			query:       "?store=" + strings.Repeat("a", 129),
+			body:        "12345678903",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Batch: Body too large",
+			contentType: "text/plain",
+			body:        strings.Repeat("12345678903\n", entity.OrderBatchMaxSize*6),
+			statusCode:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "Batch: Repository error",
+			contentType: "text/plain",
+			body:        "12345678903",
+			numbers:     []entity.OrderNumber{"12345678903"},
+			mockError:   errors.New("repository error"),
+			statusCode:  http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+			adderMock := mocks.NewOrderBatchAdder(t)
+			if tc.numbers != nil {
+				adderMock.On("AddBatch", mock.Anything, tc.numbers, user.UUID, tc.store).
+					Return(tc.results, tc.mockError).
+					Once()
+			}
+
+			handler := orders.NewBatchAdder(logger.NewLogger(), adderMock, authorizerMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/user/orders/batch"+tc.query, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.response != "" {
+				require.JSONEq(t, tc.response, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewRetractor(t *testing.T) {
+
+	user := &entity.User{UUID: uuid.New()}
+
+	tests := []struct {
+		name       string
+		number     string
+		mockError  error
+		statusCode int
+		noCall     bool
+	}{
+		{
+			name:       "Retract: Success",
+			number:     "12345678903",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "Retract: Invalid number",
+			number:     "abc",
+			noCall:     true,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Retract: Order not found",
+			number:     "12345678903",
+			mockError:  entity.ErrOrderNotFound,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Retract: Order is processing",
+			number:     "12345678903",
+			mockError:  entity.ErrOrderNotRetractable,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Retract: Repository error",
+			number:     "12345678903",
+			mockError:  errors.New("repository error"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			authorizerMock.On("Authorize", mock.Anything, "JWT_test").Return(user, nil).Once()
+
+			retractorMock := mocks.NewOrderRetractor(t)
+			if !tc.noCall {
+				retractorMock.On("Retract", mock.Anything, user.UUID, entity.OrderNumber(tc.number)).
+					Return(tc.mockError).
+					Once()
+			}
+
+			router := chi.NewRouter()
+			router.Delete("/api/user/orders/{number}", orders.NewRetractor(logger.NewLogger(), retractorMock, authorizerMock))
+
+			req, err := http.NewRequest(http.MethodDelete, "/api/user/orders/"+tc.number, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "JWT_test")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PasswordChanger is an autogenerated mock type for the PasswordChanger type
+type PasswordChanger struct {
+	mock.Mock
+}
+
+// ChangePassword provides a mock function with given fields: ctx, user, oldPassword, newPassword
+func (_m *PasswordChanger) ChangePassword(ctx context.Context, user *entity.User, oldPassword string, newPassword string) error {
+	ret := _m.Called(ctx, user, oldPassword, newPassword)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string, string) error); ok {
+		r0 = rf(ctx, user, oldPassword, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPasswordChanger interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPasswordChanger creates a new instance of PasswordChanger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPasswordChanger(t mockConstructorTestingTNewPasswordChanger) *PasswordChanger {
+	mock := &PasswordChanger{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
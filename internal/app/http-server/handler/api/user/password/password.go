@@ -0,0 +1,106 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// PasswordChanger is an interface for changing the user password.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=PasswordChanger
+type PasswordChanger interface {
+	ChangePassword(ctx context.Context, user *entity.User, oldPassword, newPassword string) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// Request struct for HTTP Request in JSON
+type Request struct {
+	OldPassword string `json:"old_password" validate:"required" example:"test_Password"`
+	NewPassword string `json:"new_password" validate:"required" example:"new_test_Password"`
+}
+
+// New returned func for changing the user password.
+//
+//	@Tags			User
+//	@Summary		Смена пароля пользователя.
+//	@Description	Эндпоинт используется для смены пароля пользователя.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	Новый пароль проверяется политикой паролей, все остальные сессии пользователя завершаются.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/user/password [put]
+//	@Param			Authorization	header	string				true	"JWT Token"
+//	@Param			Request			body	password.Request	true	"Change Password Request"
+//	@Success		200				"Password changed"
+//	@Failure		400				"Bad request or new password does not satisfy the policy"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"Old password is wrong"
+//	@Failure		500				"Internal server error"
+func New(log *logger.Logger, changer PasswordChanger, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.password.New"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req Request
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = changer.ChangePassword(ctx, user, req.OldPassword, req.NewPassword)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserWrongPasswordOrLogin) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, entity.ErrPasswordPolicy) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Password changed")
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,104 @@
+package password_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/password"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/password/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestNew(t *testing.T) {
+	user := entity.NewUser("", "", "JWT_test", uuid.New())
+
+	tests := []struct {
+		name          string
+		authErr       error
+		mockError     error
+		incorrectJSON bool
+		statusCode    int
+	}{
+		{
+			name:       "Change password: Success",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Change password: Unauthorized",
+			authErr:    entity.ErrUserSessionNotFound,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "Change password: Incorrect JSON",
+			incorrectJSON: true,
+			statusCode:    http.StatusBadRequest,
+		},
+		{
+			name:       "Change password: Wrong old password",
+			mockError:  entity.ErrUserWrongPasswordOrLogin,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Change password: Rejected by policy",
+			mockError:  entity.ErrPasswordTooShort,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Change password: Repository error",
+			mockError:  errors.New("repository error"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorizerMock := mocks.NewUserAuthorizer(t)
+			if tc.authErr != nil {
+				authorizerMock.On("Authorize", mock.Anything, user.JWT).
+					Return(nil, tc.authErr).
+					Once()
+			} else {
+				authorizerMock.On("Authorize", mock.Anything, user.JWT).
+					Return(user, nil).
+					Once()
+			}
+
+			changerMock := mocks.NewPasswordChanger(t)
+			if tc.authErr == nil && !tc.incorrectJSON {
+				changerMock.On("ChangePassword", mock.Anything, user, "old", "new").
+					Return(tc.mockError).
+					Once()
+			}
+
+			log := logger.NewLogger()
+
+			handler := password.New(log, changerMock, authorizerMock)
+
+			input := fmt.Sprintf(`{"old_password": "%s", "new_password": "%s"}`, "old", "new")
+			if tc.incorrectJSON {
+				input = "incorrect JSON"
+			}
+
+			req, err := http.NewRequest(http.MethodPut, "/api/user/password", bytes.NewReader([]byte(input)))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", user.JWT)
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+		})
+	}
+}
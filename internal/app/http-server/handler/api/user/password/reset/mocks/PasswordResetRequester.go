@@ -0,0 +1,43 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PasswordResetRequester is an autogenerated mock type for the PasswordResetRequester type
+type PasswordResetRequester struct {
+	mock.Mock
+}
+
+// RequestPasswordReset provides a mock function with given fields: ctx, login
+func (_m *PasswordResetRequester) RequestPasswordReset(ctx context.Context, login string) error {
+	ret := _m.Called(ctx, login)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, login)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPasswordResetRequester interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPasswordResetRequester creates a new instance of PasswordResetRequester. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPasswordResetRequester(t mockConstructorTestingTNewPasswordResetRequester) *PasswordResetRequester {
+	mock := &PasswordResetRequester{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,43 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PasswordResetter is an autogenerated mock type for the PasswordResetter type
+type PasswordResetter struct {
+	mock.Mock
+}
+
+// ResetPassword provides a mock function with given fields: ctx, token, newPassword
+func (_m *PasswordResetter) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	ret := _m.Called(ctx, token, newPassword)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPasswordResetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPasswordResetter creates a new instance of PasswordResetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPasswordResetter(t mockConstructorTestingTNewPasswordResetter) *PasswordResetter {
+	mock := &PasswordResetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
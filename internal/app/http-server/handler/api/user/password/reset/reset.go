@@ -0,0 +1,157 @@
+package reset
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// PasswordResetRequester is an interface for requesting a password reset token.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=PasswordResetRequester
+type PasswordResetRequester interface {
+	RequestPasswordReset(ctx context.Context, login string) error
+}
+
+// PasswordResetter is an interface for resetting a password by a token.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=PasswordResetter
+type PasswordResetter interface {
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// Request struct for HTTP password reset token Request in JSON
+type Request struct {
+	Login string `json:"login" validate:"required" example:"test@test.com"`
+}
+
+// ResetRequest struct for HTTP password reset Request in JSON
+type ResetRequest struct {
+	Token       string `json:"token" validate:"required" example:"mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"`
+	NewPassword string `json:"new_password" validate:"required" example:"new_test_Password"`
+}
+
+// NewRequester returned func for requesting a password reset token.
+//
+//	@Tags			User
+//	@Summary		Запрос на сброс пароля.
+//	@Description	Эндпоинт используется для получения одноразового токена сброса пароля.
+//	@Description	Логин приводится к нижнему регистру на стороне сервера
+//	@Description	Токен доставляется пользователю через канал уведомлений, ответ не зависит от существования логина.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/user/password/reset-request [post]
+//	@Param			Request	body	reset.Request	true	"Password Reset Token Request"
+//	@Success		202		"Request accepted"
+//	@Failure		400		"Bad request"
+//	@Failure		500		"Internal server error"
+func NewRequester(log *logger.Logger, requester PasswordResetRequester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.password.reset.NewRequester"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		var req Request
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		//convert Login to lower case
+		req.Login = strings.ToLower(req.Login)
+
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = requester.RequestPasswordReset(ctx, req.Login)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Password reset requested")
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// NewResetter returned func for resetting a password by a token.
+//
+//	@Tags			User
+//	@Summary		Сброс пароля по токену.
+//	@Description	Эндпоинт используется для установки нового пароля по одноразовому токену сброса.
+//	@Description	Новый пароль проверяется политикой паролей, все сессии пользователя завершаются.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/user/password/reset [post]
+//	@Param			Request	body	reset.ResetRequest	true	"Password Reset Request"
+//	@Success		200		"Password changed"
+//	@Failure		400		"Bad request or new password does not satisfy the policy"
+//	@Failure		401		"Token is unknown, used or expired"
+//	@Failure		500		"Internal server error"
+func NewResetter(log *logger.Logger, resetter PasswordResetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.password.reset.NewResetter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		var req ResetRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = resetter.ResetPassword(ctx, req.Token, req.NewPassword)
+		if err != nil {
+			if errors.Is(err, entity.ErrPasswordResetTokenInvalid) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if errors.Is(err, entity.ErrPasswordPolicy) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Password reset")
+		w.WriteHeader(http.StatusOK)
+	}
+}
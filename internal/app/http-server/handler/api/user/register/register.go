@@ -35,6 +35,7 @@ type Request struct {
 //	@Summary		Регистрация нового пользователя.
 //	@Description	Эндпоинт используется для регистрации нового пользователя.
 //	@Description	Логин приводится к нижнему регистру на стороне сервера
+//	@Description	Пароль проверяется политикой паролей: длина, классы символов, отсутствие логина, распространённые пароли
 //	@Description	В заголовке Authorization возвращается JWT токен авторизации
 //	@Accept			json
 //	@Produce		plain
@@ -42,7 +43,7 @@ type Request struct {
 //	@Param			Request	body	register.Request	true	"Register Request"
 //	@Success		200		"User registered successfully"
 //	@Failure		409		"User already exists"
-//	@Failure		400		"Bad request"
+//	@Failure		400		"Bad request or password does not satisfy the policy"
 //	@Failure		500		"Internal server error"
 //	@Header			200		{string}	Authorization	"JWT Token"
 func New(log *logger.Logger, service UserRegistrar) http.HandlerFunc {
@@ -82,6 +83,10 @@ func New(log *logger.Logger, service UserRegistrar) http.HandlerFunc {
 				w.WriteHeader(http.StatusConflict)
 				return
 			}
+			if errors.Is(err, entity.ErrPasswordPolicy) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
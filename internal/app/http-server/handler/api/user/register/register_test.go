@@ -47,6 +47,14 @@ func TestNew(t *testing.T) {
 			incorrectJSON: false,
 			statusCode:    http.StatusConflict,
 		},
+		{
+			name:          "Registration: Password rejected by policy",
+			login:         "test_user",
+			password:      "password",
+			mockError:     entity.ErrPasswordTooCommon,
+			incorrectJSON: false,
+			statusCode:    http.StatusBadRequest,
+		},
 		{
 			name:          "Registration: Incorrect JSON",
 			incorrectJSON: true,
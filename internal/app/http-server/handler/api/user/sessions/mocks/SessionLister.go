@@ -0,0 +1,56 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SessionLister is an autogenerated mock type for the SessionLister type
+type SessionLister struct {
+	mock.Mock
+}
+
+// ListSessions provides a mock function with given fields: ctx, user
+func (_m *SessionLister) ListSessions(ctx context.Context, user *entity.User) ([]entity.Session, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 []entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) ([]entity.Session, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) []entity.Session); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSessionLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSessionLister creates a new instance of SessionLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSessionLister(t mockConstructorTestingTNewSessionLister) *SessionLister {
+	mock := &SessionLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
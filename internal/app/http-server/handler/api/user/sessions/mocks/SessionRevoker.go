@@ -0,0 +1,46 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// SessionRevoker is an autogenerated mock type for the SessionRevoker type
+type SessionRevoker struct {
+	mock.Mock
+}
+
+// RevokeSession provides a mock function with given fields: ctx, user, sessionUUID
+func (_m *SessionRevoker) RevokeSession(ctx context.Context, user *entity.User, sessionUUID uuid.UUID) error {
+	ret := _m.Called(ctx, user, sessionUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, uuid.UUID) error); ok {
+		r0 = rf(ctx, user, sessionUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewSessionRevoker interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSessionRevoker creates a new instance of SessionRevoker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSessionRevoker(t mockConstructorTestingTNewSessionRevoker) *SessionRevoker {
+	mock := &SessionRevoker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
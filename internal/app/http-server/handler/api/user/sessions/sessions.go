@@ -0,0 +1,177 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// SessionLister is an interface for listing the active sessions of the user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SessionLister
+type SessionLister interface {
+	ListSessions(ctx context.Context, user *entity.User) ([]entity.Session, error)
+}
+
+// SessionRevoker is an interface for revoking a session of the user.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SessionRevoker
+type SessionRevoker interface {
+	RevokeSession(ctx context.Context, user *entity.User, sessionUUID uuid.UUID) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// Response is a session response.
+type Response struct {
+	ID         string `json:"id" example:"5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"`
+	CreatedAt  string `json:"created_at" example:"2020-12-10T15:15:45+03:00"`
+	LastUsedAt string `json:"last_used_at" example:"2020-12-10T15:15:45+03:00"`
+	ExpiresAt  string `json:"expires_at" example:"2020-12-11T15:15:45+03:00"`
+	IP         string `json:"ip" example:"192.0.2.1"`
+	UserAgent  string `json:"user_agent" example:"Mozilla/5.0"`
+	Current    bool   `json:"current" example:"true"`
+}
+
+// NewLister returned func for listing the active sessions of the user.
+//
+//	@Tags			User
+//	@Summary		Получение списка активных сессий.
+//	@Description	Эндпоинт возвращает активные сессии пользователя: время создания и последнего использования, IP и User-Agent.
+//	@Description	Сессия, которой выполнен запрос, отмечена полем current.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/sessions [get]
+//	@Param			Authorization	header		string				true	"JWT Token"
+//	@Success		200				{object}	[]sessions.Response	"Successfully fetched sessions"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage sessions"
+//	@Failure		500				"Internal server error"
+func NewLister(log *logger.Logger, lister SessionLister, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.sessions.NewLister"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		sessions, err := lister.ListSessions(ctx, user)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		result := make([]Response, 0, len(sessions))
+		for _, s := range sessions {
+			result = append(result, Response{
+				ID:         s.UUID.String(),
+				CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+				LastUsedAt: s.LastUsedAt.Format(time.RFC3339),
+				ExpiresAt:  s.ExpiresAt.Format(time.RFC3339),
+				IP:         s.IP,
+				UserAgent:  s.UserAgent,
+				Current:    s.UUID == user.SessionUUID,
+			})
+		}
+
+		render.JSON(w, r, result)
+		logWith.Info("Sessions successfully retrieved")
+	}
+}
+
+// NewRevoker returned func for revoking a session of the user.
+//
+//	@Tags			User
+//	@Summary		Завершение сессии.
+//	@Description	Эндпоинт завершает одну сессию пользователя, её JWT перестаёт приниматься сразу.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		plain
+//	@Router			/user/sessions/{id} [delete]
+//	@Param			Authorization	header	string	true	"JWT Token"
+//	@Param			id				path	string	true	"Session ID"
+//	@Success		204				"Session revoked"
+//	@Failure		400				"Invalid session ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage sessions"
+//	@Failure		404				"Session not found"
+//	@Failure		500				"Internal server error"
+func NewRevoker(log *logger.Logger, revoker SessionRevoker, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.sessions.NewRevoker"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		sessionUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			logWith.Info("Invalid session ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = revoker.RevokeSession(ctx, user, sessionUUID)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserSessionNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Session revoked", log.StringField("session_uuid", sessionUUID.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
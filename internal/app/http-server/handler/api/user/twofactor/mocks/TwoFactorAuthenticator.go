@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TwoFactorAuthenticator is an autogenerated mock type for the TwoFactorAuthenticator type
+type TwoFactorAuthenticator struct {
+	mock.Mock
+}
+
+// AuthenticateTwoFactor provides a mock function with given fields: ctx, challenge, code
+func (_m *TwoFactorAuthenticator) AuthenticateTwoFactor(ctx context.Context, challenge string, code string) (string, error) {
+	ret := _m.Called(ctx, challenge, code)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return rf(ctx, challenge, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, challenge, code)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, challenge, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTwoFactorAuthenticator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTwoFactorAuthenticator creates a new instance of TwoFactorAuthenticator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTwoFactorAuthenticator(t mockConstructorTestingTNewTwoFactorAuthenticator) *TwoFactorAuthenticator {
+	mock := &TwoFactorAuthenticator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
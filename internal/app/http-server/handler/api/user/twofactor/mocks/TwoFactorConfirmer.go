@@ -0,0 +1,56 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TwoFactorConfirmer is an autogenerated mock type for the TwoFactorConfirmer type
+type TwoFactorConfirmer struct {
+	mock.Mock
+}
+
+// ConfirmTwoFactor provides a mock function with given fields: ctx, user, code
+func (_m *TwoFactorConfirmer) ConfirmTwoFactor(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	ret := _m.Called(ctx, user, code)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string) ([]string, error)); ok {
+		return rf(ctx, user, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string) []string); ok {
+		r0 = rf(ctx, user, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User, string) error); ok {
+		r1 = rf(ctx, user, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTwoFactorConfirmer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTwoFactorConfirmer creates a new instance of TwoFactorConfirmer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTwoFactorConfirmer(t mockConstructorTestingTNewTwoFactorConfirmer) *TwoFactorConfirmer {
+	mock := &TwoFactorConfirmer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TwoFactorDisabler is an autogenerated mock type for the TwoFactorDisabler type
+type TwoFactorDisabler struct {
+	mock.Mock
+}
+
+// DisableTwoFactor provides a mock function with given fields: ctx, user, code
+func (_m *TwoFactorDisabler) DisableTwoFactor(ctx context.Context, user *entity.User, code string) error {
+	ret := _m.Called(ctx, user, code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string) error); ok {
+		r0 = rf(ctx, user, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewTwoFactorDisabler interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTwoFactorDisabler creates a new instance of TwoFactorDisabler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTwoFactorDisabler(t mockConstructorTestingTNewTwoFactorDisabler) *TwoFactorDisabler {
+	mock := &TwoFactorDisabler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
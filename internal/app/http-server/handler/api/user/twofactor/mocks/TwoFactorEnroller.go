@@ -0,0 +1,56 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TwoFactorEnroller is an autogenerated mock type for the TwoFactorEnroller type
+type TwoFactorEnroller struct {
+	mock.Mock
+}
+
+// EnrollTwoFactor provides a mock function with given fields: ctx, user
+func (_m *TwoFactorEnroller) EnrollTwoFactor(ctx context.Context, user *entity.User) (*entity.TwoFactorEnrollment, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 *entity.TwoFactorEnrollment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) (*entity.TwoFactorEnrollment, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) *entity.TwoFactorEnrollment); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.TwoFactorEnrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTwoFactorEnroller interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTwoFactorEnroller creates a new instance of TwoFactorEnroller. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTwoFactorEnroller(t mockConstructorTestingTNewTwoFactorEnroller) *TwoFactorEnroller {
+	mock := &TwoFactorEnroller{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
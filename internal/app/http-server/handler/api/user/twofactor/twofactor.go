@@ -0,0 +1,340 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// TwoFactorAuthenticator is an interface for the second step of two-factor login.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=TwoFactorAuthenticator
+type TwoFactorAuthenticator interface {
+	AuthenticateTwoFactor(ctx context.Context, challenge, code string) (string, error)
+}
+
+// TwoFactorEnroller is an interface for starting two-factor enrollment.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=TwoFactorEnroller
+type TwoFactorEnroller interface {
+	EnrollTwoFactor(ctx context.Context, user *entity.User) (*entity.TwoFactorEnrollment, error)
+}
+
+// TwoFactorConfirmer is an interface for enabling two-factor authentication.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=TwoFactorConfirmer
+type TwoFactorConfirmer interface {
+	ConfirmTwoFactor(ctx context.Context, user *entity.User, code string) ([]string, error)
+}
+
+// TwoFactorDisabler is an interface for disabling two-factor authentication.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=TwoFactorDisabler
+type TwoFactorDisabler interface {
+	DisableTwoFactor(ctx context.Context, user *entity.User, code string) error
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// LoginRequest struct for HTTP second factor login Request in JSON
+type LoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"`
+	Code           string `json:"code" validate:"required" example:"123456"`
+}
+
+// CodeRequest struct for HTTP Request with a TOTP or recovery code in JSON
+type CodeRequest struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// EnrollResponse is a response with a pending TOTP secret.
+type EnrollResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI    string `json:"uri" example:"otpauth://totp/Gophermart:test@test.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Gophermart"`
+}
+
+// ConfirmResponse is a response with one-time recovery codes.
+type ConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcde-fghij,klmno-pqrst"`
+}
+
+// NewLogin returned func for the second step of two-factor login.
+//
+//	@Tags			User
+//	@Summary		Второй шаг двухфакторной аутентификации.
+//	@Description	Эндпоинт принимает токен подтверждения из /user/login и TOTP или резервный код.
+//	@Description	В заголовке Authorization возвращается JWT токен для авторизации
+//	@Description	Каждый TOTP код принимается один раз, после 5 неверных попыток за 15 минут вход блокируется.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/user/login/2fa [post]
+//	@Param			Request	body	twofactor.LoginRequest	true	"Second Factor Request"
+//	@Success		200		"User successfully authenticated"
+//	@Failure		400		"Bad request"
+//	@Failure		401		"Challenge token or code is invalid"
+//	@Failure		429		"Too many attempts, try again later"
+//	@Failure		500		"Internal server error"
+//	@Header			200		{string}	Authorization	"JWT Token"
+func NewLogin(log *logger.Logger, authenticator TwoFactorAuthenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.twofactor.NewLogin"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		var req LoginRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		jwtString, err := authenticator.AuthenticateTwoFactor(ctx, req.ChallengeToken, req.Code)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserTwoFactorChallengeInvalid) || errors.Is(err, entity.ErrUserTwoFactorCodeInvalid) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if errors.Is(err, entity.ErrUserTwoFactorLocked) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Authorization", jwtString)
+		logWith.Info("User Authenticated with second factor")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewEnroller returned func for starting two-factor enrollment.
+//
+//	@Tags			User
+//	@Summary		Подключение двухфакторной аутентификации.
+//	@Description	Эндпоинт создаёт новый TOTP секрет и otpauth URI для приложения-аутентификатора.
+//	@Description	Двухфакторная аутентификация включается только после подтверждения кодом.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/2fa/enroll [post]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Success		200				{object}	twofactor.EnrollResponse	"Pending TOTP secret"
+//	@Failure		401				"User is not authorized"
+//	@Failure		409				"Two-factor authentication is already enabled"
+//	@Failure		500				"Internal server error"
+func NewEnroller(log *logger.Logger, enroller TwoFactorEnroller, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.twofactor.NewEnroller"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		enrollment, err := enroller.EnrollTwoFactor(ctx, user)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserTwoFactorAlreadyEnabled) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, EnrollResponse{
+			Secret: enrollment.Secret,
+			URI:    enrollment.URI,
+		})
+		logWith.Info("Two-factor enrollment started")
+	}
+}
+
+// NewConfirmer returned func for enabling two-factor authentication.
+//
+//	@Tags			User
+//	@Summary		Подтверждение двухфакторной аутентификации.
+//	@Description	Эндпоинт включает двухфакторную аутентификацию по первому TOTP коду и возвращает резервные коды.
+//	@Description	Резервные коды показываются один раз, каждый код можно использовать один раз.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/user/2fa/confirm [post]
+//	@Param			Authorization	header		string						true	"JWT Token"
+//	@Param			Request			body		twofactor.CodeRequest		true	"TOTP Code"
+//	@Success		200				{object}	twofactor.ConfirmResponse	"Two-factor authentication enabled"
+//	@Failure		400				"Bad request or enrollment was not started"
+//	@Failure		401				"User is not authorized"
+//	@Failure		409				"Two-factor authentication is already enabled"
+//	@Failure		422				"TOTP code is invalid"
+//	@Failure		500				"Internal server error"
+func NewConfirmer(log *logger.Logger, confirmer TwoFactorConfirmer, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.twofactor.NewConfirmer"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req CodeRequest
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		codes, err := confirmer.ConfirmTwoFactor(ctx, user, req.Code)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserTwoFactorAlreadyEnabled) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if errors.Is(err, entity.ErrUserTwoFactorNotEnrolled) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, entity.ErrUserTwoFactorCodeInvalid) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, ConfirmResponse{RecoveryCodes: codes})
+		logWith.Info("Two-factor authentication enabled")
+	}
+}
+
+// NewDisabler returned func for disabling two-factor authentication.
+//
+//	@Tags			User
+//	@Summary		Отключение двухфакторной аутентификации.
+//	@Description	Эндпоинт отключает двухфакторную аутентификацию по TOTP или резервному коду.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		plain
+//	@Router			/user/2fa [delete]
+//	@Param			Authorization	header	string					true	"JWT Token"
+//	@Param			Request			body	twofactor.CodeRequest	true	"TOTP or Recovery Code"
+//	@Success		200				"Two-factor authentication disabled"
+//	@Failure		400				"Bad request or two-factor authentication is not enabled"
+//	@Failure		401				"User is not authorized"
+//	@Failure		422				"Code is invalid"
+//	@Failure		429				"Too many attempts, try again later"
+//	@Failure		500				"Internal server error"
+func NewDisabler(log *logger.Logger, disabler TwoFactorDisabler, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.twofactor.NewDisabler"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req CodeRequest
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = disabler.DisableTwoFactor(ctx, user, req.Code)
+		if err != nil {
+			if errors.Is(err, entity.ErrUserTwoFactorNotEnrolled) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, entity.ErrUserTwoFactorLocked) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			if errors.Is(err, entity.ErrUserTwoFactorCodeInvalid) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Two-factor authentication disabled")
+		w.WriteHeader(http.StatusOK)
+	}
+}
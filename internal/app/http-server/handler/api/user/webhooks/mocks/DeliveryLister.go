@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// DeliveryLister is an autogenerated mock type for the DeliveryLister type
+type DeliveryLister struct {
+	mock.Mock
+}
+
+// Deliveries provides a mock function with given fields: ctx, userUUID, webhookUUID
+func (_m *DeliveryLister) Deliveries(ctx context.Context, userUUID uuid.UUID, webhookUUID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, userUUID, webhookUUID)
+
+	var r0 []entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]entity.WebhookDelivery, error)); ok {
+		return rf(ctx, userUUID, webhookUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, userUUID, webhookUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID, webhookUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDeliveryLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDeliveryLister creates a new instance of DeliveryLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDeliveryLister(t mockConstructorTestingTNewDeliveryLister) *DeliveryLister {
+	mock := &DeliveryLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// WebhookCreator is an autogenerated mock type for the WebhookCreator type
+type WebhookCreator struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, userUUID, rawURL, eventTypes
+func (_m *WebhookCreator) Create(ctx context.Context, userUUID uuid.UUID, rawURL string, eventTypes []entity.EventType) (*entity.Webhook, error) {
+	ret := _m.Called(ctx, userUUID, rawURL, eventTypes)
+
+	var r0 *entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []entity.EventType) (*entity.Webhook, error)); ok {
+		return rf(ctx, userUUID, rawURL, eventTypes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []entity.EventType) *entity.Webhook); ok {
+		r0 = rf(ctx, userUUID, rawURL, eventTypes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, []entity.EventType) error); ok {
+		r1 = rf(ctx, userUUID, rawURL, eventTypes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookCreator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookCreator creates a new instance of WebhookCreator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookCreator(t mockConstructorTestingTNewWebhookCreator) *WebhookCreator {
+	mock := &WebhookCreator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// WebhookLister is an autogenerated mock type for the WebhookLister type
+type WebhookLister struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields: ctx, userUUID
+func (_m *WebhookLister) List(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Webhook, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Webhook); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookLister creates a new instance of WebhookLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookLister(t mockConstructorTestingTNewWebhookLister) *WebhookLister {
+	mock := &WebhookLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
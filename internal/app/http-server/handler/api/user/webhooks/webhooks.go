@@ -0,0 +1,393 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// WebhookCreator is an interface for creating webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookCreator
+type WebhookCreator interface {
+	Create(ctx context.Context, userUUID uuid.UUID, rawURL string, eventTypes []entity.EventType) (*entity.Webhook, error)
+}
+
+// WebhookLister is an interface for listing webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookLister
+type WebhookLister interface {
+	List(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error)
+}
+
+// WebhookDeleter is an interface for deleting webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookDeleter
+type WebhookDeleter interface {
+	Delete(ctx context.Context, userUUID, webhookUUID uuid.UUID) error
+}
+
+// DeliveryLister is an interface for listing the deliveries of a webhook.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DeliveryLister
+type DeliveryLister interface {
+	Deliveries(ctx context.Context, userUUID, webhookUUID uuid.UUID) ([]entity.WebhookDelivery, error)
+}
+
+// UserAuthorizer is an interface for authorizing users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=UserAuthorizer
+type UserAuthorizer interface {
+	Authorize(ctx context.Context, token string) (*entity.User, error)
+}
+
+// Request struct for HTTP webhook creation Request in JSON
+type Request struct {
+	URL    string   `json:"url" validate:"required,url" example:"https://partner.example/hooks/gophermart"`
+	Events []string `json:"events" validate:"required,min=1" example:"order_status,balance"`
+}
+
+// Response is a webhook response.
+type Response struct {
+	ID        string   `json:"id" example:"5b0c3f52-8f6a-4e2e-9d7c-3f2b1a6c9e10"`
+	URL       string   `json:"url" example:"https://partner.example/hooks/gophermart"`
+	Events    []string `json:"events" example:"order_status,balance"`
+	CreatedAt string   `json:"created_at" example:"2020-12-10T15:15:45+03:00"`
+}
+
+// CreateResponse is a response with a new webhook and its signing secret.
+type CreateResponse struct {
+	Response
+	Secret string `json:"secret" example:"mJ6v0i3C0R2c5Yw1xk1m2X9hD0pLwq8aVfZcTg7yU4s"`
+}
+
+// DeliveryResponse is a webhook delivery response.
+type DeliveryResponse struct {
+	ID            string `json:"id" example:"0f6f2c1e-3d4b-4c5a-9e8f-7a6b5c4d3e2f"`
+	Event         string `json:"event" example:"order_status" enums:"order_status,balance"`
+	Status        string `json:"status" example:"DELIVERED" enums:"PENDING,DELIVERED,FAILED"`
+	Attempts      int    `json:"attempts" example:"1"`
+	ResponseCode  int    `json:"response_code,omitempty" example:"200"`
+	LastError     string `json:"last_error,omitempty" example:"unexpected response status 500"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty" example:"2020-12-10T15:16:45+03:00"`
+	CreatedAt     string `json:"created_at" example:"2020-12-10T15:15:45+03:00"`
+	DeliveredAt   string `json:"delivered_at,omitempty" example:"2020-12-10T15:15:46+03:00"`
+}
+
+// NewCreator returned func for creating a webhook.
+//
+//	@Tags			Webhook
+//	@Summary		Создание вебхука.
+//	@Description	Эндпоинт подписывает URL партнёра на события пользователя: order_status и balance.
+//	@Description	Каждая доставка отправляется POST запросом с заголовками X-Gophermart-Event, X-Gophermart-Delivery и X-Gophermart-Timestamp.
+//	@Description	Заголовок X-Gophermart-Signature содержит sha256=HMAC-SHA256 секрета от строки "<timestamp>.<тело запроса>" в hex.
+//	@Description	Неуспешные доставки повторяются с экспоненциальной задержкой. Секрет показывается один раз.
+//	@Description	Адреса loopback, частных и link-local сетей не принимаются, перенаправления не выполняются.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			json
+//	@Produce		json
+//	@Router			/user/webhooks [post]
+//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Request			body		webhooks.Request		true	"Webhook Request"
+//	@Success		201				{object}	webhooks.CreateResponse	"Webhook created"
+//	@Failure		400				"Bad request, invalid or internal URL or unknown event"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage webhooks"
+//	@Failure		500				"Internal server error"
+func NewCreator(log *logger.Logger, creator WebhookCreator, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.webhooks.NewCreator"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req Request
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			logWith.Info("Failed to decode request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			logWith.Info("Failed to validate request body", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		eventTypes := make([]entity.EventType, 0, len(req.Events))
+		for _, event := range req.Events {
+			eventTypes = append(eventTypes, entity.EventType(event))
+		}
+
+		webhook, err := creator.Create(ctx, user.UUID, req.URL, eventTypes)
+		if err != nil {
+			if errors.Is(err, entity.ErrWebhookURLInvalid) || errors.Is(err, entity.ErrWebhookURLForbidden) ||
+				errors.Is(err, entity.ErrWebhookEventTypeInvalid) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateResponse{Response: newResponse(webhook), Secret: webhook.Secret})
+		logWith.Info("Webhook created", log.StringField("webhook_uuid", webhook.UUID.String()))
+	}
+}
+
+// NewLister returned func for listing the webhooks of the user.
+//
+//	@Tags			Webhook
+//	@Summary		Получение списка вебхуков.
+//	@Description	Эндпоинт возвращает вебхуки пользователя без секретов.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/webhooks [get]
+//	@Param			Authorization	header		string				true	"JWT Token"
+//	@Success		200				{object}	[]webhooks.Response	"Successfully fetched webhooks"
+//	@Success		204				"No content"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage webhooks"
+//	@Failure		500				"Internal server error"
+func NewLister(log *logger.Logger, lister WebhookLister, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.webhooks.NewLister"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		webhooks, err := lister.List(ctx, user.UUID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(webhooks) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		result := make([]Response, 0, len(webhooks))
+		for i := range webhooks {
+			result = append(result, newResponse(&webhooks[i]))
+		}
+
+		render.JSON(w, r, result)
+		logWith.Info("Webhooks successfully retrieved")
+	}
+}
+
+// NewDeleter returned func for deleting a webhook.
+//
+//	@Tags			Webhook
+//	@Summary		Удаление вебхука.
+//	@Description	Эндпоинт удаляет вебхук пользователя вместе с журналом доставок, недоставленные события отбрасываются.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		plain
+//	@Router			/user/webhooks/{id} [delete]
+//	@Param			Authorization	header	string	true	"JWT Token"
+//	@Param			id				path	string	true	"Webhook ID"
+//	@Success		204				"Webhook deleted"
+//	@Failure		400				"Invalid webhook ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage webhooks"
+//	@Failure		404				"Webhook not found"
+//	@Failure		500				"Internal server error"
+func NewDeleter(log *logger.Logger, deleter WebhookDeleter, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.webhooks.NewDeleter"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		webhookUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			logWith.Info("Invalid webhook ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = deleter.Delete(ctx, user.UUID, webhookUUID)
+		if err != nil {
+			if errors.Is(err, entity.ErrWebhookNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logWith.Info("Webhook deleted", log.StringField("webhook_uuid", webhookUUID.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// NewDeliveryLister returned func for listing the delivery log of a webhook.
+//
+//	@Tags			Webhook
+//	@Summary		Журнал доставок вебхука.
+//	@Description	Эндпоинт возвращает последние доставки вебхука: статус, число попыток, код ответа и последнюю ошибку.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Accept			plain
+//	@Produce		json
+//	@Router			/user/webhooks/{id}/deliveries [get]
+//	@Param			Authorization	header		string						true	"JWT Token"
+//	@Param			id				path		string						true	"Webhook ID"
+//	@Success		200				{object}	[]webhooks.DeliveryResponse	"Successfully fetched deliveries"
+//	@Success		204				"No content"
+//	@Failure		400				"Invalid webhook ID"
+//	@Failure		401				"User is not authorized"
+//	@Failure		403				"API keys cannot manage webhooks"
+//	@Failure		404				"Webhook not found"
+//	@Failure		500				"Internal server error"
+func NewDeliveryLister(log *logger.Logger, lister DeliveryLister, authorizer UserAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "app.http-server.handler.api.user.webhooks.NewDeliveryLister"
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		reqID := middleware.GetReqID(ctx)
+		ctx = context.WithValue(ctx, contexter.RequestID, reqID)
+		logWith := log.With(
+			log.StringField("op", op),
+			log.StringField("request_id", reqID),
+		)
+
+		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
+		if err != nil {
+			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		webhookUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			logWith.Info("Invalid webhook ID", log.ErrorField(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := lister.Deliveries(ctx, user.UUID, webhookUUID)
+		if err != nil {
+			if errors.Is(err, entity.ErrWebhookNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(deliveries) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		result := make([]DeliveryResponse, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			item := DeliveryResponse{
+				ID:           delivery.UUID.String(),
+				Event:        string(delivery.EventType),
+				Status:       string(delivery.Status),
+				Attempts:     delivery.Attempts,
+				ResponseCode: delivery.ResponseCode,
+				LastError:    delivery.LastError,
+				CreatedAt:    delivery.CreatedAt.Format(time.RFC3339),
+			}
+			if delivery.Status == entity.WebhookDeliveryPending {
+				item.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+			}
+			if delivery.DeliveredAt != nil {
+				item.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+			}
+			result = append(result, item)
+		}
+
+		render.JSON(w, r, result)
+		logWith.Info("Webhook deliveries successfully retrieved")
+	}
+}
+
+// newResponse returns the webhook response without the secret.
+func newResponse(webhook *entity.Webhook) Response {
+	events := make([]string, 0, len(webhook.EventTypes))
+	for _, eventType := range webhook.EventTypes {
+		events = append(events, string(eventType))
+	}
+	return Response{
+		ID:        webhook.UUID.String(),
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -3,7 +3,6 @@ package withdrawals
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -35,14 +34,15 @@ type UserAuthorizer interface {
 //	@Tags			Balance
 //	@Summary		Получение списка операций снятия баланса.
 //	@Description	Эндпоинт используется для получения списка операций снятия баланса пользователя
-//	@Description	В заголовке Authorization необходимо передавать JWT токен.
+//	@Description	В заголовке Authorization необходимо передавать JWT токен или API ключ с правом balance:read.
 //	@Produce		json
 //	@Accept			plain
 //	@Router			/api/user/withdrawals [get]
-//	@Param			Authorization	header		string					true	"JWT Token"
+//	@Param			Authorization	header		string					true	"JWT Token or API key"
 //	@Success		200				{object}	[]withdrawals.Response	"User balance successfully returned"
 //	@Success		204				"No withdrawal operations found"
 //	@Failure		401				"User is not authorized"
+//	@Failure		403				"API key has no required scope"
 //	@Failure		500				"Internal server error"
 func New(log *logger.Logger, getter BalanceWithdrawOperationGetter, authorizer UserAuthorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -61,6 +61,10 @@ func New(log *logger.Logger, getter BalanceWithdrawOperationGetter, authorizer U
 		user, err := authorizer.Authorize(ctx, r.Header.Get("Authorization"))
 		if err != nil {
 			logWith.Info("Failed to authorize request", log.ErrorField(err))
+			if errors.Is(err, entity.ErrAccessForbidden) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -78,7 +82,7 @@ func New(log *logger.Logger, getter BalanceWithdrawOperationGetter, authorizer U
 		result := make([]Response, 0, len(balanceOperations))
 		for _, t := range balanceOperations {
 			operation := Response{
-				OrderNumber: fmt.Sprintf("%d", t.OrderNumber),
+				OrderNumber: string(t.OrderNumber),
 				Withdrawal:  t.Withdrawal,
 				ProcessedAt: t.ProcessedAt.Format(time.RFC3339),
 			}
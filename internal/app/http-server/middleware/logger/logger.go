@@ -1,11 +1,14 @@
 package logger
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
 
@@ -38,7 +41,15 @@ func New(log *logger.Logger) func(next http.Handler) http.Handler {
 				)
 			}()
 
-			next.ServeHTTP(ww, r)
+			// the client is remembered for the session records
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			ctx := context.WithValue(r.Context(), contexter.ClientIP, ip)
+			ctx = context.WithValue(ctx, contexter.UserAgent, r.UserAgent())
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
 		}
 
 		return http.HandlerFunc(fn)
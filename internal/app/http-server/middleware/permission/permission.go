@@ -0,0 +1,20 @@
+package permission
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+)
+
+// New returns a middleware that restricts the routes behind it to the roles with the permission.
+// The permission is checked when the request is authorized.
+func New(permission string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), contexter.RequiredPermission, permission)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,20 @@
+package scope
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+)
+
+// New returns a middleware that lets API keys with the scope use the routes behind it.
+// Routes without the middleware are available to JWT sessions only.
+func New(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), contexter.RequiredScope, scope)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
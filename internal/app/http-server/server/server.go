@@ -5,26 +5,46 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/jackc/pgx/v5/pgxpool"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/mbiwapa/gophermart.git/config"
 	"github.com/mbiwapa/gophermart.git/docs"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/accrual"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/metrics"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/rewards"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/admin/users"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/account"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/apikeys"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/balance"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/balance/withdraw"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/events"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/login"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/orders"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/password"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/password/reset"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/register"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/sessions"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/twofactor"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/webhooks"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/handler/api/user/withdrawals"
 	"github.com/mbiwapa/gophermart.git/internal/app/http-server/middleware/decompressor"
 	mwLogger "github.com/mbiwapa/gophermart.git/internal/app/http-server/middleware/logger"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/middleware/permission"
+	"github.com/mbiwapa/gophermart.git/internal/app/http-server/middleware/scope"
+	"github.com/mbiwapa/gophermart.git/internal/app/storage"
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	"github.com/mbiwapa/gophermart.git/internal/domain/service"
-	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+	broker "github.com/mbiwapa/gophermart.git/internal/infrastructure/events"
+	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/notifier"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
 
@@ -35,14 +55,22 @@ type HTTPServer struct {
 	userService    *service.UserService
 	orderService   *service.OrderService
 	balanceService *service.BalanceService
+	accountService *service.AccountService
+	webhookService *service.WebhookService
+	accrualEngine  *service.AccrualEngine
 	ctx            context.Context
 	config         *config.Config
 	orderQueue     chan entity.Order
-	db             *pgxpool.Pool
+	repositories   *storage.Repositories
+	broker         *broker.Broker
+	accrualMetrics *httpc.Metrics
 }
 
+// eventsHeartbeat is the interval of the SSE comments and WebSocket pings keeping an idle connection open.
+const eventsHeartbeat = 15 * time.Second
+
 // New returns a new HTTPServer.
-func New(ctx context.Context, config *config.Config, logger *logger.Logger, orderQueue chan entity.Order, db *pgxpool.Pool) (*HTTPServer, error) {
+func New(ctx context.Context, config *config.Config, logger *logger.Logger, orderQueue chan entity.Order, repositories *storage.Repositories, eventBroker *broker.Broker, accrualMetrics *httpc.Metrics) (*HTTPServer, error) {
 
 	server := &HTTPServer{
 		server: &http.Server{
@@ -51,11 +79,13 @@ func New(ctx context.Context, config *config.Config, logger *logger.Logger, orde
 				return ctx
 			},
 		},
-		logger:     logger,
-		ctx:        ctx,
-		config:     config,
-		orderQueue: orderQueue,
-		db:         db,
+		logger:         logger,
+		ctx:            ctx,
+		config:         config,
+		orderQueue:     orderQueue,
+		repositories:   repositories,
+		broker:         eventBroker,
+		accrualMetrics: accrualMetrics,
 	}
 	return server, nil
 }
@@ -65,30 +95,55 @@ func (s *HTTPServer) Run() {
 	const op = "internal.app.http-server.server.Run"
 	log := s.logger.With(s.logger.StringField("op", op))
 	go func() {
-		userRepository := postgre.NewUserRepository(s.db, s.logger)
-		err := userRepository.Migrate(s.ctx)
-		if err != nil {
-			log.Error("Failed to migrate user repository", log.ErrorField(err))
-			os.Exit(1)
+		if s.config.AccrualEngine == config.AccrualEngineBuiltin {
+			s.accrualEngine = service.NewAccrualEngine(s.logger, s.repositories.Accrual)
 		}
-		orderRepository := postgre.NewOrderRepository(s.db, s.logger)
-		err = orderRepository.Migrate(s.ctx)
+
+		passwordPolicy, err := tool.NewPasswordPolicy(
+			s.config.PasswordMinLength,
+			s.config.PasswordMinClasses,
+			s.config.PasswordCommonListFile,
+		)
 		if err != nil {
-			log.Error("Failed to migrate order repository", log.ErrorField(err))
+			log.Error("Failed to load password policy", log.ErrorField(err))
 			os.Exit(1)
 		}
-		balanceRepository := postgre.NewBalanceRepository(s.db, s.logger)
-		err = balanceRepository.Migrate(s.ctx)
+
+		resetNotifier, err := notifier.NewFileNotifier(s.config.PasswordResetFile, s.logger)
 		if err != nil {
-			log.Error("Failed to migrate balance repository", log.ErrorField(err))
+			log.Error("Failed to create password reset notifier", log.ErrorField(err))
 			os.Exit(1)
 		}
 
-		s.balanceService = service.NewBalanceService(s.logger, balanceRepository)
+		s.webhookService = service.NewWebhookService(s.logger, s.repositories.Webhooks)
+		publisher := service.MultiPublisher{s.broker, s.webhookService}
+
+		s.balanceService = service.NewBalanceService(s.logger, s.repositories.Balances)
+		s.balanceService.SetPublisher(publisher)
+
+		s.userService = service.NewUserService(s.repositories.Users, s.balanceService, passwordPolicy, s.logger, s.config.SecretKey)
+		s.userService.SetPasswordResetNotifier(resetNotifier, s.config.PasswordResetTTL)
+		s.userService.SetTransactor(s.repositories.Transactor)
+
+		if s.config.AdminLogin != "" {
+			err = s.userService.BootstrapAdmin(
+				context.WithValue(s.ctx, contexter.RequestID, "bootstrap"),
+				strings.ToLower(s.config.AdminLogin),
+				s.config.AdminPassword,
+			)
+			if err != nil {
+				log.Error("Failed to bootstrap admin", log.ErrorField(err))
+				os.Exit(1)
+			}
+		}
 
-		s.userService = service.NewUserService(userRepository, s.balanceService, s.logger, s.config.SecretKey)
+		s.orderService = service.NewOrderService(s.logger, s.orderQueue, s.repositories.Orders)
+		s.orderService.SetPublisher(publisher)
+		s.orderService.SetAccruer(s.balanceService)
+		s.orderService.SetTransactor(s.repositories.Transactor)
 
-		s.orderService = service.NewOrderService(s.logger, s.orderQueue, orderRepository)
+		s.accountService = service.NewAccountService(s.logger, s.userService, s.orderService, s.balanceService)
+		s.accountService.SetTransactor(s.repositories.Transactor)
 
 		s.server.Handler = s.newRouter()
 
@@ -132,14 +187,66 @@ func (s *HTTPServer) newRouter() http.Handler {
 
 	r.Post("/api/user/register", register.New(s.logger, s.userService))
 	r.Post("/api/user/login", login.New(s.logger, s.userService))
+	r.Post("/api/user/login/2fa", twofactor.NewLogin(s.logger, s.userService))
+	r.Post("/api/user/password/reset-request", reset.NewRequester(s.logger, s.userService))
+	r.Post("/api/user/password/reset", reset.NewResetter(s.logger, s.userService))
 
 	//Only for authenticated users
 	r.Group(func(r chi.Router) {
-		r.Post("/api/user/orders", orders.NewAdder(s.logger, s.orderService, s.userService))
-		r.Get("/api/user/orders", orders.NewAllGetter(s.logger, s.orderService, s.userService))
-		r.Get("/api/user/balance", balance.New(s.logger, s.balanceService, s.userService))
-		r.Post("/api/user/balance/withdraw", withdraw.New(s.logger, s.balanceService, s.userService))
-		r.Get("/api/user/withdrawals", withdrawals.New(s.logger, s.balanceService, s.userService))
+		//API keys are accepted only on the routes with a required scope
+		r.With(scope.New(entity.ScopeOrdersWrite)).Post("/api/user/orders", orders.NewAdder(s.logger, s.orderService, s.userService))
+		r.With(scope.New(entity.ScopeOrdersWrite)).Post("/api/user/orders/batch", orders.NewBatchAdder(s.logger, s.orderService, s.userService))
+		r.With(scope.New(entity.ScopeOrdersRead)).Get("/api/user/orders", orders.NewAllGetter(s.logger, s.orderService, s.userService))
+		r.With(scope.New(entity.ScopeOrdersRead)).Get("/api/user/orders/{number}", orders.NewGetter(s.logger, s.orderService, s.userService))
+		r.With(scope.New(entity.ScopeOrdersWrite)).Delete("/api/user/orders/{number}", orders.NewRetractor(s.logger, s.orderService, s.userService))
+		r.With(scope.New(entity.ScopeBalanceRead)).Get("/api/user/balance", balance.New(s.logger, s.balanceService, s.userService))
+		r.With(scope.New(entity.ScopeBalanceWrite)).Post("/api/user/balance/withdraw", withdraw.New(s.logger, s.balanceService, s.userService))
+		r.With(scope.New(entity.ScopeBalanceRead)).Get("/api/user/withdrawals", withdrawals.New(s.logger, s.balanceService, s.userService))
+		r.Get("/api/user/events", events.New(s.logger, s.broker, s.userService, eventsHeartbeat))
+		r.Get("/api/user/ws", events.NewWebSocket(s.logger, s.broker, s.userService, eventsHeartbeat))
+		r.Put("/api/user/password", password.New(s.logger, s.userService, s.userService))
+		r.Post("/api/user/2fa/enroll", twofactor.NewEnroller(s.logger, s.userService, s.userService))
+		r.Post("/api/user/2fa/confirm", twofactor.NewConfirmer(s.logger, s.userService, s.userService))
+		r.Delete("/api/user/2fa", twofactor.NewDisabler(s.logger, s.userService, s.userService))
+		r.Get("/api/user/export", account.NewExporter(s.logger, s.accountService, s.userService))
+		r.Delete("/api/user", account.NewDeleter(s.logger, s.accountService, s.userService))
+		r.Get("/api/user/sessions", sessions.NewLister(s.logger, s.userService, s.userService))
+		r.Delete("/api/user/sessions/{id}", sessions.NewRevoker(s.logger, s.userService, s.userService))
+		r.Post("/api/user/api-keys", apikeys.NewCreator(s.logger, s.userService, s.userService))
+		r.Get("/api/user/api-keys", apikeys.NewLister(s.logger, s.userService, s.userService))
+		r.Delete("/api/user/api-keys/{id}", apikeys.NewRevoker(s.logger, s.userService, s.userService))
+		r.Post("/api/user/webhooks", webhooks.NewCreator(s.logger, s.webhookService, s.userService))
+		r.Get("/api/user/webhooks", webhooks.NewLister(s.logger, s.webhookService, s.userService))
+		r.Delete("/api/user/webhooks/{id}", webhooks.NewDeleter(s.logger, s.webhookService, s.userService))
+		r.Get("/api/user/webhooks/{id}/deliveries", webhooks.NewDeliveryLister(s.logger, s.webhookService, s.userService))
+	})
+
+	//Only for the accrual system, signed with the shared secret
+	if s.config.AccrualPushSecret != "" {
+		r.Post("/api/internal/accrual/orders", accrual.NewReceiver(s.logger, s.orderService, s.config.AccrualPushSecret))
+	}
+
+	//Only for support and admins
+	r.Group(func(r chi.Router) {
+		r.With(permission.New(entity.PermissionUsersRead)).Get("/api/admin/users/{userID}", users.NewGetter(s.logger, s.userService, s.userService))
+		r.With(permission.New(entity.PermissionUsersRead)).Get("/api/admin/users/{userID}/api-keys", apikeys.NewLister(s.logger, s.userService, s.userService))
+		r.With(permission.New(entity.PermissionMetricsRead)).Get("/api/admin/metrics/accrual-client", metrics.NewAccrualClientGetter(s.logger, s.accrualMetrics, s.userService))
+
+		r.Group(func(r chi.Router) {
+			r.Use(permission.New(entity.PermissionUsersWrite))
+			r.Put("/api/admin/users/{userID}/role", users.NewRoleSetter(s.logger, s.userService, s.userService))
+			r.Post("/api/admin/users/{userID}/api-keys", apikeys.NewCreator(s.logger, s.userService, s.userService))
+			r.Delete("/api/admin/users/{userID}/api-keys/{id}", apikeys.NewRevoker(s.logger, s.userService, s.userService))
+		})
+
+		//The built-in accrual engine is fed by the admins instead of the external system
+		if s.accrualEngine != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(permission.New(entity.PermissionAccrualWrite))
+				r.Post("/api/admin/accrual/goods", rewards.NewRuleAdder(s.logger, s.accrualEngine, s.userService))
+				r.Post("/api/admin/accrual/orders", rewards.NewOrderRegistrar(s.logger, s.accrualEngine, s.userService))
+			})
+		}
 	})
 
 	return r
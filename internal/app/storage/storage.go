@@ -0,0 +1,50 @@
+// Package storage builds the repositories shared by the server and the workers on the configured storage.
+package storage
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/memory"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// Repositories are the repositories of one storage.
+type Repositories struct {
+	Users            service.UserRepository
+	Orders           service.OrderRepository
+	Balances         service.BalanceRepository
+	Webhooks         service.WebhookRepository
+	Accrual          service.AccrualRepository
+	AccrualResponses httpc.ResponseAuditor
+	Transactor       service.Transactor
+}
+
+// NewPostgre returns the repositories stored in the database.
+func NewPostgre(db *pgxpool.Pool, log *logger.Logger) *Repositories {
+	return &Repositories{
+		Users:            postgre.NewUserRepository(db, log),
+		Orders:           postgre.NewOrderRepository(db, log),
+		Balances:         postgre.NewBalanceRepository(db, log),
+		Webhooks:         postgre.NewWebhookRepository(db, log),
+		Accrual:          postgre.NewAccrualRepository(db, log),
+		AccrualResponses: postgre.NewAccrualResponseRepository(db, log),
+		Transactor:       postgre.NewTxManager(db, log),
+	}
+}
+
+// NewMemory returns the repositories kept in process memory, the data is lost on exit.
+func NewMemory(log *logger.Logger) *Repositories {
+	storage := memory.NewStorage()
+	return &Repositories{
+		Users:            memory.NewUserRepository(storage, log),
+		Orders:           memory.NewOrderRepository(storage, log),
+		Balances:         memory.NewBalanceRepository(storage, log),
+		Webhooks:         memory.NewWebhookRepository(storage, log),
+		Accrual:          memory.NewAccrualRepository(storage, log),
+		AccrualResponses: memory.NewAccrualResponseRepository(storage),
+		Transactor:       memory.NewTransactor(),
+	}
+}
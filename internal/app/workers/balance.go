@@ -4,11 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
+	"github.com/mbiwapa/gophermart.git/internal/app/storage"
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	"github.com/mbiwapa/gophermart.git/internal/domain/service"
-	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
 	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
@@ -19,16 +17,18 @@ type BalanceWorker struct {
 	ctx            context.Context
 	balanceQueue   chan entity.BalanceOperation
 	balanceService *service.BalanceService
-	db             *pgxpool.Pool
+	repositories   *storage.Repositories
+	publisher      service.EventPublisher
 }
 
-func NewBalanceWorker(ctx context.Context, logger *logger.Logger, balanceQueue chan entity.BalanceOperation, errorChanel chan error, db *pgxpool.Pool) *BalanceWorker {
+func NewBalanceWorker(ctx context.Context, logger *logger.Logger, balanceQueue chan entity.BalanceOperation, errorChanel chan error, repositories *storage.Repositories, publisher service.EventPublisher) *BalanceWorker {
 	return &BalanceWorker{
 		balanceQueue: balanceQueue,
 		logger:       logger,
 		errorChan:    errorChanel,
 		ctx:          ctx,
-		db:           db,
+		repositories: repositories,
+		publisher:    publisher,
 	}
 }
 
@@ -36,8 +36,9 @@ func (w *BalanceWorker) Run() {
 	const op = "app.workers.BalanceWorker.Run"
 	log := w.logger.With(w.logger.StringField("op", op))
 
-	balanceRepository := postgre.NewBalanceRepository(w.db, w.logger)
-	w.balanceService = service.NewBalanceService(w.logger, balanceRepository)
+	w.balanceService = service.NewBalanceService(w.logger, w.repositories.Balances)
+	webhookService := service.NewWebhookService(w.logger, w.repositories.Webhooks)
+	w.balanceService.SetPublisher(service.MultiPublisher{w.publisher, webhookService})
 
 	for i := 1; i <= 3; i++ {
 		go w.worker()
@@ -59,7 +60,7 @@ func (w *BalanceWorker) worker() {
 				return
 			}
 			//TODO что-то получше сделать
-			reqID := "req_order" + fmt.Sprintf("%d", operation.OrderNumber)
+			reqID := "req_order" + string(operation.OrderNumber)
 			ctx := context.WithValue(w.ctx, contexter.RequestID, reqID)
 
 			log.Info("Update balance", log.AnyField("add", operation.Accrual))
@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
+	"github.com/mbiwapa/gophermart.git/config"
+	"github.com/mbiwapa/gophermart.git/internal/app/storage"
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	"github.com/mbiwapa/gophermart.git/internal/domain/service"
 	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
-	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
 	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
 
+// orderRetryPause is the pause before an order failed to check is queued again.
+const orderRetryPause = 10 * time.Second
+
 type OrderWorker struct {
 	orderService *service.OrderService
 	orderQueue   chan entity.Order
@@ -22,19 +25,27 @@ type OrderWorker struct {
 	errorChan    chan error
 	ctx          context.Context
 	balanceQueue chan entity.BalanceOperation
-	db           *pgxpool.Pool
-	accrualURL   string
+	repositories *storage.Repositories
+	providers    []config.AccrualProvider
+	transport    config.AccrualTransport
+	metrics      *httpc.Metrics
+	engine       string
+	publisher    service.EventPublisher
 }
 
-func NewOrderWorker(ctx context.Context, logger *logger.Logger, orderQueue chan entity.Order, errorChanel chan error, balanceQueue chan entity.BalanceOperation, db *pgxpool.Pool, accrualURL string) *OrderWorker {
+func NewOrderWorker(ctx context.Context, logger *logger.Logger, orderQueue chan entity.Order, errorChanel chan error, balanceQueue chan entity.BalanceOperation, repositories *storage.Repositories, providers []config.AccrualProvider, transport config.AccrualTransport, metrics *httpc.Metrics, engine string, publisher service.EventPublisher) *OrderWorker {
 	return &OrderWorker{
 		orderQueue:   orderQueue,
 		balanceQueue: balanceQueue,
 		logger:       logger,
 		errorChan:    errorChanel,
 		ctx:          ctx,
-		db:           db,
-		accrualURL:   accrualURL,
+		repositories: repositories,
+		providers:    providers,
+		transport:    transport,
+		metrics:      metrics,
+		engine:       engine,
+		publisher:    publisher,
 	}
 }
 
@@ -42,20 +53,99 @@ func (w *OrderWorker) Run() {
 	const op = "app.workers.OrderWorker.Run"
 	log := w.logger.With(w.logger.StringField("op", op))
 
-	client, err := httpc.NewOrderClient(w.accrualURL, w.logger)
-	if err != nil {
-		log.Error("Failed to create order client", log.ErrorField(err))
+	w.orderService = service.NewOrderService(w.logger, w.orderQueue, w.repositories.Orders)
+	switch w.engine {
+	case config.AccrualEngineBuiltin:
+		w.orderService.SetClient(service.NewAccrualEngine(w.logger, w.repositories.Accrual))
+	case config.AccrualEngineExternal:
+		providers := make([]service.AccrualProvider, 0, len(w.providers))
+		for _, provider := range w.providers {
+			client, err := httpc.NewOrderClient(providerOptions(provider, w.transport), w.metrics, w.logger)
+			if err != nil {
+				log.Error("Failed to create order client", log.StringField("provider", provider.Name), log.ErrorField(err))
+				os.Exit(1)
+			}
+			client.SetAuditor(w.repositories.AccrualResponses)
+			providers = append(providers, service.AccrualProvider{
+				Name:     provider.Name,
+				Prefixes: provider.Prefixes,
+				Stores:   provider.Stores,
+				Client:   client,
+			})
+		}
+		w.orderService.SetProviders(service.NewProviderRegistry(providers...))
+	default:
+		log.Error("Unknown accrual engine", log.StringField("engine", w.engine))
 		os.Exit(1)
 	}
-
-	orderRepository := postgre.NewOrderRepository(w.db, w.logger)
-	w.orderService = service.NewOrderService(w.logger, w.orderQueue, orderRepository)
-	w.orderService.SetClient(client)
+	webhookService := service.NewWebhookService(w.logger, w.repositories.Webhooks)
+	w.orderService.SetPublisher(service.MultiPublisher{w.publisher, webhookService})
 
 	for i := 1; i <= 3; i++ {
 		go w.worker()
 	}
 	log.Info("Start 3 order workers")
+
+	go w.requeue()
+}
+
+// requeue puts the orders left unfinished before the start back to the queue.
+func (w *OrderWorker) requeue() {
+	const op = "app.workers.order.requeue"
+	ctx := context.WithValue(w.ctx, contexter.RequestID, "req_order_requeue")
+	err := w.orderService.Requeue(ctx)
+	if err != nil {
+		w.errorChan <- fmt.Errorf("%s: %w", op, err)
+	}
+}
+
+// providerOptions maps the provider configuration to the accrual system client options,
+// the mTLS and proxy settings of the provider replace the common ones.
+func providerOptions(provider config.AccrualProvider, transport config.AccrualTransport) httpc.ProviderOptions {
+	options := httpc.ProviderOptions{
+		Name:       provider.Name,
+		URL:        provider.URL,
+		Path:       provider.Path,
+		AuthHeader: provider.AuthHeader,
+		AuthToken:  provider.AuthToken,
+		RateLimit:  provider.RateLimit,
+		Fields: httpc.ResponseFields{
+			Order:   provider.Fields.Order,
+			Status:  provider.Fields.Status,
+			Accrual: provider.Fields.Accrual,
+		},
+		Transport: httpc.TransportOptions{
+			MaxIdleConns:          transport.MaxIdleConns,
+			MaxIdleConnsPerHost:   transport.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       transport.MaxConnsPerHost,
+			IdleConnTimeout:       transport.IdleConnTimeout,
+			DialTimeout:           transport.DialTimeout,
+			TLSHandshakeTimeout:   transport.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: transport.ResponseHeaderTimeout,
+			RequestTimeout:        transport.RequestTimeout,
+			ClientCert:            transport.ClientCert,
+			ClientKey:             transport.ClientKey,
+			CACert:                transport.CACert,
+			Proxy:                 transport.Proxy,
+		},
+	}
+	if provider.ClientCert != "" || provider.ClientKey != "" {
+		options.Transport.ClientCert = provider.ClientCert
+		options.Transport.ClientKey = provider.ClientKey
+	}
+	if provider.CACert != "" {
+		options.Transport.CACert = provider.CACert
+	}
+	if provider.Proxy != "" {
+		options.Transport.Proxy = provider.Proxy
+	}
+	if provider.Statuses != nil {
+		options.Statuses = make(map[string]entity.Status, len(provider.Statuses))
+		for external, status := range provider.Statuses {
+			options.Statuses[external] = entity.Status(status)
+		}
+	}
+	return options
 }
 
 // worker is a goroutine that is responsible for processing orders.
@@ -72,23 +162,48 @@ func (w *OrderWorker) worker() {
 				return
 			}
 			//TODO что-то получше сделать
-			reqID := "req_order" + fmt.Sprintf("%d", order.Number)
+			reqID := "req_order" + string(order.Number)
 			ctx := context.WithValue(w.ctx, contexter.RequestID, reqID)
 
 			log.Info("Processing order", log.AnyField("order_number", order.Number), log)
-			bonuses, err := w.orderService.Check(ctx, order)
+			checked, err := w.orderService.Check(ctx, order)
 			if err != nil {
-				w.errorChan <- fmt.Errorf("%s: %w", op, err)
-				return
+				// an error of one order never stops the worker, the order is checked again later
+				log.Error("Failed to check order", log.AnyField("order_number", order.Number), log.ErrorField(err))
+				w.retry(order)
+				continue
 			}
 
 			log.Info("Order processed",
 				log.AnyField("order_number", order.Number),
-				log.AnyField("bonuses", bonuses),
+				log.AnyField("status", checked.Status),
+				log.AnyField("bonuses", checked.Accrual),
 			)
-			if bonuses > 0 {
-				w.balanceQueue <- entity.NewBalanceOperation(order.UserUUID, bonuses, 0, order.Number)
+			// the accrual goes to the current owner, the order may be moved to a pseudonym meanwhile
+			if checked.Status == entity.OrderProcessed && checked.Accrual > 0 {
+				w.balanceQueue <- entity.NewBalanceOperation(checked.UserUUID, checked.Accrual, 0, checked.Number)
 			}
 		}
 	}
 }
+
+// retry puts the order back to the queue after a pause. When the queue is full the order stays unfinished
+// and is queued again on the next start.
+func (w *OrderWorker) retry(order entity.Order) {
+	const op = "app.workers.order.retry"
+	log := w.logger.With(w.logger.StringField("op", op), w.logger.AnyField("order_number", order.Number))
+
+	timer := time.NewTimer(orderRetryPause)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-w.ctx.Done():
+		return
+	}
+	select {
+	case w.orderQueue <- order:
+		log.Info("Order queued again")
+	default:
+		log.Error("Order queue is full, order is left for the next start")
+	}
+}
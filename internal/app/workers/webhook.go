@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/app/storage"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+const (
+	// webhookPollInterval is the pause between the polls when there is nothing to deliver.
+	webhookPollInterval = time.Second
+	// webhookBatchSize is the number of deliveries claimed at once.
+	webhookBatchSize = 10
+)
+
+type WebhookWorker struct {
+	webhookService *service.WebhookService
+	logger         *logger.Logger
+	errorChan      chan error
+	ctx            context.Context
+	repositories   *storage.Repositories
+}
+
+func NewWebhookWorker(ctx context.Context, logger *logger.Logger, errorChanel chan error, repositories *storage.Repositories) *WebhookWorker {
+	return &WebhookWorker{
+		logger:       logger,
+		errorChan:    errorChanel,
+		ctx:          ctx,
+		repositories: repositories,
+	}
+}
+
+func (w *WebhookWorker) Run() {
+	const op = "app.workers.WebhookWorker.Run"
+	log := w.logger.With(w.logger.StringField("op", op))
+
+	w.webhookService = service.NewWebhookService(w.logger, w.repositories.Webhooks)
+	w.webhookService.SetSender(httpc.NewWebhookClient(w.logger))
+
+	for i := 1; i <= 3; i++ {
+		go w.worker(i)
+	}
+	log.Info("Start 3 webhook workers")
+}
+
+// worker is a goroutine that is responsible for delivering webhooks.
+// The deliveries are stored in the database, so nothing is lost on a restart.
+func (w *WebhookWorker) worker(id int) {
+	const op = "app.workers.webhook"
+	ctx := context.WithValue(w.ctx, contexter.RequestID, fmt.Sprintf("webhook_worker%d", id))
+	for {
+		claimed, err := w.webhookService.Deliver(ctx, webhookBatchSize)
+		if err != nil {
+			w.errorChan <- fmt.Errorf("%s: %w", op, err)
+		}
+		if err != nil || claimed < webhookBatchSize {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(webhookPollInterval):
+			}
+			continue
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// RewardType is a way a reward rule rewards the matching goods.
+type RewardType string
+
+const (
+	// RewardPercent rewards a percent of the goods price.
+	RewardPercent RewardType = "%"
+	// RewardPoints rewards a fixed number of points for the goods.
+	RewardPoints RewardType = "pt"
+)
+
+// RewardRule rewards the goods whose description contains the match string.
+type RewardRule struct {
+	Match      string
+	Reward     float64
+	RewardType RewardType
+	CreatedAt  time.Time
+}
+
+// Good is an item of an order registered in the built-in accrual engine.
+type Good struct {
+	Description string
+	Price       float64
+}
+
+// AccrualOrder is an order registered in the built-in accrual engine with its goods.
+// The accrual is calculated once, by the rules known at the registration.
+type AccrualOrder struct {
+	Number       OrderNumber
+	Goods        []Good
+	Accrual      float64
+	RegisteredAt time.Time
+}
+
+var (
+	// ErrRewardRuleInvalid is returned when a reward rule has an empty match, an unknown type or a negative reward.
+	ErrRewardRuleInvalid = errors.New("reward rule is invalid")
+	// ErrRewardRuleAlreadyExists is returned when a reward rule with the same match is already registered.
+	ErrRewardRuleAlreadyExists = errors.New("reward rule already exists")
+	// ErrAccrualOrderAlreadyRegistered is returned when an order is already registered in the accrual engine.
+	ErrAccrualOrderAlreadyRegistered = errors.New("accrual order already registered")
+	// ErrAccrualOrderNotFound is returned when an order is not registered in the accrual engine.
+	ErrAccrualOrderNotFound = errors.New("accrual order not found")
+)
+
+// NewRewardRule returns a validated reward rule.
+func NewRewardRule(match string, reward float64, rewardType RewardType) (RewardRule, error) {
+	if strings.TrimSpace(match) == "" || reward < 0 || math.IsNaN(reward) || math.IsInf(reward, 0) {
+		return RewardRule{}, ErrRewardRuleInvalid
+	}
+	if rewardType != RewardPercent && rewardType != RewardPoints {
+		return RewardRule{}, ErrRewardRuleInvalid
+	}
+	if rewardType == RewardPercent && reward > 100 {
+		return RewardRule{}, ErrRewardRuleInvalid
+	}
+	return RewardRule{
+		Match:      match,
+		Reward:     reward,
+		RewardType: rewardType,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Matches reports whether the goods description contains the match string, ignoring case.
+func (r RewardRule) Matches(description string) bool {
+	return strings.Contains(strings.ToLower(description), strings.ToLower(r.Match))
+}
+
+// RewardFor returns the reward of the goods with the price.
+func (r RewardRule) RewardFor(price float64) float64 {
+	if r.RewardType == RewardPercent {
+		return price * r.Reward / 100
+	}
+	return r.Reward
+}
+
+// CalculateAccrual returns the accrual of the goods. Every goods item is rewarded
+// by the first of the rules it matches, the goods matching no rule bring nothing.
+func CalculateAccrual(goods []Good, rules []RewardRule) float64 {
+	var accrual float64
+	for _, good := range goods {
+		for _, rule := range rules {
+			if rule.Matches(good.Description) {
+				accrual += rule.RewardFor(good.Price)
+				break
+			}
+		}
+	}
+	return math.Round(accrual*100) / 100
+}
+
+// AccrualClientMetrics are the request counters of the client of an accrual provider.
+type AccrualClientMetrics struct {
+	Provider string
+	// Requests counts the requests sent, InFlight the requests waiting for a response.
+	Requests int64
+	InFlight int64
+	// Responses counts the responses by the status code.
+	Responses map[int]int64
+	// Errors counts the requests failed without a response, Timeouts the failed by a deadline among them.
+	Errors   int64
+	Timeouts int64
+	// RateLimited counts the requests not sent because of the provider rate limit.
+	RateLimited int64
+	// Duration is the total time of the requests, MaxDuration the longest request.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// AccrualResponse is a raw response of an accrual system kept for audit.
+type AccrualResponse struct {
+	Provider    string
+	OrderNumber OrderNumber
+	StatusCode  int
+	Body        []byte
+	// Error is the reason the response was rejected, empty for an accepted response.
+	Error      string
+	ReceivedAt time.Time
+}
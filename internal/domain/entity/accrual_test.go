@@ -0,0 +1,41 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+func TestNewRewardRule(t *testing.T) {
+	_, err := entity.NewRewardRule("Bork", 10, entity.RewardPercent)
+	assert.NoError(t, err)
+	_, err = entity.NewRewardRule("Bork", 150, entity.RewardPoints)
+	assert.NoError(t, err)
+
+	_, err = entity.NewRewardRule(" ", 10, entity.RewardPercent)
+	assert.ErrorIs(t, err, entity.ErrRewardRuleInvalid)
+	_, err = entity.NewRewardRule("Bork", -1, entity.RewardPoints)
+	assert.ErrorIs(t, err, entity.ErrRewardRuleInvalid)
+	_, err = entity.NewRewardRule("Bork", 101, entity.RewardPercent)
+	assert.ErrorIs(t, err, entity.ErrRewardRuleInvalid)
+	_, err = entity.NewRewardRule("Bork", 10, entity.RewardType("x"))
+	assert.ErrorIs(t, err, entity.ErrRewardRuleInvalid)
+}
+
+func TestCalculateAccrual(t *testing.T) {
+	rules := []entity.RewardRule{
+		{Match: "Bork", Reward: 10, RewardType: entity.RewardPercent},
+		{Match: "чайник", Reward: 50, RewardType: entity.RewardPoints},
+	}
+	goods := []entity.Good{
+		// matches both rules, the first one wins
+		{Description: "Чайник Bork", Price: 7000},
+		{Description: "Чайник Tefal", Price: 3000},
+		{Description: "Кружка", Price: 500},
+	}
+
+	assert.Equal(t, 750.0, entity.CalculateAccrual(goods, rules))
+	assert.Zero(t, entity.CalculateAccrual(goods, nil))
+}
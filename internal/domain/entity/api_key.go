@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes granted to API keys.
+const (
+	ScopeOrdersRead   = "orders:read"
+	ScopeOrdersWrite  = "orders:write"
+	ScopeBalanceRead  = "balance:read"
+	ScopeBalanceWrite = "balance:write"
+)
+
+// APIKey is a long-lived credential of a machine-to-machine client acting on behalf of a user.
+// Only a hash of the key is stored.
+type APIKey struct {
+	UUID       uuid.UUID
+	UserUUID   uuid.UUID
+	Name       string
+	KeyHash    string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+var (
+	// ErrAPIKeyNotFound is returned when an API key is unknown or revoked.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyScopeInvalid is returned when an API key is requested with an unknown or empty scope list.
+	ErrAPIKeyScopeInvalid = errors.New("api key scope is invalid")
+	// ErrAccessForbidden is returned when the caller is authenticated, but is not allowed to use the route.
+	ErrAccessForbidden = errors.New("access forbidden")
+)
+
+// NewAPIKey returns a new API key for the user.
+func NewAPIKey(userUUID uuid.UUID, name, keyHash string, scopes []string) *APIKey {
+	return &APIKey{
+		UUID:      uuid.New(),
+		UserUUID:  userUUID,
+		Name:      name,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ValidScope reports whether scope is a known API key scope.
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeOrdersRead, ScopeOrdersWrite, ScopeBalanceRead, ScopeBalanceWrite:
+		return true
+	}
+	return false
+}
+
+// HasScope reports whether the key is granted the scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
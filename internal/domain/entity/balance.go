@@ -13,12 +13,23 @@ type Balance struct {
 	Withdraw float64
 }
 
+// BalanceOperationType is the kind of a balance operation.
+type BalanceOperationType string
+
+const (
+	// BalanceOperationAccrual adds the bonuses of a processed order, one per order.
+	BalanceOperationAccrual BalanceOperationType = "accrual"
+	// BalanceOperationWithdrawal pays for an order with bonuses, an order may be paid in several parts.
+	BalanceOperationWithdrawal BalanceOperationType = "withdrawal"
+)
+
 type BalanceOperation struct {
 	UUID        uuid.UUID
 	UserUUID    uuid.UUID
+	Type        BalanceOperationType
 	Accrual     float64
 	Withdrawal  float64
-	OrderNumber int
+	OrderNumber OrderNumber
 	ProcessedAt time.Time
 }
 
@@ -27,13 +38,20 @@ var (
 	ErrBalanceInsufficientFunds = errors.New("insufficient funds in the account")
 	// ErrBalanceOperationsNotFound there are no balance operations
 	ErrBalanceOperationsNotFound = errors.New("balance operations not found")
+	// ErrBalanceAccrualExists the accrual for the order is already executed
+	ErrBalanceAccrualExists = errors.New("accrual for the order already exists")
 )
 
-func NewBalanceOperation(userUUID uuid.UUID, accrual, withdrawal float64, orderNumber int) BalanceOperation {
+// NewBalanceOperation returns a withdrawal when the withdrawal is positive and an accrual otherwise.
+func NewBalanceOperation(userUUID uuid.UUID, accrual, withdrawal float64, orderNumber OrderNumber) BalanceOperation {
 	var operation = BalanceOperation{}
 
 	operation.UUID = uuid.New()
 	operation.UserUUID = userUUID
+	operation.Type = BalanceOperationAccrual
+	if withdrawal > 0 {
+		operation.Type = BalanceOperationWithdrawal
+	}
 	operation.Accrual = accrual
 	operation.Withdrawal = withdrawal
 	operation.OrderNumber = orderNumber
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is a kind of change pushed to the user.
+type EventType string
+
+const (
+	// EventOrderStatus is published when an order changes its status.
+	EventOrderStatus EventType = "order_status"
+	// EventBalance is published when a balance operation is executed.
+	EventBalance EventType = "balance"
+)
+
+// Event is a change pushed to the user in real time.
+// Exactly one of Order and Balance is set, according to Type.
+type Event struct {
+	ID        int64
+	UserUUID  uuid.UUID
+	Type      EventType
+	CreatedAt time.Time
+	Order     *Order
+	Balance   *Balance
+}
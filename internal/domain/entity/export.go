@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// UserExport is the personal data of a user collected for an export.
+type UserExport struct {
+	Login      string
+	ExportedAt time.Time
+	Balance    Balance
+	Orders     []Order
+	Operations []BalanceOperation
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,15 +19,84 @@ const (
 	OrderRegistered Status = "REGISTERED"
 )
 
+// OrderNumberMaxLength is the longest order number accepted.
+const OrderNumberMaxLength = 64
+
+// OrderNumber is an order number, a string of digits of any length.
+// Leading zeros are significant, so the number is never converted to an integer.
+type OrderNumber string
+
+// Digits reports whether the number is a non-empty string of decimal digits no longer than OrderNumberMaxLength.
+// The checksum is validated separately.
+func (n OrderNumber) Digits() bool {
+	if len(n) == 0 || len(n) > OrderNumberMaxLength {
+		return false
+	}
+	for _, c := range n {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Final reports whether the accrual system will not change the status any more.
+func (s Status) Final() bool {
+	return s == OrderProcessed || s == OrderInvalid
+}
+
+// External reports whether the accrual system may report the status.
+func (s Status) External() bool {
+	return s == OrderRegistered || s == OrderProcessing || s == OrderInvalid || s == OrderProcessed
+}
+
+// Retractable reports whether the owner may remove the order:
+// it is not sent to the accrual system yet or the accrual system refused it.
+func (s Status) Retractable() bool {
+	return s == OrderNew || s == OrderInvalid
+}
+
 // Order is an entity for managing orders.
 type Order struct {
-	Number     int
+	Number     OrderNumber
 	Status     Status
 	Accrual    float64
 	UserUUID   uuid.UUID
 	UploadedAt time.Time
+	// Store is the store the order was made in, empty when unknown.
+	Store string
+}
+
+// OrderStatusChange is a transition of an order to a new status.
+type OrderStatusChange struct {
+	OrderNumber OrderNumber
+	Status      Status
+	Accrual     float64
+	ChangedAt   time.Time
 }
 
+// OrderRetraction is an audit record of an order removed by its owner.
+type OrderRetraction struct {
+	OrderNumber OrderNumber
+	UserUUID    uuid.UUID
+	Status      Status
+	UploadedAt  time.Time
+	RetractedAt time.Time
+}
+
+// OrderBatchMaxSize is the largest number of orders uploaded in one batch.
+const OrderBatchMaxSize = 1000
+
+// OrderUploadResult is an outcome of uploading an order number in a batch.
+type OrderUploadResult string
+
+const (
+	OrderUploadAccepted           OrderUploadResult = "accepted"
+	OrderUploadAlreadyUploaded    OrderUploadResult = "already_uploaded"
+	OrderUploadOwnedByAnotherUser OrderUploadResult = "owned_by_another_user"
+	OrderUploadInvalid            OrderUploadResult = "invalid"
+)
+
 var (
 	// ErrOrderAlreadyUploaded is returned when an order is already uploaded.
 	ErrOrderAlreadyUploaded = errors.New("order already uploaded")
@@ -34,14 +104,48 @@ var (
 	ErrOrderAlreadyUploadedByAnotherUser = errors.New("order already uploaded by another user")
 	// ErrOrderNotFound is returned when an order is not found.
 	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderNotRetractable is returned when an order is already processed by the accrual system.
+	ErrOrderNotRetractable = errors.New("order is not retractable")
+	// ErrOrderStatusFinal is returned when an order to update already has a final status.
+	ErrOrderStatusFinal = errors.New("order status is already final")
 
 	// ErrExternalOrderNotRegistered is returned when an order is not registered in external system.
 	ErrExternalOrderNotRegistered = errors.New("external order not registered")
 	// ErrExternalOrderRateLimitExceeded is returned when an order is rate limit exceeded in external system.
 	ErrExternalOrderRateLimitExceeded = errors.New("external order rate limit exceeded")
+	// ErrExternalUnavailable is returned when the external system answers with a server error.
+	ErrExternalUnavailable = errors.New("external system unavailable")
+	// ErrAccrualProviderNotFound is returned when no accrual provider is configured for an order.
+	ErrAccrualProviderNotFound = errors.New("accrual provider not found")
+
+	// ErrExternalResponseInvalid is returned when the accrual system response can not be trusted,
+	// the errors below wrap it with the reason.
+	ErrExternalResponseInvalid = errors.New("external response invalid")
+	// ErrExternalResponseMalformed is returned when the response is not a JSON object of the expected fields.
+	ErrExternalResponseMalformed = errors.New("external response malformed")
+	// ErrExternalOrderMismatch is returned when the response is about another order.
+	ErrExternalOrderMismatch = errors.New("external order number mismatch")
+	// ErrExternalStatusUnknown is returned when the response status is not a known order status.
+	ErrExternalStatusUnknown = errors.New("external order status unknown")
+	// ErrExternalAccrualInvalid is returned when the response accrual is negative or not a number.
+	ErrExternalAccrualInvalid = errors.New("external order accrual invalid")
 )
 
-func NewOrder(userUUID uuid.UUID, orderNumber int) Order {
+// RateLimitError is returned when the requests to the accrual system are limited, it wraps ErrExternalOrderRateLimitExceeded.
+// RetryAfter is the pause until the next request is allowed.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrExternalOrderRateLimitExceeded, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrExternalOrderRateLimitExceeded
+}
+
+func NewOrder(userUUID uuid.UUID, orderNumber OrderNumber) Order {
 
 	order := Order{
 		UserUUID:   userUUID,
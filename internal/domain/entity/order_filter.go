@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+const (
+	// OrdersPageMaxLimit is the largest allowed page of orders.
+	OrdersPageMaxLimit = 1000
+)
+
+// OrderCursor is a position in a list of orders sorted by upload time.
+// The order number breaks ties between orders uploaded at the same time.
+type OrderCursor struct {
+	UploadedAt time.Time
+	Number     OrderNumber
+}
+
+// OrderFilter selects a page of the user orders.
+type OrderFilter struct {
+	Statuses []Status
+	// UploadedFrom is inclusive, UploadedTo is exclusive, zero values are not applied.
+	UploadedFrom time.Time
+	UploadedTo   time.Time
+	Descending   bool
+	Limit        int
+	// After is the cursor of the last order of the previous page, nil for the first page.
+	After *OrderCursor
+}
+
+// OrderPage is a page of the user orders.
+type OrderPage struct {
+	Orders []Order
+	// Total is the number of orders matching the filter on all pages.
+	Total int
+	// Next is the cursor of the next page, nil for the last page.
+	Next *OrderCursor
+}
@@ -0,0 +1,20 @@
+package entity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+func TestOrderNumber_Digits(t *testing.T) {
+	assert.True(t, entity.OrderNumber("12345678903").Digits())
+	assert.True(t, entity.OrderNumber("0012345678903").Digits())
+	assert.True(t, entity.OrderNumber(strings.Repeat("9", entity.OrderNumberMaxLength)).Digits())
+	assert.False(t, entity.OrderNumber("").Digits())
+	assert.False(t, entity.OrderNumber("-12345678903").Digits())
+	assert.False(t, entity.OrderNumber("1234 5678903").Digits())
+	assert.False(t, entity.OrderNumber(strings.Repeat("9", entity.OrderNumberMaxLength+1)).Digits())
+}
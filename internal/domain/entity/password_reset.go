@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken is a single-use token for resetting a forgotten password.
+// Only the hash of the token is stored.
+type PasswordResetToken struct {
+	TokenHash string
+	UserUUID  uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// NewPasswordResetToken returns a new password reset token that lives for ttl.
+func NewPasswordResetToken(userUUID uuid.UUID, tokenHash string, ttl time.Duration) *PasswordResetToken {
+	now := time.Now()
+	return &PasswordResetToken{
+		TokenHash: tokenHash,
+		UserUUID:  userUUID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "errors"
+
+// Role is a set of permissions granted to a user.
+type Role string
+
+// User roles.
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+// Permissions checked on the routes of the support and admin tooling.
+const (
+	PermissionUsersRead    = "users:read"
+	PermissionUsersWrite   = "users:write"
+	PermissionAccrualWrite = "accrual:write"
+	PermissionMetricsRead  = "metrics:read"
+)
+
+// rolePermissions lists the permissions of each role.
+var rolePermissions = map[Role][]string{
+	RoleUser:    {},
+	RoleSupport: {PermissionUsersRead, PermissionMetricsRead},
+	RoleAdmin:   {PermissionUsersRead, PermissionUsersWrite, PermissionAccrualWrite, PermissionMetricsRead},
+}
+
+// ErrRoleInvalid is returned when a role is unknown.
+var ErrRoleInvalid = errors.New("role is invalid")
+
+// ParseRole returns the role by its name.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", ErrRoleInvalid
+	}
+	return role, nil
+}
+
+// Can reports whether the role is granted the permission.
+func (r Role) Can(permission string) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+func TestRole_Can(t *testing.T) {
+	assert.False(t, entity.RoleUser.Can(entity.PermissionUsersRead))
+	assert.True(t, entity.RoleSupport.Can(entity.PermissionUsersRead))
+	assert.False(t, entity.RoleSupport.Can(entity.PermissionUsersWrite))
+	assert.True(t, entity.RoleAdmin.Can(entity.PermissionUsersWrite))
+	assert.True(t, entity.RoleAdmin.Can(entity.PermissionAccrualWrite))
+	assert.False(t, entity.RoleSupport.Can(entity.PermissionAccrualWrite))
+	assert.True(t, entity.RoleSupport.Can(entity.PermissionMetricsRead))
+	assert.False(t, entity.RoleUser.Can(entity.PermissionMetricsRead))
+	assert.False(t, entity.Role("root").Can(entity.PermissionUsersRead))
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := entity.ParseRole("support")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.RoleSupport, role)
+
+	_, err = entity.ParseRole("root")
+	assert.ErrorIs(t, err, entity.ErrRoleInvalid)
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a server-side record of an issued JWT.
+// A JWT is accepted only while its session exists.
+type Session struct {
+	UUID       uuid.UUID
+	UserUUID   uuid.UUID
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	IP         string
+	UserAgent  string
+}
+
+// NewSession returns a new session for the user that lives for ttl.
+func NewSession(userUUID uuid.UUID, ttl time.Duration) *Session {
+	now := time.Now()
+	return &Session{
+		UUID:       uuid.New(),
+		UserUUID:   userUUID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
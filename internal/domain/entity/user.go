@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,18 @@ type User struct {
 	Login        string
 	PasswordHash string
 	JWT          string
+	SessionUUID  uuid.UUID
+	TOTPSecret   string
+	TOTPEnabled  bool
+	Role         Role
+	// APIKeyUUID is set when the request is authenticated by an API key instead of a JWT.
+	APIKeyUUID uuid.UUID
+}
+
+// TwoFactorEnrollment is a pending TOTP secret to be added to an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
 }
 
 var (
@@ -21,6 +34,36 @@ var (
 	ErrUserExists = errors.New("user already exists")
 	// ErrUserWrongPasswordOrLogin is returned when a user password is wrong.
 	ErrUserWrongPasswordOrLogin = errors.New("wrong password")
+	// ErrUserSessionNotFound is returned when a session is revoked, expired or does not exist.
+	ErrUserSessionNotFound = errors.New("session not found")
+	// ErrUserTwoFactorRequired is returned when the password is right, but the second factor is still needed.
+	ErrUserTwoFactorRequired = errors.New("two-factor authentication required")
+	// ErrUserTwoFactorChallengeInvalid is returned when a two-factor challenge token is invalid or expired.
+	ErrUserTwoFactorChallengeInvalid = errors.New("two-factor challenge is invalid")
+	// ErrUserTwoFactorCodeInvalid is returned when a TOTP or recovery code is wrong.
+	ErrUserTwoFactorCodeInvalid = errors.New("two-factor code is invalid")
+	// ErrUserTwoFactorLocked is returned when the user has used up the second factor attempts for a while.
+	ErrUserTwoFactorLocked = errors.New("too many two-factor attempts")
+	// ErrUserTwoFactorNotEnrolled is returned when there is no pending or enabled TOTP secret.
+	ErrUserTwoFactorNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	// ErrUserTwoFactorAlreadyEnabled is returned when enrolling while two-factor authentication is enabled.
+	ErrUserTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrPasswordResetTokenInvalid is returned when a password reset token is unknown, used or expired.
+	ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid")
+	// ErrPasswordResetUnavailable is returned when there is no way to deliver a password reset token.
+	ErrPasswordResetUnavailable = errors.New("password reset is unavailable")
+
+	// ErrPasswordPolicy is returned when a password does not satisfy the password policy.
+	// All the policy errors below wrap it.
+	ErrPasswordPolicy = errors.New("password does not satisfy the policy")
+	// ErrPasswordTooShort is returned when a password is shorter than the policy allows.
+	ErrPasswordTooShort = fmt.Errorf("%w: password is too short", ErrPasswordPolicy)
+	// ErrPasswordTooSimple is returned when a password has too few character classes.
+	ErrPasswordTooSimple = fmt.Errorf("%w: password has too few character classes", ErrPasswordPolicy)
+	// ErrPasswordContainsLogin is returned when a password contains the user login.
+	ErrPasswordContainsLogin = fmt.Errorf("%w: password contains login", ErrPasswordPolicy)
+	// ErrPasswordTooCommon is returned when a password is in the list of common passwords.
+	ErrPasswordTooCommon = fmt.Errorf("%w: password is too common", ErrPasswordPolicy)
 )
 
 // NewUser returns a new user.
@@ -36,6 +79,7 @@ func NewUser(login, passwordHash, jwtToken string, userUUID uuid.UUID) *User {
 	user.Login = login
 	user.PasswordHash = passwordHash
 	user.JWT = jwtToken
+	user.Role = RoleUser
 
 	return user
 }
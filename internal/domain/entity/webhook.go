@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a subscription of a partner endpoint to the events of a user.
+// The secret signs the deliveries, so it is stored as is.
+type Webhook struct {
+	UUID       uuid.UUID
+	UserUUID   uuid.UUID
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// WebhookDeliveryStatus is a state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is an event to be sent to a webhook and the log of its attempts.
+type WebhookDelivery struct {
+	UUID          uuid.UUID
+	WebhookUUID   uuid.UUID
+	EventType     EventType
+	Payload       []byte
+	Status        WebhookDeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	ResponseCode  int
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	// URL and Secret are the target of a claimed delivery.
+	URL    string
+	Secret string
+}
+
+var (
+	// ErrWebhookNotFound is returned when a webhook is unknown or belongs to another user.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookURLInvalid is returned when a webhook URL is not an absolute http or https URL.
+	ErrWebhookURLInvalid = errors.New("webhook url is invalid")
+	// ErrWebhookURLForbidden is returned when a webhook URL points to a loopback, private, link-local or unspecified address.
+	ErrWebhookURLForbidden = errors.New("webhook url points to an internal address")
+	// ErrWebhookEventTypeInvalid is returned when a webhook is requested with an unknown or empty event type list.
+	ErrWebhookEventTypeInvalid = errors.New("webhook event type is invalid")
+)
+
+// NewWebhook returns a new webhook of the user.
+func NewWebhook(userUUID uuid.UUID, url string, eventTypes []EventType, secret string) *Webhook {
+	return &Webhook{
+		UUID:       uuid.New(),
+		UserUUID:   userUUID,
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// ValidEventType reports whether eventType is a known event type.
+func ValidEventType(eventType EventType) bool {
+	switch eventType {
+	case EventOrderStatus, EventBalance:
+		return true
+	}
+	return false
+}
+
+// NewWebhookDelivery returns a pending delivery of the payload to the webhook.
+func NewWebhookDelivery(webhookUUID uuid.UUID, eventType EventType, payload []byte) WebhookDelivery {
+	now := time.Now()
+	return WebhookDelivery{
+		UUID:          uuid.New(),
+		WebhookUUID:   webhookUUID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
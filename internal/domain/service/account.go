@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccountUsers is an interface for the user data of an account.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccountUsers
+type AccountUsers interface {
+	GetUser(ctx context.Context, userUUID uuid.UUID) (*entity.User, error)
+	Anonymize(ctx context.Context, userUUID uuid.UUID) error
+}
+
+// AccountOrders is an interface for the orders of an account.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccountOrders
+type AccountOrders interface {
+	GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error)
+	Pseudonymize(ctx context.Context, userUUID, pseudonym uuid.UUID) error
+}
+
+// AccountBalances is an interface for the balance of an account.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccountBalances
+type AccountBalances interface {
+	GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error)
+	GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error)
+	Pseudonymize(ctx context.Context, userUUID, pseudonym uuid.UUID) error
+}
+
+// AccountService is a service for exporting and deleting the personal data of a user.
+type AccountService struct {
+	users      AccountUsers
+	orders     AccountOrders
+	balances   AccountBalances
+	logger     *logger.Logger
+	transactor Transactor
+}
+
+// NewAccountService returns a new account service.
+func NewAccountService(logger *logger.Logger, users AccountUsers, orders AccountOrders, balances AccountBalances) *AccountService {
+	return &AccountService{
+		users:      users,
+		orders:     orders,
+		balances:   balances,
+		logger:     logger,
+		transactor: noTransaction{},
+	}
+}
+
+// SetTransactor sets the transactor deleting the account atomically.
+func (s *AccountService) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
+}
+
+// Export collects the login, orders, balance and balance operations of the user.
+func (s *AccountService) Export(ctx context.Context, userUUID uuid.UUID) (*entity.UserExport, error) {
+	const op = "domain.services.AccountService.Export"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", userUUID.String()),
+	)
+
+	user, err := s.users.GetUser(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orders.GetAll(ctx, userUUID)
+	if err != nil && !errors.Is(err, entity.ErrOrderNotFound) {
+		return nil, err
+	}
+
+	balance, err := s.balances.GetBalance(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, err := s.balances.GetOperations(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("User data exported")
+	return &entity.UserExport{
+		Login:      user.Login,
+		ExportedAt: time.Now(),
+		Balance:    *balance,
+		Orders:     orders,
+		Operations: operations,
+	}, nil
+}
+
+// Delete deletes the account of the user.
+// Orders and balance operations are kept for accounting under a random pseudonym,
+// then the user is anonymized and cannot log in anymore.
+// The steps run in one transaction, a failed deletion can be repeated.
+func (s *AccountService) Delete(ctx context.Context, userUUID uuid.UUID) error {
+	const op = "domain.services.AccountService.Delete"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", userUUID.String()),
+	)
+
+	pseudonym := uuid.New()
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.orders.Pseudonymize(ctx, userUUID, pseudonym)
+		if err != nil {
+			return err
+		}
+
+		err = s.balances.Pseudonymize(ctx, userUUID, pseudonym)
+		if err != nil {
+			return err
+		}
+
+		return s.users.Anonymize(ctx, userUUID)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Account deleted")
+	return nil
+}
@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestAccountService_Export(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	t.Run("Export a user without orders", func(t *testing.T) {
+		t.Parallel()
+		usersMock := mocks.NewAccountUsers(t)
+		usersMock.On("GetUser", mock.Anything, userUUID).
+			Return(&entity.User{UUID: userUUID, Login: "test"}, nil).
+			Once()
+		ordersMock := mocks.NewAccountOrders(t)
+		ordersMock.On("GetAll", mock.Anything, userUUID).
+			Return(nil, entity.ErrOrderNotFound).
+			Once()
+		balancesMock := mocks.NewAccountBalances(t)
+		balancesMock.On("GetBalance", mock.Anything, userUUID).
+			Return(&entity.Balance{Current: 10}, nil).
+			Once()
+		balancesMock.On("GetOperations", mock.Anything, userUUID).
+			Return(nil, nil).
+			Once()
+		s := service.NewAccountService(logger.NewLogger(), usersMock, ordersMock, balancesMock)
+
+		export, err := s.Export(ctx, userUUID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "test", export.Login)
+		assert.Equal(t, float64(10), export.Balance.Current)
+		assert.Empty(t, export.Orders)
+	})
+}
+
+func TestAccountService_Delete(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	t.Run("Orders and balance get the same pseudonym", func(t *testing.T) {
+		t.Parallel()
+		var pseudonym uuid.UUID
+		ordersMock := mocks.NewAccountOrders(t)
+		ordersMock.On("Pseudonymize", mock.Anything, userUUID, mock.Anything).
+			Run(func(args mock.Arguments) { pseudonym = args.Get(2).(uuid.UUID) }).
+			Return(nil).
+			Once()
+		balancesMock := mocks.NewAccountBalances(t)
+		balancesMock.On("Pseudonymize", mock.Anything, userUUID, mock.MatchedBy(func(p uuid.UUID) bool {
+			return p == pseudonym && p != userUUID
+		})).
+			Return(nil).
+			Once()
+		usersMock := mocks.NewAccountUsers(t)
+		usersMock.On("Anonymize", mock.Anything, userUUID).
+			Return(nil).
+			Once()
+		s := service.NewAccountService(logger.NewLogger(), usersMock, ordersMock, balancesMock)
+
+		err := s.Delete(ctx, userUUID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("User is kept when pseudonymization fails", func(t *testing.T) {
+		t.Parallel()
+		ordersMock := mocks.NewAccountOrders(t)
+		ordersMock.On("Pseudonymize", mock.Anything, userUUID, mock.Anything).
+			Return(errors.New("db error")).
+			Once()
+		s := service.NewAccountService(logger.NewLogger(), mocks.NewAccountUsers(t), ordersMock, mocks.NewAccountBalances(t))
+
+		err := s.Delete(ctx, userUUID)
+
+		assert.Error(t, err)
+	})
+}
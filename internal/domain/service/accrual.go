@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccrualRepository is an interface for the reward rules and orders of the built-in accrual engine.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AccrualRepository
+type AccrualRepository interface {
+	AddRewardRule(ctx context.Context, rule entity.RewardRule) error
+	GetRewardRules(ctx context.Context) ([]entity.RewardRule, error)
+	AddAccrualOrder(ctx context.Context, order entity.AccrualOrder) error
+	GetAccrualOrder(ctx context.Context, number entity.OrderNumber) (entity.AccrualOrder, error)
+}
+
+// AccrualEngine is the built-in accrual system. It calculates the accruals of the orders
+// registered with their goods by the locally stored reward rules and serves them as OrderClient.
+type AccrualEngine struct {
+	repository AccrualRepository
+	logger     *logger.Logger
+}
+
+// NewAccrualEngine returns a new built-in accrual engine.
+func NewAccrualEngine(logger *logger.Logger, repository AccrualRepository) *AccrualEngine {
+	return &AccrualEngine{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// AddRule registers a reward rule, it applies to the orders registered after it.
+func (e *AccrualEngine) AddRule(ctx context.Context, match string, reward float64, rewardType entity.RewardType) (entity.RewardRule, error) {
+	const op = "domain.services.AccrualEngine.AddRule"
+	log := e.logger.With(
+		e.logger.StringField("op", op),
+		e.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		e.logger.StringField("match", match),
+	)
+
+	rule, err := entity.NewRewardRule(match, reward, rewardType)
+	if err != nil {
+		return entity.RewardRule{}, err
+	}
+	err = e.repository.AddRewardRule(ctx, rule)
+	if err != nil {
+		return entity.RewardRule{}, err
+	}
+	log.Info("Reward rule added")
+	return rule, nil
+}
+
+// RegisterOrder registers an order with its goods and calculates its accrual by the current rules.
+func (e *AccrualEngine) RegisterOrder(ctx context.Context, number entity.OrderNumber, goods []entity.Good) (entity.AccrualOrder, error) {
+	const op = "domain.services.AccrualEngine.RegisterOrder"
+	log := e.logger.With(
+		e.logger.StringField("op", op),
+		e.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		e.logger.AnyField("order_number", number),
+	)
+
+	rules, err := e.repository.GetRewardRules(ctx)
+	if err != nil {
+		return entity.AccrualOrder{}, err
+	}
+	order := entity.AccrualOrder{
+		Number:       number,
+		Goods:        goods,
+		Accrual:      entity.CalculateAccrual(goods, rules),
+		RegisteredAt: time.Now(),
+	}
+	err = e.repository.AddAccrualOrder(ctx, order)
+	if err != nil {
+		return entity.AccrualOrder{}, err
+	}
+	log.Info("Accrual order registered", log.AnyField("accrual", order.Accrual))
+	return order, nil
+}
+
+// Check returns the accrual of a registered order, it implements OrderClient.
+// The accrual is calculated at the registration, so a registered order is always PROCESSED.
+func (e *AccrualEngine) Check(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
+	order, err := e.repository.GetAccrualOrder(ctx, number)
+	if err != nil {
+		if errors.Is(err, entity.ErrAccrualOrderNotFound) {
+			return entity.Order{}, entity.ErrExternalOrderNotRegistered
+		}
+		return entity.Order{}, err
+	}
+	return entity.Order{
+		Number:  order.Number,
+		Status:  entity.OrderProcessed,
+		Accrual: order.Accrual,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestAccrualEngine_RegisterOrder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+
+	repositoryMock := mocks.NewAccrualRepository(t)
+	repositoryMock.On("GetRewardRules", mock.Anything).
+		Return([]entity.RewardRule{{Match: "Bork", Reward: 10, RewardType: entity.RewardPercent}}, nil).
+		Once()
+	repositoryMock.On("AddAccrualOrder", mock.Anything, mock.MatchedBy(func(order entity.AccrualOrder) bool {
+		return order.Number == "12345678903" && order.Accrual == 700 && len(order.Goods) == 1
+	})).
+		Return(nil).
+		Once()
+	e := service.NewAccrualEngine(logger.NewLogger(), repositoryMock)
+
+	order, err := e.RegisterOrder(ctx, "12345678903", []entity.Good{{Description: "Чайник Bork", Price: 7000}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 700.0, order.Accrual)
+}
+
+func TestAccrualEngine_Check(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+
+	repositoryMock := mocks.NewAccrualRepository(t)
+	repositoryMock.On("GetAccrualOrder", mock.Anything, entity.OrderNumber("12345678903")).
+		Return(entity.AccrualOrder{Number: "12345678903", Accrual: 700}, nil).
+		Once()
+	repositoryMock.On("GetAccrualOrder", mock.Anything, entity.OrderNumber("79927398713")).
+		Return(entity.AccrualOrder{}, entity.ErrAccrualOrderNotFound).
+		Once()
+	e := service.NewAccrualEngine(logger.NewLogger(), repositoryMock)
+
+	order, err := e.Check(ctx, "12345678903")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.OrderProcessed, order.Status)
+	assert.Equal(t, 700.0, order.Accrual)
+
+	_, err = e.Check(ctx, "79927398713")
+	assert.ErrorIs(t, err, entity.ErrExternalOrderNotRegistered)
+}
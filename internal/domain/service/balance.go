@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 
@@ -11,12 +12,16 @@ import (
 )
 
 // BalanceRepository is an interface for balance repository.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=BalanceRepository
 type BalanceRepository interface {
 	GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error)
 	GetWithdrawOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error)
 	Withdraw(ctx context.Context, operation entity.BalanceOperation) error
 	Accrue(ctx context.Context, operation entity.BalanceOperation) error
 	CreateBalance(ctx context.Context, userUUID uuid.UUID) error
+	GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error)
+	ReassignUserBalance(ctx context.Context, fromUserUUID, toUserUUID uuid.UUID) error
 }
 
 // BalanceService is a service for managing balances.
@@ -25,6 +30,7 @@ type BalanceRepository interface {
 type BalanceService struct {
 	repository BalanceRepository
 	logger     *logger.Logger
+	publisher  EventPublisher
 }
 
 // NewBalanceService returns a new balance service.
@@ -35,6 +41,11 @@ func NewBalanceService(logger *logger.Logger, repository BalanceRepository) *Bal
 	}
 }
 
+// SetPublisher sets the publisher of the balance events.
+func (s *BalanceService) SetPublisher(publisher EventPublisher) {
+	s.publisher = publisher
+}
+
 // GetBalance returns the balance of a user.
 func (s *BalanceService) GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error) {
 	const op = "domain.services.BalanceService.GetBalance"
@@ -61,13 +72,38 @@ func (s *BalanceService) Execute(ctx context.Context, operation entity.BalanceOp
 		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
 	)
 
-	if operation.Withdrawal > 0 {
+	var err error
+	switch {
+	case operation.Type == entity.BalanceOperationWithdrawal && operation.Withdrawal > 0:
 		log.Info("Executing withdrawal")
-		return s.repository.Withdraw(ctx, operation)
-	}
-	if operation.Accrual > 0 {
+		err = s.repository.Withdraw(ctx, operation)
+	case operation.Type == entity.BalanceOperationAccrual && operation.Accrual > 0:
 		log.Info("Executing accrual")
-		return s.repository.Accrue(ctx, operation)
+		err = s.repository.Accrue(ctx, operation)
+	default:
+		return nil
+	}
+	if errors.Is(err, entity.ErrBalanceAccrualExists) {
+		// the order result is delivered both by polling and by push, the accrual is executed once
+		log.Info("Accrual already executed")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.publisher != nil {
+		balance, err := s.repository.GetBalance(ctx, operation.UserUUID)
+		if err != nil {
+			// the operation is done, only the event is lost
+			log.Error("Failed to get balance for the event", log.ErrorField(err))
+			return nil
+		}
+		s.publisher.Publish(ctx, entity.Event{
+			UserUUID: operation.UserUUID,
+			Type:     entity.EventBalance,
+			Balance:  balance,
+		})
 	}
 	return nil
 }
@@ -81,6 +117,17 @@ func (s *BalanceService) GetWithdrawOperations(ctx context.Context, userUUID uui
 	return operations, nil
 }
 
+// GetOperations returns all balance operations for a user.
+func (s *BalanceService) GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	return s.repository.GetOperations(ctx, userUUID)
+}
+
+// Pseudonymize moves the balance and balance operations of a deleted user to the pseudonym,
+// so they are kept for accounting but cannot be linked back to the user.
+func (s *BalanceService) Pseudonymize(ctx context.Context, userUUID, pseudonym uuid.UUID) error {
+	return s.repository.ReassignUserBalance(ctx, userUUID, pseudonym)
+}
+
 func (s *BalanceService) CreateBalanceForUser(ctx context.Context, userUUID uuid.UUID) error {
 	err := s.repository.CreateBalance(ctx, userUUID)
 	if err != nil {
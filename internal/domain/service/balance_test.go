@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestBalanceService_Execute(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	t.Run("Accrual publishes the balance", func(t *testing.T) {
+		operation := entity.NewBalanceOperation(userUUID, 500, 0, "12345678903")
+		repositoryMock := mocks.NewBalanceRepository(t)
+		repositoryMock.On("Accrue", mock.Anything, operation).Return(nil).Once()
+		repositoryMock.On("GetBalance", mock.Anything, userUUID).
+			Return(&entity.Balance{UserUUID: userUUID, Current: 500}, nil).
+			Once()
+		publisherMock := mocks.NewEventPublisher(t)
+		publisherMock.On("Publish", mock.Anything, mock.MatchedBy(func(event entity.Event) bool {
+			return event.Type == entity.EventBalance && event.UserUUID == userUUID && event.Balance.Current == 500
+		})).Once()
+		s := service.NewBalanceService(logger.NewLogger(), repositoryMock)
+		s.SetPublisher(publisherMock)
+
+		assert.NoError(t, s.Execute(ctx, operation))
+	})
+
+	t.Run("Failed withdrawal publishes nothing", func(t *testing.T) {
+		operation := entity.NewBalanceOperation(userUUID, 0, 500, "12345678903")
+		repositoryMock := mocks.NewBalanceRepository(t)
+		repositoryMock.On("Withdraw", mock.Anything, operation).Return(entity.ErrBalanceInsufficientFunds).Once()
+		publisherMock := mocks.NewEventPublisher(t)
+		s := service.NewBalanceService(logger.NewLogger(), repositoryMock)
+		s.SetPublisher(publisherMock)
+
+		assert.ErrorIs(t, s.Execute(ctx, operation), entity.ErrBalanceInsufficientFunds)
+	})
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+// EventPublisher is an interface for pushing events to the users in real time.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=EventPublisher
+type EventPublisher interface {
+	Publish(ctx context.Context, event entity.Event)
+}
+
+// MultiPublisher publishes events to every publisher in turn.
+type MultiPublisher []EventPublisher
+
+// Publish publishes the event to every publisher.
+func (m MultiPublisher) Publish(ctx context.Context, event entity.Event) {
+	for _, publisher := range m {
+		publisher.Publish(ctx, event)
+	}
+}
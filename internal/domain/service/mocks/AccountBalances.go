@@ -0,0 +1,98 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AccountBalances is an autogenerated mock type for the AccountBalances type
+type AccountBalances struct {
+	mock.Mock
+}
+
+// GetBalance provides a mock function with given fields: ctx, userUUID
+func (_m *AccountBalances) GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 *entity.Balance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*entity.Balance, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *entity.Balance); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Balance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOperations provides a mock function with given fields: ctx, userUUID
+func (_m *AccountBalances) GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.BalanceOperation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.BalanceOperation, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.BalanceOperation); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.BalanceOperation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Pseudonymize provides a mock function with given fields: ctx, userUUID, pseudonym
+func (_m *AccountBalances) Pseudonymize(ctx context.Context, userUUID uuid.UUID, pseudonym uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, pseudonym)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, pseudonym)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAccountBalances interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccountBalances creates a new instance of AccountBalances. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccountBalances(t mockConstructorTestingTNewAccountBalances) *AccountBalances {
+	mock := &AccountBalances{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,72 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AccountOrders is an autogenerated mock type for the AccountOrders type
+type AccountOrders struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, userUUID
+func (_m *AccountOrders) GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Order, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Order); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Pseudonymize provides a mock function with given fields: ctx, userUUID, pseudonym
+func (_m *AccountOrders) Pseudonymize(ctx context.Context, userUUID uuid.UUID, pseudonym uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, pseudonym)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, pseudonym)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAccountOrders interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccountOrders creates a new instance of AccountOrders. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccountOrders(t mockConstructorTestingTNewAccountOrders) *AccountOrders {
+	mock := &AccountOrders{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,108 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AccrualRepository is an autogenerated mock type for the AccrualRepository type
+type AccrualRepository struct {
+	mock.Mock
+}
+
+// AddAccrualOrder provides a mock function with given fields: ctx, order
+func (_m *AccrualRepository) AddAccrualOrder(ctx context.Context, order entity.AccrualOrder) error {
+	ret := _m.Called(ctx, order)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.AccrualOrder) error); ok {
+		r0 = rf(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddRewardRule provides a mock function with given fields: ctx, rule
+func (_m *AccrualRepository) AddRewardRule(ctx context.Context, rule entity.RewardRule) error {
+	ret := _m.Called(ctx, rule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.RewardRule) error); ok {
+		r0 = rf(ctx, rule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAccrualOrder provides a mock function with given fields: ctx, number
+func (_m *AccrualRepository) GetAccrualOrder(ctx context.Context, number entity.OrderNumber) (entity.AccrualOrder, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 entity.AccrualOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) (entity.AccrualOrder, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) entity.AccrualOrder); ok {
+		r0 = rf(ctx, number)
+	} else {
+		r0 = ret.Get(0).(entity.AccrualOrder)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRewardRules provides a mock function with given fields: ctx
+func (_m *AccrualRepository) GetRewardRules(ctx context.Context) ([]entity.RewardRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.RewardRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.RewardRule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.RewardRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RewardRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAccrualRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAccrualRepository creates a new instance of AccrualRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAccrualRepository(t mockConstructorTestingTNewAccrualRepository) *AccrualRepository {
+	mock := &AccrualRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
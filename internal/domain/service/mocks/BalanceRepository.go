@@ -0,0 +1,166 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// BalanceRepository is an autogenerated mock type for the BalanceRepository type
+type BalanceRepository struct {
+	mock.Mock
+}
+
+// Accrue provides a mock function with given fields: ctx, operation
+func (_m *BalanceRepository) Accrue(ctx context.Context, operation entity.BalanceOperation) error {
+	ret := _m.Called(ctx, operation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.BalanceOperation) error); ok {
+		r0 = rf(ctx, operation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateBalance provides a mock function with given fields: ctx, userUUID
+func (_m *BalanceRepository) CreateBalance(ctx context.Context, userUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBalance provides a mock function with given fields: ctx, userUUID
+func (_m *BalanceRepository) GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 *entity.Balance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*entity.Balance, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *entity.Balance); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Balance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOperations provides a mock function with given fields: ctx, userUUID
+func (_m *BalanceRepository) GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.BalanceOperation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.BalanceOperation, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.BalanceOperation); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.BalanceOperation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWithdrawOperations provides a mock function with given fields: ctx, userUUID
+func (_m *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.BalanceOperation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.BalanceOperation, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.BalanceOperation); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.BalanceOperation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReassignUserBalance provides a mock function with given fields: ctx, fromUserUUID, toUserUUID
+func (_m *BalanceRepository) ReassignUserBalance(ctx context.Context, fromUserUUID uuid.UUID, toUserUUID uuid.UUID) error {
+	ret := _m.Called(ctx, fromUserUUID, toUserUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, fromUserUUID, toUserUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Withdraw provides a mock function with given fields: ctx, operation
+func (_m *BalanceRepository) Withdraw(ctx context.Context, operation entity.BalanceOperation) error {
+	ret := _m.Called(ctx, operation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.BalanceOperation) error); ok {
+		r0 = rf(ctx, operation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewBalanceRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBalanceRepository creates a new instance of BalanceRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBalanceRepository(t mockConstructorTestingTNewBalanceRepository) *BalanceRepository {
+	mock := &BalanceRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
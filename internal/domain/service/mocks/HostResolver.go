@@ -0,0 +1,56 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	netip "net/netip"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HostResolver is an autogenerated mock type for the HostResolver type
+type HostResolver struct {
+	mock.Mock
+}
+
+// LookupNetIP provides a mock function with given fields: ctx, network, host
+func (_m *HostResolver) LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+	ret := _m.Called(ctx, network, host)
+
+	var r0 []netip.Addr
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]netip.Addr, error)); ok {
+		return rf(ctx, network, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []netip.Addr); ok {
+		r0 = rf(ctx, network, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netip.Addr)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, network, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewHostResolver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewHostResolver creates a new instance of HostResolver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewHostResolver(t mockConstructorTestingTNewHostResolver) *HostResolver {
+	mock := &HostResolver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderAccruer is an autogenerated mock type for the OrderAccruer type
+type OrderAccruer struct {
+	mock.Mock
+}
+
+// Execute provides a mock function with given fields: ctx, operation
+func (_m *OrderAccruer) Execute(ctx context.Context, operation entity.BalanceOperation) error {
+	ret := _m.Called(ctx, operation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.BalanceOperation) error); ok {
+		r0 = rf(ctx, operation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewOrderAccruer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderAccruer creates a new instance of OrderAccruer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderAccruer(t mockConstructorTestingTNewOrderAccruer) *OrderAccruer {
+	mock := &OrderAccruer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
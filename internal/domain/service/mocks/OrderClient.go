@@ -0,0 +1,54 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderClient is an autogenerated mock type for the OrderClient type
+type OrderClient struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields: ctx, number
+func (_m *OrderClient) Check(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) (entity.Order, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) entity.Order); ok {
+		r0 = rf(ctx, number)
+	} else {
+		r0 = ret.Get(0).(entity.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewOrderClient interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderClient creates a new instance of OrderClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderClient(t mockConstructorTestingTNewOrderClient) *OrderClient {
+	mock := &OrderClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,288 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// OrderRepository is an autogenerated mock type for the OrderRepository type
+type OrderRepository struct {
+	mock.Mock
+}
+
+// AddOrderForUser provides a mock function with given fields: ctx, order
+func (_m *OrderRepository) AddOrderForUser(ctx context.Context, order entity.Order) error {
+	ret := _m.Called(ctx, order)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Order) error); ok {
+		r0 = rf(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddOrderStatusChange provides a mock function with given fields: ctx, change
+func (_m *OrderRepository) AddOrderStatusChange(ctx context.Context, change entity.OrderStatusChange) error {
+	ret := _m.Called(ctx, change)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderStatusChange) error); ok {
+		r0 = rf(ctx, change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddOrdersForUser provides a mock function with given fields: ctx, userUUID, orders
+func (_m *OrderRepository) AddOrdersForUser(ctx context.Context, userUUID uuid.UUID, orders []entity.Order) (map[entity.OrderNumber]entity.OrderUploadResult, error) {
+	ret := _m.Called(ctx, userUUID, orders)
+
+	var r0 map[entity.OrderNumber]entity.OrderUploadResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []entity.Order) (map[entity.OrderNumber]entity.OrderUploadResult, error)); ok {
+		return rf(ctx, userUUID, orders)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []entity.Order) map[entity.OrderNumber]entity.OrderUploadResult); ok {
+		r0 = rf(ctx, userUUID, orders)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[entity.OrderNumber]entity.OrderUploadResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []entity.Order) error); ok {
+		r1 = rf(ctx, userUUID, orders)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllUserOrders provides a mock function with given fields: ctx, userUUID
+func (_m *OrderRepository) GetAllUserOrders(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Order, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Order); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderByNumber provides a mock function with given fields: ctx, number
+func (_m *OrderRepository) GetOrderByNumber(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) (entity.Order, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) entity.Order); ok {
+		r0 = rf(ctx, number)
+	} else {
+		r0 = ret.Get(0).(entity.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderStatusHistory provides a mock function with given fields: ctx, number
+func (_m *OrderRepository) GetOrderStatusHistory(ctx context.Context, number entity.OrderNumber) ([]entity.OrderStatusChange, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 []entity.OrderStatusChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) ([]entity.OrderStatusChange, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.OrderNumber) []entity.OrderStatusChange); ok {
+		r0 = rf(ctx, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.OrderStatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnfinishedOrders provides a mock function with given fields: ctx
+func (_m *OrderRepository) GetUnfinishedOrders(ctx context.Context) ([]entity.Order, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.Order, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Order); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserOrder provides a mock function with given fields: ctx, userUUID, number
+func (_m *OrderRepository) GetUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, error) {
+	ret := _m.Called(ctx, userUUID, number)
+
+	var r0 entity.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) (entity.Order, error)); ok {
+		return rf(ctx, userUUID, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) entity.Order); ok {
+		r0 = rf(ctx, userUUID, number)
+	} else {
+		r0 = ret.Get(0).(entity.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, userUUID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserOrdersPage provides a mock function with given fields: ctx, userUUID, filter
+func (_m *OrderRepository) GetUserOrdersPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error) {
+	ret := _m.Called(ctx, userUUID, filter)
+
+	var r0 entity.OrderPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderFilter) (entity.OrderPage, error)); ok {
+		return rf(ctx, userUUID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderFilter) entity.OrderPage); ok {
+		r0 = rf(ctx, userUUID, filter)
+	} else {
+		r0 = ret.Get(0).(entity.OrderPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.OrderFilter) error); ok {
+		r1 = rf(ctx, userUUID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReassignUserOrders provides a mock function with given fields: ctx, fromUserUUID, toUserUUID
+func (_m *OrderRepository) ReassignUserOrders(ctx context.Context, fromUserUUID uuid.UUID, toUserUUID uuid.UUID) error {
+	ret := _m.Called(ctx, fromUserUUID, toUserUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, fromUserUUID, toUserUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RetractUserOrder provides a mock function with given fields: ctx, userUUID, number
+func (_m *OrderRepository) RetractUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.OrderRetraction, error) {
+	ret := _m.Called(ctx, userUUID, number)
+
+	var r0 entity.OrderRetraction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) (entity.OrderRetraction, error)); ok {
+		return rf(ctx, userUUID, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.OrderNumber) entity.OrderRetraction); ok {
+		r0 = rf(ctx, userUUID, number)
+	} else {
+		r0 = ret.Get(0).(entity.OrderRetraction)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.OrderNumber) error); ok {
+		r1 = rf(ctx, userUUID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateOrderForUser provides a mock function with given fields: ctx, order
+func (_m *OrderRepository) UpdateOrderForUser(ctx context.Context, order entity.Order) error {
+	ret := _m.Called(ctx, order)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Order) error); ok {
+		r0 = rf(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewOrderRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOrderRepository creates a new instance of OrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOrderRepository(t mockConstructorTestingTNewOrderRepository) *OrderRepository {
+	mock := &OrderRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
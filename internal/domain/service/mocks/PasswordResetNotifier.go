@@ -0,0 +1,46 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PasswordResetNotifier is an autogenerated mock type for the PasswordResetNotifier type
+type PasswordResetNotifier struct {
+	mock.Mock
+}
+
+// SendPasswordReset provides a mock function with given fields: ctx, user, token, expiresAt
+func (_m *PasswordResetNotifier) SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, user, token, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, string, time.Time) error); ok {
+		r0 = rf(ctx, user, token, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewPasswordResetNotifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPasswordResetNotifier creates a new instance of PasswordResetNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPasswordResetNotifier(t mockConstructorTestingTNewPasswordResetNotifier) *PasswordResetNotifier {
+	mock := &PasswordResetNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,10 +5,12 @@ package mocks
 import (
 	context "context"
 
-	uuid "github.com/google/uuid"
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	mock "github.com/stretchr/testify/mock"
 
-	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	time "time"
+
+	uuid "github.com/google/uuid"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -16,6 +18,104 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// AddTwoFactorAttempt provides a mock function with given fields: ctx, userUUID, limit, window
+func (_m *UserRepository) AddTwoFactorAttempt(ctx context.Context, userUUID uuid.UUID, limit int, window time.Duration) error {
+	ret := _m.Called(ctx, userUUID, limit, window)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int, time.Duration) error); ok {
+		r0 = rf(ctx, userUUID, limit, window)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AnonymizeUser provides a mock function with given fields: ctx, userUUID
+func (_m *UserRepository) AnonymizeUser(ctx context.Context, userUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumePasswordResetToken provides a mock function with given fields: ctx, tokenHash
+func (_m *UserRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) error {
+	ret := _m.Called(ctx, tokenHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumeRecoveryCode provides a mock function with given fields: ctx, userUUID, codeHash
+func (_m *UserRepository) ConsumeRecoveryCode(ctx context.Context, userUUID uuid.UUID, codeHash string) error {
+	ret := _m.Called(ctx, userUUID, codeHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, userUUID, codeHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, key
+func (_m *UserRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey) error {
+	ret := _m.Called(ctx, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.APIKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreatePasswordResetToken provides a mock function with given fields: ctx, token
+func (_m *UserRepository) CreatePasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PasswordResetToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *UserRepository) CreateSession(ctx context.Context, session *entity.Session) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Session) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateUser provides a mock function with given fields: ctx, user
 func (_m *UserRepository) CreateUser(ctx context.Context, user *entity.User) (*entity.User, error) {
 	ret := _m.Called(ctx, user)
@@ -42,6 +142,112 @@ func (_m *UserRepository) CreateUser(ctx context.Context, user *entity.User) (*e
 	return r0, r1
 }
 
+// DeleteSession provides a mock function with given fields: ctx, userUUID, sessionUUID
+func (_m *UserRepository) DeleteSession(ctx context.Context, userUUID uuid.UUID, sessionUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, sessionUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, sessionUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserSessions provides a mock function with given fields: ctx, userUUID, exceptSessionUUID
+func (_m *UserRepository) DeleteUserSessions(ctx context.Context, userUUID uuid.UUID, exceptSessionUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, exceptSessionUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, exceptSessionUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAPIKeyByHash provides a mock function with given fields: ctx, keyHash
+func (_m *UserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	var r0 *entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.APIKey, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.APIKey); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPasswordResetToken provides a mock function with given fields: ctx, tokenHash
+func (_m *UserRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	var r0 *entity.PasswordResetToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.PasswordResetToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.PasswordResetToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.PasswordResetToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSession provides a mock function with given fields: ctx, sessionUUID
+func (_m *UserRepository) GetSession(ctx context.Context, sessionUUID uuid.UUID) (*entity.Session, error) {
+	ret := _m.Called(ctx, sessionUUID)
+
+	var r0 *entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*entity.Session, error)); ok {
+		return rf(ctx, sessionUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *entity.Session); ok {
+		r0 = rf(ctx, sessionUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, sessionUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByLogin provides a mock function with given fields: ctx, login
 func (_m *UserRepository) GetUserByLogin(ctx context.Context, login string) (*entity.User, error) {
 	ret := _m.Called(ctx, login)
@@ -94,6 +300,184 @@ func (_m *UserRepository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID)
 	return r0, r1
 }
 
+// ListAPIKeys provides a mock function with given fields: ctx, userUUID
+func (_m *UserRepository) ListAPIKeys(ctx context.Context, userUUID uuid.UUID) ([]entity.APIKey, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.APIKey, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.APIKey); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSessions provides a mock function with given fields: ctx, userUUID
+func (_m *UserRepository) ListSessions(ctx context.Context, userUUID uuid.UUID) ([]entity.Session, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Session, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Session); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceRecoveryCodes provides a mock function with given fields: ctx, userUUID, codeHashes
+func (_m *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userUUID uuid.UUID, codeHashes []string) error {
+	ret := _m.Called(ctx, userUUID, codeHashes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []string) error); ok {
+		r0 = rf(ctx, userUUID, codeHashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetTwoFactorAttempts provides a mock function with given fields: ctx, userUUID
+func (_m *UserRepository) ResetTwoFactorAttempts(ctx context.Context, userUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, userUUID, keyUUID
+func (_m *UserRepository) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, keyUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, keyUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTOTP provides a mock function with given fields: ctx, userUUID, secret, enabled
+func (_m *UserRepository) SetTOTP(ctx context.Context, userUUID uuid.UUID, secret string, enabled bool) error {
+	ret := _m.Called(ctx, userUUID, secret, enabled)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, bool) error); ok {
+		r0 = rf(ctx, userUUID, secret, enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchAPIKey provides a mock function with given fields: ctx, keyUUID
+func (_m *UserRepository) TouchAPIKey(ctx context.Context, keyUUID uuid.UUID) error {
+	ret := _m.Called(ctx, keyUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, keyUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchSession provides a mock function with given fields: ctx, sessionUUID, ip, userAgent
+func (_m *UserRepository) TouchSession(ctx context.Context, sessionUUID uuid.UUID, ip string, userAgent string) error {
+	ret := _m.Called(ctx, sessionUUID, ip, userAgent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) error); ok {
+		r0 = rf(ctx, sessionUUID, ip, userAgent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, userUUID, passwordHash
+func (_m *UserRepository) UpdatePassword(ctx context.Context, userUUID uuid.UUID, passwordHash string) error {
+	ret := _m.Called(ctx, userUUID, passwordHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, userUUID, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserRole provides a mock function with given fields: ctx, userUUID, role
+func (_m *UserRepository) UpdateUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error {
+	ret := _m.Called(ctx, userUUID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.Role) error); ok {
+		r0 = rf(ctx, userUUID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseTOTPStep provides a mock function with given fields: ctx, userUUID, step
+func (_m *UserRepository) UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) error {
+	ret := _m.Called(ctx, userUUID, step)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
+		r0 = rf(ctx, userUUID, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewUserRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,194 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// WebhookRepository is an autogenerated mock type for the WebhookRepository type
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// AddDeliveries provides a mock function with given fields: ctx, deliveries
+func (_m *WebhookRepository) AddDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	ret := _m.Called(ctx, deliveries)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.WebhookDelivery) error); ok {
+		r0 = rf(ctx, deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimDueDeliveries provides a mock function with given fields: ctx, limit, lease
+func (_m *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	var r0 []entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]entity.WebhookDelivery, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *WebhookRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	ret := _m.Called(ctx, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, userUUID, webhookUUID
+func (_m *WebhookRepository) DeleteWebhook(ctx context.Context, userUUID uuid.UUID, webhookUUID uuid.UUID) error {
+	ret := _m.Called(ctx, userUUID, webhookUUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUUID, webhookUUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListDeliveries provides a mock function with given fields: ctx, userUUID, webhookUUID, limit
+func (_m *WebhookRepository) ListDeliveries(ctx context.Context, userUUID uuid.UUID, webhookUUID uuid.UUID, limit int) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, userUUID, webhookUUID, limit)
+
+	var r0 []entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int) ([]entity.WebhookDelivery, error)); ok {
+		return rf(ctx, userUUID, webhookUUID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, userUUID, webhookUUID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, userUUID, webhookUUID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListEventWebhooks provides a mock function with given fields: ctx, userUUID, eventType
+func (_m *WebhookRepository) ListEventWebhooks(ctx context.Context, userUUID uuid.UUID, eventType entity.EventType) ([]entity.Webhook, error) {
+	ret := _m.Called(ctx, userUUID, eventType)
+
+	var r0 []entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.EventType) ([]entity.Webhook, error)); ok {
+		return rf(ctx, userUUID, eventType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, entity.EventType) []entity.Webhook); ok {
+		r0 = rf(ctx, userUUID, eventType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, entity.EventType) error); ok {
+		r1 = rf(ctx, userUUID, eventType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhooks provides a mock function with given fields: ctx, userUUID
+func (_m *WebhookRepository) ListWebhooks(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error) {
+	ret := _m.Called(ctx, userUUID)
+
+	var r0 []entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]entity.Webhook, error)); ok {
+		return rf(ctx, userUUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entity.Webhook); ok {
+		r0 = rf(ctx, userUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, delivery
+func (_m *WebhookRepository) UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewWebhookRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookRepository(t mockConstructorTestingTNewWebhookRepository) *WebhookRepository {
+	mock := &WebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookSender is an autogenerated mock type for the WebhookSender type
+type WebhookSender struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, url, headers, body
+func (_m *WebhookSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	ret := _m.Called(ctx, url, headers, body)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string, []byte) (int, error)); ok {
+		return rf(ctx, url, headers, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string, []byte) int); ok {
+		r0 = rf(ctx, url, headers, body)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string, []byte) error); ok {
+		r1 = rf(ctx, url, headers, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookSender interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookSender creates a new instance of WebhookSender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookSender(t mockConstructorTestingTNewWebhookSender) *WebhookSender {
+	mock := &WebhookSender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,22 +16,47 @@ import (
 )
 
 // OrderRepository is an interface for orders repository.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderRepository
 type OrderRepository interface {
 	AddOrderForUser(ctx context.Context, order entity.Order) error
+	AddOrdersForUser(ctx context.Context, userUUID uuid.UUID, orders []entity.Order) (map[entity.OrderNumber]entity.OrderUploadResult, error)
 	GetAllUserOrders(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error)
+	GetUserOrdersPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error)
+	// UpdateOrderForUser returns ErrOrderStatusFinal when the order already has a final status.
 	UpdateOrderForUser(ctx context.Context, order entity.Order) error
+	ReassignUserOrders(ctx context.Context, fromUserUUID, toUserUUID uuid.UUID) error
+	GetUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, error)
+	GetOrderByNumber(ctx context.Context, number entity.OrderNumber) (entity.Order, error)
+	GetUnfinishedOrders(ctx context.Context) ([]entity.Order, error)
+	AddOrderStatusChange(ctx context.Context, change entity.OrderStatusChange) error
+	GetOrderStatusHistory(ctx context.Context, number entity.OrderNumber) ([]entity.OrderStatusChange, error)
+	RetractUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.OrderRetraction, error)
 }
 
+// OrderAccruer is an interface for crediting the accrual of a processed order.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderAccruer
+type OrderAccruer interface {
+	Execute(ctx context.Context, operation entity.BalanceOperation) error
+}
+
+// OrderClient is an interface for checking orders in an accrual system.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=OrderClient
 type OrderClient interface {
-	Check(ctx context.Context, number int) (entity.Order, error)
+	Check(ctx context.Context, number entity.OrderNumber) (entity.Order, error)
 }
 
 // OrderService is a service for managing orders.
 type OrderService struct {
 	repository OrderRepository
 	logger     *logger.Logger
-	client     OrderClient
+	providers  *ProviderRegistry
 	orderQueue chan entity.Order
+	publisher  EventPublisher
+	accruer    OrderAccruer
+	transactor Transactor
 }
 
 // NewOrderService returns a new order service.
@@ -37,16 +65,38 @@ func NewOrderService(logger *logger.Logger, orderQueue chan entity.Order, reposi
 		repository: repository,
 		logger:     logger,
 		orderQueue: orderQueue,
+		transactor: noTransaction{},
 	}
 }
 
-// SetClient sets the client for the order service.
+// SetClient sets the only accrual system of the order service, it takes all orders.
 func (s *OrderService) SetClient(client OrderClient) {
-	s.client = client
+	s.providers = NewProviderRegistry(AccrualProvider{Name: "default", Client: client})
+}
+
+// SetProviders sets the accrual systems the orders are routed to.
+func (s *OrderService) SetProviders(providers *ProviderRegistry) {
+	s.providers = providers
+}
+
+// SetPublisher sets the publisher of the order status events.
+func (s *OrderService) SetPublisher(publisher EventPublisher) {
+	s.publisher = publisher
+}
+
+// SetAccruer sets the balance service crediting the orders results received by push.
+func (s *OrderService) SetAccruer(accruer OrderAccruer) {
+	s.accruer = accruer
+}
+
+// SetTransactor sets the transactor storing the order and its first status atomically.
+func (s *OrderService) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
 }
 
 // Add adds a new order for a user.
-func (s *OrderService) Add(ctx context.Context, orderNumber int, userUUID uuid.UUID) error {
+// The store is optional, it routes the order to the accrual system of the store.
+func (s *OrderService) Add(ctx context.Context, orderNumber entity.OrderNumber, userUUID uuid.UUID, store string) error {
 	const op = "domain.services.OrderService.Add"
 	log := s.logger.With(
 		s.logger.StringField("op", op),
@@ -54,7 +104,19 @@ func (s *OrderService) Add(ctx context.Context, orderNumber int, userUUID uuid.U
 	)
 
 	order := entity.NewOrder(userUUID, orderNumber)
-	err := s.repository.AddOrderForUser(ctx, order)
+	order.Store = store
+	// the order is queued once it is committed with its history, so the worker never misses it
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.repository.AddOrderForUser(ctx, order)
+		if err != nil {
+			return err
+		}
+		return s.repository.AddOrderStatusChange(ctx, entity.OrderStatusChange{
+			OrderNumber: order.Number,
+			Status:      order.Status,
+			ChangedAt:   order.UploadedAt,
+		})
+	})
 	if err != nil {
 		return err
 	}
@@ -64,6 +126,83 @@ func (s *OrderService) Add(ctx context.Context, orderNumber int, userUUID uuid.U
 	return nil
 }
 
+// AddBatch adds new orders for a user and returns the upload result of every number.
+// The numbers must be already validated. The store is optional, like in Add it routes all orders of the batch.
+func (s *OrderService) AddBatch(ctx context.Context, orderNumbers []entity.OrderNumber, userUUID uuid.UUID, store string) (map[entity.OrderNumber]entity.OrderUploadResult, error) {
+	const op = "domain.services.OrderService.AddBatch"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	orders := make([]entity.Order, 0, len(orderNumbers))
+	seen := make(map[entity.OrderNumber]struct{}, len(orderNumbers))
+	for _, number := range orderNumbers {
+		if _, ok := seen[number]; ok {
+			continue
+		}
+		seen[number] = struct{}{}
+		order := entity.NewOrder(userUUID, number)
+		order.Store = store
+		orders = append(orders, order)
+	}
+
+	results, err := s.repository.AddOrdersForUser(ctx, userUUID, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := make([]entity.Order, 0, len(orders))
+	for _, order := range orders {
+		if results[order.Number] == entity.OrderUploadAccepted {
+			accepted = append(accepted, order)
+		}
+	}
+	// a batch may be larger than the queue buffer, the orders not queued until the request is done
+	// stay NEW and are queued again on the next start
+	queued := s.enqueue(ctx, accepted)
+	if queued < len(accepted) {
+		log.Info("Not all orders added to queue", log.AnyField("count", queued), log.AnyField("left", len(accepted)-queued))
+		return results, nil
+	}
+	log.Info("Orders added to queue", log.AnyField("count", queued))
+	return results, nil
+}
+
+// Requeue puts the NEW and PROCESSING orders back to the queue.
+// It is called on start, the orders queued before the last shutdown are lost with the queue.
+func (s *OrderService) Requeue(ctx context.Context) error {
+	const op = "domain.services.OrderService.Requeue"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	orders, err := s.repository.GetUnfinishedOrders(ctx)
+	if err != nil {
+		return err
+	}
+	queued := s.enqueue(ctx, orders)
+	if queued < len(orders) {
+		log.Info("Requeue stopped", log.AnyField("count", queued), log.AnyField("left", len(orders)-queued))
+		return nil
+	}
+	log.Info("Unfinished orders added to queue", log.AnyField("count", queued))
+	return nil
+}
+
+// enqueue puts the orders to the queue until the context is done and returns the number of queued orders.
+func (s *OrderService) enqueue(ctx context.Context, orders []entity.Order) int {
+	for i, order := range orders {
+		select {
+		case s.orderQueue <- order:
+		case <-ctx.Done():
+			return i
+		}
+	}
+	return len(orders)
+}
+
 // GetAll returns all orders for a user.
 func (s *OrderService) GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
 	orders, err := s.repository.GetAllUserOrders(ctx, userUUID)
@@ -73,8 +212,50 @@ func (s *OrderService) GetAll(ctx context.Context, userUUID uuid.UUID) ([]entity
 	return orders, nil
 }
 
-// Check return count bonuses for the order
-func (s *OrderService) Check(ctx context.Context, order entity.Order) (float64, error) {
+// GetPage returns a page of the user orders selected by the filter.
+func (s *OrderService) GetPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error) {
+	if filter.Limit <= 0 || filter.Limit > entity.OrdersPageMaxLimit {
+		filter.Limit = entity.OrdersPageMaxLimit
+	}
+	return s.repository.GetUserOrdersPage(ctx, userUUID, filter)
+}
+
+// GetWithHistory returns an order of a user and its status transitions.
+func (s *OrderService) GetWithHistory(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, []entity.OrderStatusChange, error) {
+	order, err := s.repository.GetUserOrder(ctx, userUUID, number)
+	if err != nil {
+		return entity.Order{}, nil, err
+	}
+	history, err := s.repository.GetOrderStatusHistory(ctx, number)
+	if err != nil {
+		return entity.Order{}, nil, err
+	}
+	return order, history, nil
+}
+
+// Retract removes a NEW or INVALID order of the user, the retraction is kept for audit.
+// A queued order is dropped by the worker once it finds the order gone.
+func (s *OrderService) Retract(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) error {
+	const op = "domain.services.OrderService.Retract"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.AnyField("order_number", number),
+		s.logger.AnyField("user_uuid", userUUID),
+	)
+
+	retraction, err := s.repository.RetractUserOrder(ctx, userUUID, number)
+	if err != nil {
+		return err
+	}
+	log.Info("Order retracted", log.AnyField("status", retraction.Status))
+	return nil
+}
+
+// Check polls the accrual system until the order is final and returns the stored order.
+// The order is read by number, so an order moved to a pseudonym with a deleted account keeps being checked,
+// and the returned order carries its current owner. A retracted order is returned empty.
+func (s *OrderService) Check(ctx context.Context, order entity.Order) (entity.Order, error) {
 	const op = "domain.services.OrderService.Check"
 	log := s.logger.With(
 		s.logger.StringField("op", op),
@@ -83,64 +264,249 @@ func (s *OrderService) Check(ctx context.Context, order entity.Order) (float64,
 		s.logger.AnyField("user_uuid", order.UserUUID),
 		s.logger.AnyField("uploaded_at", order.UploadedAt),
 	)
+	invalidResponses := 0
+	failures := 0
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info("Context is done")
-			return 0, ctx.Err()
+			return entity.Order{}, ctx.Err()
 		default:
-			externalOrder, err := s.client.Check(ctx, order.Number)
+			// the order may be retracted by the user, reassigned or its result pushed meanwhile, the stored order wins
+			current, err := s.repository.GetOrderByNumber(ctx, order.Number)
+			if err != nil {
+				if errors.Is(err, entity.ErrOrderNotFound) {
+					log.Info("Order is retracted")
+					return entity.Order{}, nil
+				}
+				return entity.Order{}, err
+			}
+			order = current
+			if order.Status.Final() {
+				return order, nil
+			}
+
+			provider, err := s.providers.Route(order)
 			if err != nil {
+				// the order waits in its status until a provider is configured
+				log.Error("No accrual provider for order", log.AnyField("store", order.Store))
+				return order, nil
+			}
+			externalOrder, err := provider.Client.Check(ctx, order.Number)
+			if err == nil {
+				err = validateExternal(order, externalOrder)
+			}
+			if err != nil {
+				if errors.Is(err, entity.ErrExternalResponseInvalid) {
+					invalidResponses++
+					if invalidResponses >= invalidResponsesLimit {
+						// the order keeps its status, a pushed result or a restart picks it up again
+						log.Error("Accrual system keeps sending invalid responses, order checking stopped",
+							log.StringField("provider", provider.Name),
+							log.ErrorField(err),
+						)
+						return order, nil
+					}
+					log.Error("Invalid accrual system response",
+						log.StringField("provider", provider.Name),
+						log.ErrorField(err),
+					)
+					err = sleep(ctx, retryBackoff(invalidResponses))
+					if err != nil {
+						return entity.Order{}, err
+					}
+					continue
+				}
+				if unavailable(ctx, err) {
+					failures++
+					if failures >= failuresLimit {
+						log.Error("Accrual system keeps failing, order checking stopped",
+							log.StringField("provider", provider.Name),
+							log.ErrorField(err),
+						)
+						return entity.Order{}, err
+					}
+					log.Error("Accrual system request failed",
+						log.StringField("provider", provider.Name),
+						log.AnyField("failures", failures),
+						log.ErrorField(err),
+					)
+					err = sleep(ctx, retryBackoff(failures))
+					if err != nil {
+						return entity.Order{}, err
+					}
+					continue
+				}
 				if errors.Is(err, entity.ErrExternalOrderRateLimitExceeded) {
-					time.Sleep(61 * time.Second)
+					pause := rateLimitPause
+					var rateLimitErr *entity.RateLimitError
+					if errors.As(err, &rateLimitErr) {
+						pause = rateLimitErr.RetryAfter
+					}
+					log.Info("Accrual system rate limit exceeded",
+						log.StringField("provider", provider.Name),
+						log.AnyField("retry_after", pause),
+					)
+					err = sleep(ctx, pause)
+					if err != nil {
+						return entity.Order{}, err
+					}
 					continue
 				}
 				if errors.Is(err, entity.ErrExternalOrderNotRegistered) {
-					time.Sleep(100 * time.Millisecond)
+					err = sleep(ctx, pollPause)
+					if err != nil {
+						return entity.Order{}, err
+					}
 					continue
 				}
-				return 0, err
-			}
-			if externalOrder.Status == entity.OrderRegistered && order.Status != entity.OrderProcessing {
-				log.Info("Order is processing")
-				order.Status = entity.OrderProcessing
-				err := s.Update(ctx, order)
-				if err != nil {
-					return 0, err
-				}
-				continue
+				return entity.Order{}, err
 			}
-			if externalOrder.Status == entity.OrderProcessing && externalOrder.Status != order.Status {
-				log.Info("Order is processing")
-				order.Status = entity.OrderProcessing
-				err := s.Update(ctx, order)
-				if err != nil {
-					return 0, err
+			invalidResponses = 0
+			failures = 0
+			next := transition(order, externalOrder)
+			if next != order {
+				log.Info("Order status received",
+					log.AnyField("status", next.Status),
+					log.StringField("provider", provider.Name),
+				)
+				err = s.Update(ctx, next)
+				// the order is retracted, reassigned or its result pushed meanwhile, the stored order is read again
+				if err != nil && !errors.Is(err, entity.ErrOrderNotFound) && !errors.Is(err, entity.ErrOrderStatusFinal) {
+					return entity.Order{}, err
 				}
 				continue
 			}
-			if externalOrder.Status == entity.OrderProcessed {
-				log.Info("Order processed")
-				order.Status = entity.OrderProcessed
-				order.Accrual = externalOrder.Accrual
-				err = s.Update(ctx, order)
-				if err != nil {
-					return 0, err
-				}
-				return order.Accrual, nil
+			err = sleep(ctx, pollPause)
+			if err != nil {
+				return entity.Order{}, err
 			}
-			if externalOrder.Status == entity.OrderInvalid {
-				log.Info("Order invalid")
-				order.Status = entity.OrderInvalid
-				err = s.Update(ctx, order)
+		}
+	}
+}
+
+// invalidResponsesLimit is the number of invalid accrual system responses in a row the order checking gives up after.
+const invalidResponsesLimit = 10
+
+// failuresLimit is the number of failed accrual system requests in a row the order checking gives up after.
+const failuresLimit = 10
+
+// pollPause is the pause before the order is checked again in the same status.
+const pollPause = 100 * time.Millisecond
+
+// unavailable reports whether the accrual system request failed for a reason that passes:
+// a server error or a timeout of the request, while the order checking itself is not done.
+func unavailable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, entity.ErrExternalUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// rateLimitPause is the pause after a rate limit that does not tell when to retry.
+const rateLimitPause = time.Minute
+
+// sleep pauses for the duration and returns the context error when the context is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryBackoff returns the pause after the invalid response or the failed request of the number in a row,
+// it doubles from a second up to a minute.
+func retryBackoff(attempt int) time.Duration {
+	backoff := time.Second << (attempt - 1)
+	if backoff > time.Minute || backoff <= 0 {
+		return time.Minute
+	}
+	return backoff
+}
+
+// validateExternal checks the order reported by the accrual system, whichever client reported it.
+func validateExternal(order entity.Order, external entity.Order) error {
+	if external.Number != order.Number {
+		return fmt.Errorf("%w: %w", entity.ErrExternalResponseInvalid, entity.ErrExternalOrderMismatch)
+	}
+	if !external.Status.External() {
+		return fmt.Errorf("%w: %w", entity.ErrExternalResponseInvalid, entity.ErrExternalStatusUnknown)
+	}
+	if external.Accrual < 0 || math.IsNaN(external.Accrual) || math.IsInf(external.Accrual, 0) {
+		return fmt.Errorf("%w: %w", entity.ErrExternalResponseInvalid, entity.ErrExternalAccrualInvalid)
+	}
+	return nil
+}
+
+// transition returns the order moved to the state reported by the accrual system.
+// Unknown external statuses leave the order as it is.
+func transition(order entity.Order, external entity.Order) entity.Order {
+	switch external.Status {
+	case entity.OrderRegistered, entity.OrderProcessing:
+		order.Status = entity.OrderProcessing
+	case entity.OrderProcessed:
+		order.Status = entity.OrderProcessed
+		order.Accrual = external.Accrual
+	case entity.OrderInvalid:
+		order.Status = entity.OrderInvalid
+	}
+	return order
+}
+
+// Receive applies an order result pushed by the accrual system and credits the accrual.
+// It is idempotent: a repeated result of a final order changes nothing, the accrual is executed once.
+func (s *OrderService) Receive(ctx context.Context, external entity.Order) (entity.Order, error) {
+	const op = "domain.services.OrderService.Receive"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.AnyField("order_number", external.Number),
+		s.logger.AnyField("status", external.Status),
+	)
+
+	order, err := s.repository.GetOrderByNumber(ctx, external.Number)
+	if err != nil {
+		return entity.Order{}, err
+	}
+
+	if !order.Status.Final() {
+		next := transition(order, external)
+		if next != order {
+			err = s.Update(ctx, next)
+			switch {
+			case err == nil:
+				order = next
+			case errors.Is(err, entity.ErrOrderStatusFinal):
+				// polling finished the order meanwhile, its stored result wins
+				order, err = s.repository.GetOrderByNumber(ctx, external.Number)
 				if err != nil {
-					return 0, err
+					return entity.Order{}, err
 				}
-				return 0, nil
+			default:
+				return entity.Order{}, err
 			}
-			time.Sleep(100 * time.Millisecond)
 		}
 	}
+
+	// a processed order is credited again, the balance keeps one accrual per order,
+	// so a result pushed again after a failed accrual is not lost
+	if order.Status == entity.OrderProcessed && order.Accrual > 0 && s.accruer != nil {
+		err = s.accruer.Execute(ctx, entity.NewBalanceOperation(order.UserUUID, order.Accrual, 0, order.Number))
+		if err != nil {
+			return entity.Order{}, err
+		}
+	}
+
+	log.Info("Order result received", log.AnyField("current_status", order.Status))
+	return order, nil
 }
 
 func (s *OrderService) Update(ctx context.Context, order entity.Order) error {
@@ -154,10 +520,46 @@ func (s *OrderService) Update(ctx context.Context, order entity.Order) error {
 		s.logger.AnyField("status", order.Status),
 	)
 
-	err := s.repository.UpdateOrderForUser(ctx, order)
+	current, err := s.repository.GetUserOrder(ctx, order.UserUUID, order.Number)
 	if err != nil {
 		return err
 	}
+
+	// results come both from polling and from push, the repository never changes a final status,
+	// so of two concurrent results only the first one is stored
+	err = s.repository.UpdateOrderForUser(ctx, order)
+	if err != nil {
+		if errors.Is(err, entity.ErrOrderStatusFinal) {
+			log.Info("Order status is already final")
+		}
+		return err
+	}
+
+	if current.Status != order.Status {
+		err = s.repository.AddOrderStatusChange(ctx, entity.OrderStatusChange{
+			OrderNumber: order.Number,
+			Status:      order.Status,
+			Accrual:     order.Accrual,
+			ChangedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		log.Info("Order status changed", log.AnyField("previous_status", current.Status))
+		if s.publisher != nil {
+			s.publisher.Publish(ctx, entity.Event{
+				UserUUID: order.UserUUID,
+				Type:     entity.EventOrderStatus,
+				Order:    &order,
+			})
+		}
+	}
 	log.Info("Order updated")
 	return nil
 }
+
+// Pseudonymize moves all orders of a deleted user to the pseudonym, so they are kept for accounting
+// but cannot be linked back to the user.
+func (s *OrderService) Pseudonymize(ctx context.Context, userUUID, pseudonym uuid.UUID) error {
+	return s.repository.ReassignUserOrders(ctx, userUUID, pseudonym)
+}
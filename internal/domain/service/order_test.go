@@ -0,0 +1,538 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestOrderService_Add(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("AddOrderForUser", mock.Anything, mock.AnythingOfType("entity.Order")).
+		Return(nil).
+		Once()
+	repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.MatchedBy(func(change entity.OrderStatusChange) bool {
+		return change.OrderNumber == "12345678903" && change.Status == entity.OrderNew
+	})).
+		Return(nil).
+		Once()
+	queue := make(chan entity.Order, 1)
+	s := service.NewOrderService(logger.NewLogger(), queue, repositoryMock)
+
+	err := s.Add(ctx, "12345678903", userUUID, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.OrderNumber("12345678903"), (<-queue).Number)
+}
+
+func TestOrderService_Add_Transaction(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	type txKey struct{}
+	inTransaction := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+
+	transactorMock := mocks.NewTransactor(t)
+	transactorMock.On("WithinTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		}).
+		Once()
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("AddOrderForUser", inTransaction, mock.AnythingOfType("entity.Order")).
+		Return(nil).
+		Once()
+	repositoryMock.On("AddOrderStatusChange", inTransaction, mock.AnythingOfType("entity.OrderStatusChange")).
+		Return(errors.New("db error")).
+		Once()
+	queue := make(chan entity.Order, 1)
+	s := service.NewOrderService(logger.NewLogger(), queue, repositoryMock)
+	s.SetTransactor(transactorMock)
+
+	err := s.Add(ctx, "12345678903", uuid.New(), "")
+
+	// the order rolled back with its history is not queued
+	assert.Error(t, err)
+	assert.Empty(t, queue)
+}
+
+func TestOrderService_Update(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		currentStatus entity.Status
+		newStatus     entity.Status
+		wantHistory   bool
+	}{
+		{
+			name:          "Status changed: transition recorded",
+			currentStatus: entity.OrderProcessing,
+			newStatus:     entity.OrderProcessed,
+			wantHistory:   true,
+		},
+		{
+			name:          "Status not changed: no transition",
+			currentStatus: entity.OrderProcessing,
+			newStatus:     entity.OrderProcessing,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			current := entity.NewOrder(userUUID, "12345678903")
+			current.Status = tc.currentStatus
+			order := current
+			order.Status = tc.newStatus
+			order.Accrual = 500
+
+			publisherMock := mocks.NewEventPublisher(t)
+			repositoryMock := mocks.NewOrderRepository(t)
+			repositoryMock.On("GetUserOrder", mock.Anything, userUUID, order.Number).
+				Return(current, nil).
+				Once()
+			repositoryMock.On("UpdateOrderForUser", mock.Anything, order).
+				Return(nil).
+				Once()
+			if tc.wantHistory {
+				repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.MatchedBy(func(change entity.OrderStatusChange) bool {
+					return change.Status == tc.newStatus && change.Accrual == 500
+				})).
+					Return(nil).
+					Once()
+				publisherMock.On("Publish", mock.Anything, mock.MatchedBy(func(event entity.Event) bool {
+					return event.Type == entity.EventOrderStatus && event.UserUUID == userUUID && event.Order.Status == tc.newStatus
+				})).
+					Once()
+			}
+			s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+			s.SetPublisher(publisherMock)
+
+			err := s.Update(ctx, order)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOrderService_GetPage(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetUserOrdersPage", mock.Anything, userUUID, entity.OrderFilter{Limit: entity.OrdersPageMaxLimit}).
+		Return(entity.OrderPage{Total: 0}, nil).
+		Twice()
+	s := service.NewOrderService(logger.NewLogger(), make(chan entity.Order), repositoryMock)
+
+	_, err := s.GetPage(ctx, userUUID, entity.OrderFilter{})
+	assert.NoError(t, err)
+	_, err = s.GetPage(ctx, userUUID, entity.OrderFilter{Limit: entity.OrdersPageMaxLimit + 1})
+	assert.NoError(t, err)
+}
+
+func TestOrderService_AddBatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("AddOrdersForUser", mock.Anything, userUUID, mock.MatchedBy(func(orders []entity.Order) bool {
+		// duplicates in the batch are uploaded once, every order carries the store
+		return len(orders) == 2 && orders[0].Number == "12345678903" && orders[1].Number == "79927398713" &&
+			orders[0].Store == "shop" && orders[1].Store == "shop"
+	})).
+		Return(map[entity.OrderNumber]entity.OrderUploadResult{
+			"12345678903": entity.OrderUploadAccepted,
+			"79927398713": entity.OrderUploadOwnedByAnotherUser,
+		}, nil).
+		Once()
+	queue := make(chan entity.Order, 2)
+	s := service.NewOrderService(logger.NewLogger(), queue, repositoryMock)
+
+	results, err := s.AddBatch(ctx, []entity.OrderNumber{"12345678903", "79927398713", "12345678903"}, userUUID, "shop")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.OrderUploadAccepted, results["12345678903"])
+	assert.Equal(t, entity.OrderNumber("12345678903"), (<-queue).Number)
+}
+
+func TestOrderService_AddBatch_QueueFull(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), contexter.RequestID, "req"), 50*time.Millisecond)
+	defer cancel()
+	userUUID := uuid.New()
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("AddOrdersForUser", mock.Anything, userUUID, mock.Anything).
+		Return(map[entity.OrderNumber]entity.OrderUploadResult{
+			"12345678903": entity.OrderUploadAccepted,
+			"79927398713": entity.OrderUploadAccepted,
+		}, nil).
+		Once()
+	queue := make(chan entity.Order, 1)
+	s := service.NewOrderService(logger.NewLogger(), queue, repositoryMock)
+
+	// the order not queued before the request is done stays NEW for the requeue on start
+	results, err := s.AddBatch(ctx, []entity.OrderNumber{"12345678903", "79927398713"}, userUUID, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Len(t, queue, 1)
+	assert.Equal(t, entity.OrderNumber("12345678903"), (<-queue).Number)
+}
+
+func TestOrderService_Requeue(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	orders := []entity.Order{
+		{Number: "12345678903", Status: entity.OrderNew},
+		{Number: "79927398713", Status: entity.OrderProcessing},
+	}
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetUnfinishedOrders", mock.Anything).Return(orders, nil).Twice()
+	queue := make(chan entity.Order, 2)
+	s := service.NewOrderService(logger.NewLogger(), queue, repositoryMock)
+
+	assert.NoError(t, s.Requeue(ctx))
+	assert.Equal(t, orders[0].Number, (<-queue).Number)
+	assert.Equal(t, orders[1].Number, (<-queue).Number)
+
+	// a stopped service does not block on a full queue
+	queue <- entity.Order{}
+	queue <- entity.Order{}
+	stopped, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.NoError(t, s.Requeue(stopped))
+}
+
+func TestOrderService_Receive(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		currentStatus entity.Status
+		external      entity.Order
+		wantStatus    entity.Status
+		wantUpdate    bool
+		wantAccrual   bool
+	}{
+		{
+			name:          "Processed result: status changed and accrual executed",
+			currentStatus: entity.OrderProcessing,
+			external:      entity.Order{Number: "12345678903", Status: entity.OrderProcessed, Accrual: 500},
+			wantStatus:    entity.OrderProcessed,
+			wantUpdate:    true,
+			wantAccrual:   true,
+		},
+		{
+			name:          "Repeated processed result: accrual executed again idempotently",
+			currentStatus: entity.OrderProcessed,
+			external:      entity.Order{Number: "12345678903", Status: entity.OrderProcessed, Accrual: 500},
+			wantStatus:    entity.OrderProcessed,
+			wantAccrual:   true,
+		},
+		{
+			name:          "Registered result: order is processing",
+			currentStatus: entity.OrderNew,
+			external:      entity.Order{Number: "12345678903", Status: entity.OrderRegistered},
+			wantStatus:    entity.OrderProcessing,
+			wantUpdate:    true,
+		},
+		{
+			name:          "Result after invalid: final status kept",
+			currentStatus: entity.OrderInvalid,
+			external:      entity.Order{Number: "12345678903", Status: entity.OrderProcessing},
+			wantStatus:    entity.OrderInvalid,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			current := entity.NewOrder(userUUID, "12345678903")
+			current.Status = tc.currentStatus
+			if tc.currentStatus == entity.OrderProcessed {
+				current.Accrual = 500
+			}
+
+			repositoryMock := mocks.NewOrderRepository(t)
+			repositoryMock.On("GetOrderByNumber", mock.Anything, entity.OrderNumber("12345678903")).
+				Return(current, nil).
+				Once()
+			if tc.wantUpdate {
+				repositoryMock.On("GetUserOrder", mock.Anything, userUUID, entity.OrderNumber("12345678903")).
+					Return(current, nil).
+					Once()
+				repositoryMock.On("UpdateOrderForUser", mock.Anything, mock.MatchedBy(func(order entity.Order) bool {
+					return order.Status == tc.wantStatus
+				})).
+					Return(nil).
+					Once()
+				repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.AnythingOfType("entity.OrderStatusChange")).
+					Return(nil).
+					Once()
+			}
+			accruerMock := mocks.NewOrderAccruer(t)
+			if tc.wantAccrual {
+				accruerMock.On("Execute", mock.Anything, mock.MatchedBy(func(operation entity.BalanceOperation) bool {
+					return operation.UserUUID == userUUID && operation.Accrual == 500 && operation.OrderNumber == "12345678903"
+				})).
+					Return(nil).
+					Once()
+			}
+			s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+			s.SetAccruer(accruerMock)
+
+			order, err := s.Receive(ctx, tc.external)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, order.Status)
+		})
+	}
+}
+
+func TestOrderService_Receive_FinalMeanwhile(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	current := entity.NewOrder(uuid.New(), "12345678903")
+	current.Status = entity.OrderProcessing
+	invalid := current
+	invalid.Status = entity.OrderInvalid
+
+	// polling stores the invalid status between reading the order and updating it,
+	// the pushed result is dropped and nothing is credited
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetOrderByNumber", mock.Anything, current.Number).Return(current, nil).Once()
+	repositoryMock.On("GetUserOrder", mock.Anything, current.UserUUID, current.Number).Return(current, nil).Once()
+	repositoryMock.On("UpdateOrderForUser", mock.Anything, mock.AnythingOfType("entity.Order")).
+		Return(entity.ErrOrderStatusFinal).
+		Once()
+	repositoryMock.On("GetOrderByNumber", mock.Anything, current.Number).Return(invalid, nil).Once()
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+	s.SetAccruer(mocks.NewOrderAccruer(t))
+
+	order, err := s.Receive(ctx, entity.Order{Number: current.Number, Status: entity.OrderProcessed, Accrual: 500})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.OrderInvalid, order.Status)
+}
+
+func TestOrderService_Retract(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("RetractUserOrder", mock.Anything, userUUID, entity.OrderNumber("12345678903")).
+		Return(entity.OrderRetraction{OrderNumber: "12345678903", UserUUID: userUUID, Status: entity.OrderNew}, nil).
+		Once()
+	repositoryMock.On("RetractUserOrder", mock.Anything, userUUID, entity.OrderNumber("79927398713")).
+		Return(entity.OrderRetraction{}, entity.ErrOrderNotRetractable).
+		Once()
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+
+	assert.NoError(t, s.Retract(ctx, userUUID, "12345678903"))
+	assert.ErrorIs(t, s.Retract(ctx, userUUID, "79927398713"), entity.ErrOrderNotRetractable)
+}
+
+func TestOrderService_Check_Retracted(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	order := entity.NewOrder(uuid.New(), "12345678903")
+
+	// a retracted order is dropped from processing without asking the accrual system
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).
+		Return(entity.Order{}, entity.ErrOrderNotFound).
+		Once()
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+
+	checked, err := s.Check(ctx, order)
+
+	assert.NoError(t, err)
+	assert.Zero(t, checked)
+}
+
+func TestOrderService_Check_Reassigned(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	order := entity.NewOrder(uuid.New(), "12345678903")
+	reassigned := order
+	reassigned.UserUUID = uuid.New()
+	processed := reassigned
+	processed.Status = entity.OrderProcessed
+	processed.Accrual = 500
+
+	// the account is deleted while the order is queued, the order is checked under the pseudonym
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(reassigned, nil).Once()
+	repositoryMock.On("GetUserOrder", mock.Anything, reassigned.UserUUID, order.Number).Return(reassigned, nil).Once()
+	repositoryMock.On("UpdateOrderForUser", mock.Anything, processed).Return(nil).Once()
+	repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.AnythingOfType("entity.OrderStatusChange")).Return(nil).Once()
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(processed, nil).Once()
+	clientMock := mocks.NewOrderClient(t)
+	clientMock.On("Check", mock.Anything, order.Number).
+		Return(entity.Order{Number: order.Number, Status: entity.OrderProcessed, Accrual: 500}, nil).
+		Once()
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+	s.SetClient(clientMock)
+
+	checked, err := s.Check(ctx, order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, processed, checked)
+}
+
+func TestOrderService_Check_InvalidResponse(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	order := entity.NewOrder(uuid.New(), "12345678903")
+	processed := order
+	processed.Status = entity.OrderProcessed
+	processed.Accrual = 500
+
+	// an unknown status is not taken, the order is checked again after a pause
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(order, nil).Twice()
+	repositoryMock.On("GetUserOrder", mock.Anything, order.UserUUID, order.Number).Return(order, nil).Once()
+	repositoryMock.On("UpdateOrderForUser", mock.Anything, processed).Return(nil).Once()
+	repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.AnythingOfType("entity.OrderStatusChange")).Return(nil).Once()
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(processed, nil).Once()
+
+	clientMock := mocks.NewOrderClient(t)
+	clientMock.On("Check", mock.Anything, order.Number).
+		Return(entity.Order{Number: order.Number, Status: "DONE"}, nil).
+		Once()
+	clientMock.On("Check", mock.Anything, order.Number).
+		Return(entity.Order{Number: order.Number, Status: entity.OrderProcessed, Accrual: 500}, nil).
+		Once()
+
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+	s.SetClient(clientMock)
+
+	checked, err := s.Check(ctx, order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 500.0, checked.Accrual)
+}
+
+func TestOrderService_Check_RateLimit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	order := entity.NewOrder(uuid.New(), "12345678903")
+	invalid := order
+	invalid.Status = entity.OrderInvalid
+
+	// the pause asked by the accrual system is taken instead of a fixed one
+	repositoryMock := mocks.NewOrderRepository(t)
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(order, nil).Twice()
+	repositoryMock.On("GetUserOrder", mock.Anything, order.UserUUID, order.Number).Return(order, nil).Once()
+	repositoryMock.On("UpdateOrderForUser", mock.Anything, invalid).Return(nil).Once()
+	repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.AnythingOfType("entity.OrderStatusChange")).Return(nil).Once()
+	repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(invalid, nil).Once()
+	clientMock := mocks.NewOrderClient(t)
+	clientMock.On("Check", mock.Anything, order.Number).
+		Return(entity.Order{}, &entity.RateLimitError{RetryAfter: 10 * time.Millisecond}).
+		Once()
+	clientMock.On("Check", mock.Anything, order.Number).
+		Return(entity.Order{Number: order.Number, Status: entity.OrderInvalid}, nil).
+		Once()
+	s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+	s.SetClient(clientMock)
+
+	start := time.Now()
+	checked, err := s.Check(ctx, order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.OrderInvalid, checked.Status)
+	assert.Less(t, time.Since(start), time.Second)
+
+	t.Run("Context done during the pause", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		repositoryMock := mocks.NewOrderRepository(t)
+		repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(order, nil).Once()
+		clientMock := mocks.NewOrderClient(t)
+		clientMock.On("Check", mock.Anything, order.Number).
+			Return(entity.Order{}, &entity.RateLimitError{RetryAfter: time.Hour}).
+			Once()
+		s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+		s.SetClient(clientMock)
+
+		_, err := s.Check(ctx, order)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestOrderService_Check_Unavailable(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "Server error", err: fmt.Errorf("%w: status code 503", entity.ErrExternalUnavailable)},
+		{name: "Request deadline", err: context.DeadlineExceeded},
+		{name: "Network timeout", err: timeoutError{}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			order := entity.NewOrder(uuid.New(), "12345678903")
+			invalid := order
+			invalid.Status = entity.OrderInvalid
+
+			// the failed request is retried after a pause instead of ending the order checking
+			repositoryMock := mocks.NewOrderRepository(t)
+			repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(order, nil).Twice()
+			repositoryMock.On("GetUserOrder", mock.Anything, order.UserUUID, order.Number).Return(order, nil).Once()
+			repositoryMock.On("UpdateOrderForUser", mock.Anything, invalid).Return(nil).Once()
+			repositoryMock.On("AddOrderStatusChange", mock.Anything, mock.AnythingOfType("entity.OrderStatusChange")).Return(nil).Once()
+			repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(invalid, nil).Once()
+			clientMock := mocks.NewOrderClient(t)
+			clientMock.On("Check", mock.Anything, order.Number).Return(entity.Order{}, tc.err).Once()
+			clientMock.On("Check", mock.Anything, order.Number).
+				Return(entity.Order{Number: order.Number, Status: entity.OrderInvalid}, nil).
+				Once()
+			s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+			s.SetClient(clientMock)
+
+			checked, err := s.Check(ctx, order)
+
+			assert.NoError(t, err)
+			assert.Equal(t, entity.OrderInvalid, checked.Status)
+		})
+	}
+
+	t.Run("Other errors end the order checking", func(t *testing.T) {
+		t.Parallel()
+		order := entity.NewOrder(uuid.New(), "12345678903")
+		repositoryMock := mocks.NewOrderRepository(t)
+		repositoryMock.On("GetOrderByNumber", mock.Anything, order.Number).Return(order, nil).Once()
+		clientMock := mocks.NewOrderClient(t)
+		clientMock.On("Check", mock.Anything, order.Number).Return(entity.Order{}, errors.New("unexpected status code: 404")).Once()
+		s := service.NewOrderService(logger.NewLogger(), nil, repositoryMock)
+		s.SetClient(clientMock)
+
+		_, err := s.Check(ctx, order)
+
+		assert.Error(t, err)
+	})
+}
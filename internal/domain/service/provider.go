@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+// AccrualProvider is an accrual system of a partner and the orders routed to it.
+// A provider without prefixes and stores is a fallback, it takes the orders no other provider takes.
+type AccrualProvider struct {
+	Name     string
+	Prefixes []string
+	Stores   []string
+	Client   OrderClient
+}
+
+// Fallback reports whether the provider takes the orders not routed anywhere else.
+func (p AccrualProvider) Fallback() bool {
+	return len(p.Prefixes) == 0 && len(p.Stores) == 0
+}
+
+// ProviderRegistry routes orders to the accrual providers.
+type ProviderRegistry struct {
+	providers []AccrualProvider
+}
+
+// NewProviderRegistry returns a registry of the providers, the order of the providers breaks ties.
+func NewProviderRegistry(providers ...AccrualProvider) *ProviderRegistry {
+	return &ProviderRegistry{providers: providers}
+}
+
+// Route returns the provider of the order. The provider of the order store wins,
+// then the provider with the longest matching number prefix, then the first fallback.
+func (r *ProviderRegistry) Route(order entity.Order) (AccrualProvider, error) {
+	if order.Store != "" {
+		for _, provider := range r.providers {
+			for _, store := range provider.Stores {
+				if store == order.Store {
+					return provider, nil
+				}
+			}
+		}
+	}
+
+	best, bestLength := -1, 0
+	for i, provider := range r.providers {
+		for _, prefix := range provider.Prefixes {
+			if len(prefix) > bestLength && strings.HasPrefix(string(order.Number), prefix) {
+				best, bestLength = i, len(prefix)
+			}
+		}
+	}
+	if best >= 0 {
+		return r.providers[best], nil
+	}
+
+	for _, provider := range r.providers {
+		if provider.Fallback() {
+			return provider, nil
+		}
+	}
+	return AccrualProvider{}, entity.ErrAccrualProviderNotFound
+}
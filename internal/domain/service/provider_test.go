@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+)
+
+func TestProviderRegistry_Route(t *testing.T) {
+	registry := service.NewProviderRegistry(
+		service.AccrualProvider{Name: "default"},
+		service.AccrualProvider{Name: "partner", Prefixes: []string{"42"}},
+		service.AccrualProvider{Name: "partner-long", Prefixes: []string{"4242"}},
+		service.AccrualProvider{Name: "shop", Stores: []string{"shop-1"}},
+	)
+
+	tests := []struct {
+		name     string
+		order    entity.Order
+		provider string
+	}{
+		{name: "Fallback", order: entity.Order{Number: "12345678903"}, provider: "default"},
+		{name: "Prefix", order: entity.Order{Number: "4212345678"}, provider: "partner"},
+		{name: "Longest prefix", order: entity.Order{Number: "4242345678"}, provider: "partner-long"},
+		{name: "Store wins", order: entity.Order{Number: "4242345678", Store: "shop-1"}, provider: "shop"},
+		{name: "Unknown store", order: entity.Order{Number: "4212345678", Store: "shop-2"}, provider: "partner"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			provider, err := registry.Route(tc.order)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.provider, provider.Name)
+		})
+	}
+
+	_, err := service.NewProviderRegistry(service.AccrualProvider{Name: "partner", Prefixes: []string{"42"}}).
+		Route(entity.Order{Number: "12345678903"})
+	assert.ErrorIs(t, err, entity.ErrAccrualProviderNotFound)
+}
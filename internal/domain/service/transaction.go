@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+)
+
+// Transactor is an interface for running several repository calls atomically.
+// The transaction is carried in the context passed to the function, the repository calls made
+// with that context take part in it. The transaction is committed when the function returns nil
+// and rolled back when it returns an error.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// noTransaction runs the function as is, the services use it until a transactor is set.
+type noTransaction struct{}
+
+// WithinTransaction calls the function with the context.
+func (noTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -11,6 +12,18 @@ import (
 	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
 	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/totp"
+)
+
+const (
+	// totpIssuer is the issuer name shown in authenticator apps.
+	totpIssuer = "Gophermart"
+	// recoveryCodesCount is the number of recovery codes issued when two-factor authentication is enabled.
+	recoveryCodesCount = 10
+	// twoFactorMaxAttempts is the number of second factor attempts allowed in twoFactorAttemptsWindow.
+	twoFactorMaxAttempts = 5
+	// twoFactorAttemptsWindow is the period the second factor attempts are counted in, a success resets the count.
+	twoFactorAttemptsWindow = 15 * time.Minute
 )
 
 // UserRepository is an interface for user repository.
@@ -20,6 +33,29 @@ type UserRepository interface {
 	GetUserByLogin(ctx context.Context, login string) (*entity.User, error)
 	CreateUser(ctx context.Context, user *entity.User) (*entity.User, error)
 	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (*entity.User, error)
+	UpdatePassword(ctx context.Context, userUUID uuid.UUID, passwordHash string) error
+	CreateSession(ctx context.Context, session *entity.Session) error
+	GetSession(ctx context.Context, sessionUUID uuid.UUID) (*entity.Session, error)
+	ListSessions(ctx context.Context, userUUID uuid.UUID) ([]entity.Session, error)
+	TouchSession(ctx context.Context, sessionUUID uuid.UUID, ip, userAgent string) error
+	DeleteSession(ctx context.Context, userUUID uuid.UUID, sessionUUID uuid.UUID) error
+	DeleteUserSessions(ctx context.Context, userUUID uuid.UUID, exceptSessionUUID uuid.UUID) error
+	CreatePasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) error
+	SetTOTP(ctx context.Context, userUUID uuid.UUID, secret string, enabled bool) error
+	ReplaceRecoveryCodes(ctx context.Context, userUUID uuid.UUID, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userUUID uuid.UUID, codeHash string) error
+	AddTwoFactorAttempt(ctx context.Context, userUUID uuid.UUID, limit int, window time.Duration) error
+	ResetTwoFactorAttempts(ctx context.Context, userUUID uuid.UUID) error
+	UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) error
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListAPIKeys(ctx context.Context, userUUID uuid.UUID) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error
+	TouchAPIKey(ctx context.Context, keyUUID uuid.UUID) error
+	UpdateUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error
+	AnonymizeUser(ctx context.Context, userUUID uuid.UUID) error
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=BalanceCreator
@@ -27,24 +63,53 @@ type BalanceCreator interface {
 	CreateBalanceForUser(ctx context.Context, userUUID uuid.UUID) error
 }
 
+// PasswordValidator is an interface for password policy.
+type PasswordValidator interface {
+	Validate(login, password string) error
+}
+
+// PasswordResetNotifier is an interface for delivering password reset tokens to users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=PasswordResetNotifier
+type PasswordResetNotifier interface {
+	SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error
+}
+
 // UserService is a service for managing users.
 type UserService struct {
 	repository     UserRepository
 	secretKey      string
 	logger         *logger.Logger
 	balanceService BalanceCreator
+	passwordPolicy PasswordValidator
+	resetNotifier  PasswordResetNotifier
+	resetTTL       time.Duration
+	transactor     Transactor
 }
 
 // NewUserService returns a new user service.
-func NewUserService(repository UserRepository, balanceService BalanceCreator, logger *logger.Logger, secretKey string) *UserService {
+func NewUserService(repository UserRepository, balanceService BalanceCreator, passwordPolicy PasswordValidator, logger *logger.Logger, secretKey string) *UserService {
 	return &UserService{
 		repository:     repository,
 		secretKey:      secretKey,
 		logger:         logger,
 		balanceService: balanceService,
+		passwordPolicy: passwordPolicy,
+		transactor:     noTransaction{},
 	}
 }
 
+// SetTransactor sets the transactor creating the user and the balance atomically.
+func (s *UserService) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
+}
+
+// SetPasswordResetNotifier sets the delivery of password reset tokens and their lifetime.
+func (s *UserService) SetPasswordResetNotifier(notifier PasswordResetNotifier, ttl time.Duration) {
+	s.resetNotifier = notifier
+	s.resetTTL = ttl
+}
+
 // Register register a new user.
 func (s *UserService) Register(ctx context.Context, login, password string) (string, error) {
 	const op = "domain.services.UserService.Registration"
@@ -53,35 +118,32 @@ func (s *UserService) Register(ctx context.Context, login, password string) (str
 		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
 	)
 
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	err := s.passwordPolicy.Validate(login, password)
 	if err != nil {
-		log.Error("Did not generate password hash", log.ErrorField(err))
+		log.Info("Password rejected by policy", log.ErrorField(err))
 		return "", err
 	}
 
-	user := entity.NewUser(login, string(passwordHash), "", uuid.Nil)
-
-	user, err = s.repository.CreateUser(ctx, user)
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
+		log.Error("Did not generate password hash", log.ErrorField(err))
 		return "", err
 	}
 
-	err = s.balanceService.CreateBalanceForUser(ctx, user.UUID)
-	if err != nil {
-		return "", err
-	}
-	log.Info("User balance created")
+	user := entity.NewUser(login, string(passwordHash), "", uuid.Nil)
 
-	jwtString, err := tool.CreateJWT(user.UUID, s.secretKey)
+	user, err = s.createUserWithBalance(ctx, user)
 	if err != nil {
-		log.Error("Failed to create JWT", log.ErrorField(err))
 		return "", err
 	}
+	log.Info("User balance created")
 
-	return jwtString, nil
+	return s.startSession(ctx, user)
 }
 
 // Authenticate authorize a user.
+// If the user has two-factor authentication enabled, it returns a short-lived challenge token
+// together with entity.ErrUserTwoFactorRequired instead of the JWT.
 func (s *UserService) Authenticate(ctx context.Context, login, password string) (string, error) {
 	const op = "domain.services.UserService.Authorize"
 	log := s.logger.With(s.logger.StringField("op", op),
@@ -102,28 +164,633 @@ func (s *UserService) Authenticate(ctx context.Context, login, password string)
 		return "", entity.ErrUserWrongPasswordOrLogin
 	}
 
-	jwtString, err := tool.CreateJWT(user.UUID, s.secretKey)
+	if user.TOTPEnabled {
+		challenge, err := tool.CreateChallengeJWT(user.UUID, s.secretKey)
+		if err != nil {
+			log.Error("Failed to create challenge JWT", log.ErrorField(err))
+			return "", err
+		}
+		log.Info("Second factor required")
+		return challenge, entity.ErrUserTwoFactorRequired
+	}
+
+	return s.startSession(ctx, user)
+}
+
+// AuthenticateTwoFactor finishes a two-factor login with a TOTP or recovery code.
+func (s *UserService) AuthenticateTwoFactor(ctx context.Context, challenge, code string) (string, error) {
+	const op = "domain.services.UserService.AuthenticateTwoFactor"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	userUUID, err := tool.CheckChallengeJWT(challenge, s.secretKey)
+	if err != nil {
+		log.Info("Invalid challenge JWT", log.ErrorField(err))
+		return "", entity.ErrUserTwoFactorChallengeInvalid
+	}
+
+	user, err := s.repository.GetUserByUUID(ctx, userUUID)
 	if err != nil {
-		log.Error("Failed to create JWT", log.ErrorField(err))
 		return "", err
 	}
+	if !user.TOTPEnabled {
+		log.Info("Two-factor authentication was disabled after the challenge")
+		return "", entity.ErrUserTwoFactorChallengeInvalid
+	}
 
-	return jwtString, nil
+	err = s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return "", err
+	}
+
+	return s.startSession(ctx, user)
 }
 
-// Authorize authenticates a user.
+// EnrollTwoFactor creates a new pending TOTP secret for the user.
+// Two-factor authentication is enabled only after ConfirmTwoFactor.
+func (s *UserService) EnrollTwoFactor(ctx context.Context, user *entity.User) (*entity.TwoFactorEnrollment, error) {
+	const op = "domain.services.UserService.EnrollTwoFactor"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	storedUser, err := s.repository.GetUserByUUID(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if storedUser.TOTPEnabled {
+		return nil, entity.ErrUserTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Error("Failed to generate TOTP secret", log.ErrorField(err))
+		return nil, err
+	}
+
+	err = s.repository.SetTOTP(ctx, user.UUID, secret, false)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Two-factor enrollment started")
+	return &entity.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, storedUser.Login, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with the first TOTP code and returns recovery codes.
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	const op = "domain.services.UserService.ConfirmTwoFactor"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	storedUser, err := s.repository.GetUserByUUID(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if storedUser.TOTPEnabled {
+		return nil, entity.ErrUserTwoFactorAlreadyEnabled
+	}
+	if storedUser.TOTPSecret == "" {
+		return nil, entity.ErrUserTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Match(storedUser.TOTPSecret, code, time.Now())
+	if !ok {
+		log.Info("Wrong TOTP code")
+		return nil, entity.ErrUserTwoFactorCodeInvalid
+	}
+	err = s.repository.UseTOTPStep(ctx, user.UUID, step)
+	if err != nil {
+		log.Info("TOTP code already used", log.ErrorField(err))
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		recoveryCode, err := tool.NewRecoveryCode()
+		if err != nil {
+			log.Error("Failed to generate recovery code", log.ErrorField(err))
+			return nil, err
+		}
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, tool.HashToken(tool.NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	err = s.repository.ReplaceRecoveryCodes(ctx, user.UUID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.SetTOTP(ctx, user.UUID, storedUser.TOTPSecret, true)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Two-factor authentication enabled")
+	return codes, nil
+}
+
+// DisableTwoFactor disables two-factor authentication after checking a TOTP or recovery code.
+func (s *UserService) DisableTwoFactor(ctx context.Context, user *entity.User, code string) error {
+	const op = "domain.services.UserService.DisableTwoFactor"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	storedUser, err := s.repository.GetUserByUUID(ctx, user.UUID)
+	if err != nil {
+		return err
+	}
+	if !storedUser.TOTPEnabled {
+		return entity.ErrUserTwoFactorNotEnrolled
+	}
+
+	err = s.verifySecondFactor(ctx, storedUser, code)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.SetTOTP(ctx, user.UUID, "", false)
+	if err != nil {
+		return err
+	}
+	err = s.repository.ReplaceRecoveryCodes(ctx, user.UUID, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Two-factor authentication disabled")
+	return nil
+}
+
+// verifySecondFactor accepts a TOTP code or consumes a recovery code.
+// The attempts of the user are limited, and a TOTP code is accepted once, the older codes are rejected after it.
+func (s *UserService) verifySecondFactor(ctx context.Context, user *entity.User, code string) error {
+	const op = "domain.services.UserService.verifySecondFactor"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	// the attempt is counted before the check, so parallel requests cannot exceed the limit
+	err := s.repository.AddTwoFactorAttempt(ctx, user.UUID, twoFactorMaxAttempts, twoFactorAttemptsWindow)
+	if err != nil {
+		log.Info("Second factor attempt rejected", log.ErrorField(err))
+		return err
+	}
+
+	if step, ok := totp.Match(user.TOTPSecret, code, time.Now()); ok {
+		err = s.repository.UseTOTPStep(ctx, user.UUID, step)
+		if err != nil {
+			log.Info("TOTP code already used", log.ErrorField(err))
+			return err
+		}
+	} else {
+		err = s.repository.ConsumeRecoveryCode(ctx, user.UUID, tool.HashToken(tool.NormalizeRecoveryCode(code)))
+		if err != nil {
+			log.Info("Wrong second factor code", log.ErrorField(err))
+			return err
+		}
+		log.Info("Recovery code used")
+	}
+
+	return s.repository.ResetTwoFactorAttempts(ctx, user.UUID)
+}
+
+// Authorize authenticates a user by a JWT or an API key.
+// A JWT is accepted on the routes whose required permission is granted to the user role.
+// An API key is accepted only on the routes that require a scope granted to the key.
 func (s *UserService) Authorize(ctx context.Context, token string) (*entity.User, error) {
 	const op = "domain.services.UserService.Authenticate"
 	log := s.logger.With(s.logger.StringField("op", op),
 		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
 	)
 
-	userUUID, err := tool.CheckJWT(token, s.secretKey)
-	if err != nil || userUUID == uuid.Nil {
+	if tool.IsAPIKey(token) {
+		return s.authorizeAPIKey(ctx, token)
+	}
+
+	claims, err := tool.CheckJWT(token, s.secretKey)
+	if err != nil {
 		log.Info("Invalid JWT", log.ErrorField(err))
 		return nil, err
 	}
+	if claims.UserID == uuid.Nil {
+		log.Info("JWT without user")
+		return nil, entity.ErrUserSessionNotFound
+	}
+
+	session, err := s.repository.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		log.Info("Session is not active", log.ErrorField(err))
+		return nil, err
+	}
+	if session.UserUUID != claims.UserID {
+		log.Info("Session belongs to another user")
+		return nil, entity.ErrUserSessionNotFound
+	}
+
+	user := entity.NewUser("", "", token, claims.UserID)
+	user.SessionUUID = session.UUID
+	if claims.Role != "" {
+		user.Role = claims.Role
+	}
+
+	permission := contexter.GetRequiredPermission(ctx)
+	if permission != "" && !user.Role.Can(permission) {
+		log.Info("Role does not allow the route",
+			log.StringField("role", string(user.Role)),
+			log.StringField("required_permission", permission),
+		)
+		return nil, entity.ErrAccessForbidden
+	}
+
+	err = s.repository.TouchSession(ctx, session.UUID, contexter.GetClientIP(ctx), contexter.GetUserAgent(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// authorizeAPIKey authenticates a user by an API key and checks the scope required by the route.
+func (s *UserService) authorizeAPIKey(ctx context.Context, key string) (*entity.User, error) {
+	const op = "domain.services.UserService.authorizeAPIKey"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	apiKey, err := s.repository.GetAPIKeyByHash(ctx, tool.HashToken(key))
+	if err != nil {
+		log.Info("API key is not active", log.ErrorField(err))
+		return nil, err
+	}
+
+	// API keys never get role permissions, they are limited to their scopes
+	scope := contexter.GetRequiredScope(ctx)
+	if scope == "" || !apiKey.HasScope(scope) || contexter.GetRequiredPermission(ctx) != "" {
+		log.Info("API key scope does not allow the route",
+			log.StringField("api_key_uuid", apiKey.UUID.String()),
+			log.StringField("required_scope", scope),
+		)
+		return nil, entity.ErrAccessForbidden
+	}
+
+	err = s.repository.TouchAPIKey(ctx, apiKey.UUID)
+	if err != nil {
+		return nil, err
+	}
 
-	user := entity.NewUser("", "", token, userUUID)
+	user := entity.NewUser("", "", "", apiKey.UserUUID)
+	user.APIKeyUUID = apiKey.UUID
 	return user, nil
 }
+
+// CreateAPIKey creates a new API key with the scopes for the user.
+// The key itself is returned only once, only its hash is stored.
+func (s *UserService) CreateAPIKey(ctx context.Context, user *entity.User, name string, scopes []string) (*entity.APIKey, string, error) {
+	const op = "domain.services.UserService.CreateAPIKey"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	if len(scopes) == 0 {
+		return nil, "", entity.ErrAPIKeyScopeInvalid
+	}
+	for _, scope := range scopes {
+		if !entity.ValidScope(scope) {
+			log.Info("Unknown API key scope", log.StringField("scope", scope))
+			return nil, "", entity.ErrAPIKeyScopeInvalid
+		}
+	}
+
+	key, keyHash, err := tool.NewAPIKey()
+	if err != nil {
+		log.Error("Failed to generate API key", log.ErrorField(err))
+		return nil, "", err
+	}
+
+	// admins create keys on behalf of other users, so the owner is checked
+	_, err = s.repository.GetUserByUUID(ctx, user.UUID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := entity.NewAPIKey(user.UUID, name, keyHash, scopes)
+	err = s.repository.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	log.Info("API key created", log.StringField("api_key_uuid", apiKey.UUID.String()))
+	return apiKey, key, nil
+}
+
+// ListAPIKeys returns the active API keys of the user.
+func (s *UserService) ListAPIKeys(ctx context.Context, user *entity.User) ([]entity.APIKey, error) {
+	return s.repository.ListAPIKeys(ctx, user.UUID)
+}
+
+// RevokeAPIKey revokes an API key of the user.
+func (s *UserService) RevokeAPIKey(ctx context.Context, user *entity.User, keyUUID uuid.UUID) error {
+	const op = "domain.services.UserService.RevokeAPIKey"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	err := s.repository.RevokeAPIKey(ctx, user.UUID, keyUUID)
+	if err != nil {
+		return err
+	}
+	log.Info("API key revoked", log.StringField("api_key_uuid", keyUUID.String()))
+	return nil
+}
+
+// ListSessions returns the active sessions of the user.
+func (s *UserService) ListSessions(ctx context.Context, user *entity.User) ([]entity.Session, error) {
+	return s.repository.ListSessions(ctx, user.UUID)
+}
+
+// RevokeSession signs the user out of one session.
+func (s *UserService) RevokeSession(ctx context.Context, user *entity.User, sessionUUID uuid.UUID) error {
+	const op = "domain.services.UserService.RevokeSession"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	err := s.repository.DeleteSession(ctx, user.UUID, sessionUUID)
+	if err != nil {
+		return err
+	}
+	log.Info("Session revoked", log.StringField("session_uuid", sessionUUID.String()))
+	return nil
+}
+
+// GetUser returns a user by UUID.
+func (s *UserService) GetUser(ctx context.Context, userUUID uuid.UUID) (*entity.User, error) {
+	return s.repository.GetUserByUUID(ctx, userUUID)
+}
+
+// Anonymize removes the personal data and all the credentials of the user, so the user cannot log in anymore.
+func (s *UserService) Anonymize(ctx context.Context, userUUID uuid.UUID) error {
+	return s.repository.AnonymizeUser(ctx, userUUID)
+}
+
+// SetUserRole changes the role of a user and revokes all the user sessions,
+// so the new role is carried by the next JWT.
+func (s *UserService) SetUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error {
+	const op = "domain.services.UserService.SetUserRole"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", userUUID.String()),
+	)
+
+	err := s.repository.UpdateUserRole(ctx, userUUID, role)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.DeleteUserSessions(ctx, userUUID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	log.Info("User role changed, all sessions revoked", log.StringField("role", string(role)))
+	return nil
+}
+
+// BootstrapAdmin makes sure the user with the login exists and has the admin role.
+// A missing user is registered with the password, the password of an existing user is left as is.
+func (s *UserService) BootstrapAdmin(ctx context.Context, login, password string) error {
+	const op = "domain.services.UserService.BootstrapAdmin"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_login", login),
+	)
+
+	user, err := s.repository.GetUserByLogin(ctx, login)
+	if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+		return err
+	}
+	if err == nil {
+		if user.Role == entity.RoleAdmin {
+			return nil
+		}
+		err = s.SetUserRole(ctx, user.UUID, entity.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		log.Info("Existing user promoted to admin")
+		return nil
+	}
+
+	err = s.passwordPolicy.Validate(login, password)
+	if err != nil {
+		log.Error("Admin password rejected by policy", log.ErrorField(err))
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Error("Did not generate password hash", log.ErrorField(err))
+		return err
+	}
+
+	user = entity.NewUser(login, string(passwordHash), "", uuid.Nil)
+	user.Role = entity.RoleAdmin
+	_, err = s.createUserWithBalance(ctx, user)
+	if err != nil {
+		return err
+	}
+	log.Info("Admin user created")
+	return nil
+}
+
+// ChangePassword changes the user password and revokes all the user sessions except the current one.
+func (s *UserService) ChangePassword(ctx context.Context, user *entity.User, oldPassword, newPassword string) error {
+	const op = "domain.services.UserService.ChangePassword"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", user.UUID.String()),
+	)
+
+	storedUser, err := s.repository.GetUserByUUID(ctx, user.UUID)
+	if err != nil {
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(storedUser.PasswordHash), []byte(oldPassword))
+	if err != nil {
+		log.Info("Wrong old password", log.ErrorField(err))
+		return entity.ErrUserWrongPasswordOrLogin
+	}
+
+	err = s.passwordPolicy.Validate(storedUser.Login, newPassword)
+	if err != nil {
+		log.Info("Password rejected by policy", log.ErrorField(err))
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Error("Did not generate password hash", log.ErrorField(err))
+		return err
+	}
+
+	err = s.repository.UpdatePassword(ctx, user.UUID, string(passwordHash))
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.DeleteUserSessions(ctx, user.UUID, user.SessionUUID)
+	if err != nil {
+		return err
+	}
+	log.Info("Password changed, other sessions revoked")
+	return nil
+}
+
+// RequestPasswordReset sends a password reset token to the user.
+// An unknown login is not reported to the caller, so logins cannot be enumerated.
+func (s *UserService) RequestPasswordReset(ctx context.Context, login string) error {
+	const op = "domain.services.UserService.RequestPasswordReset"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	if s.resetNotifier == nil {
+		log.Error("Password reset notifier is not set")
+		return entity.ErrPasswordResetUnavailable
+	}
+
+	user, err := s.repository.GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			log.Info("Password reset requested for unknown login")
+			return nil
+		}
+		return err
+	}
+
+	token, tokenHash, err := tool.NewSecretToken()
+	if err != nil {
+		log.Error("Failed to generate password reset token", log.ErrorField(err))
+		return err
+	}
+
+	resetToken := entity.NewPasswordResetToken(user.UUID, tokenHash, s.resetTTL)
+	err = s.repository.CreatePasswordResetToken(ctx, resetToken)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetNotifier.SendPasswordReset(ctx, user, token, resetToken.ExpiresAt)
+	if err != nil {
+		log.Error("Failed to send password reset token", log.ErrorField(err))
+		return err
+	}
+	log.Info("Password reset token sent", log.StringField("user_uuid", user.UUID.String()))
+	return nil
+}
+
+// ResetPassword sets a new password by a password reset token and revokes all the user sessions.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	const op = "domain.services.UserService.ResetPassword"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	tokenHash := tool.HashToken(token)
+	resetToken, err := s.repository.GetPasswordResetToken(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repository.GetUserByUUID(ctx, resetToken.UserUUID)
+	if err != nil {
+		return err
+	}
+
+	// the token is consumed only after the new password passes the policy,
+	// so the user can try another password with the same token
+	err = s.passwordPolicy.Validate(user.Login, newPassword)
+	if err != nil {
+		log.Info("Password rejected by policy", log.ErrorField(err))
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Error("Did not generate password hash", log.ErrorField(err))
+		return err
+	}
+
+	err = s.repository.ConsumePasswordResetToken(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.UpdatePassword(ctx, user.UUID, string(passwordHash))
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.DeleteUserSessions(ctx, user.UUID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	log.Info("Password reset, all sessions revoked", log.StringField("user_uuid", user.UUID.String()))
+	return nil
+}
+
+// createUserWithBalance creates the user and the balance in one transaction,
+// so a user is never left without a balance.
+func (s *UserService) createUserWithBalance(ctx context.Context, user *entity.User) (*entity.User, error) {
+	var created *entity.User
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.repository.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return s.balanceService.CreateBalanceForUser(ctx, created.UUID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// startSession creates a new session for the user and returns its JWT.
+func (s *UserService) startSession(ctx context.Context, user *entity.User) (string, error) {
+	const op = "domain.services.UserService.startSession"
+	log := s.logger.With(s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	session := entity.NewSession(user.UUID, tool.JWTLifetime)
+	session.IP = contexter.GetClientIP(ctx)
+	session.UserAgent = contexter.GetUserAgent(ctx)
+	err := s.repository.CreateSession(ctx, session)
+	if err != nil {
+		return "", err
+	}
+
+	jwtString, err := tool.CreateJWT(user.UUID, session.UUID, user.Role, s.secretKey)
+	if err != nil {
+		log.Error("Failed to create JWT", log.ErrorField(err))
+		return "", err
+	}
+
+	return jwtString, nil
+}
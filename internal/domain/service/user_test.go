@@ -2,7 +2,9 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -15,10 +17,20 @@ import (
 	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
 	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/totp"
 )
 
+func newPasswordPolicy(t *testing.T) *tool.PasswordPolicy {
+	policy, err := tool.NewPasswordPolicy(8, 2, "")
+	if err != nil {
+		t.Fatalf("Failed to create password policy: %v", err)
+	}
+	return policy
+}
+
 func TestUserService_Registration(t *testing.T) {
 	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	policy := newPasswordPolicy(t)
 	type args struct {
 		ctx      context.Context
 		login    string
@@ -38,7 +50,7 @@ func TestUserService_Registration(t *testing.T) {
 			args: args{
 				ctx:      ctx,
 				login:    "test",
-				password: "password",
+				password: "Str0ngPassw0rd",
 			},
 			want:           "jwtString",
 			wantErr:        nil,
@@ -50,13 +62,23 @@ func TestUserService_Registration(t *testing.T) {
 			args: args{
 				ctx:      ctx,
 				login:    "test",
-				password: "password",
+				password: "Str0ngPassw0rd",
 			},
 			want:           "",
 			wantErr:        entity.ErrUserExists,
 			repositoryWant: nil,
 			repositoryErr:  entity.ErrUserExists,
 		},
+		{
+			name: "Create a new user: password rejected by policy",
+			args: args{
+				ctx:      ctx,
+				login:    "test",
+				password: "password",
+			},
+			want:    "",
+			wantErr: entity.ErrPasswordTooSimple,
+		},
 	}
 
 	for _, tc := range tests {
@@ -71,11 +93,18 @@ func TestUserService_Registration(t *testing.T) {
 					Once()
 			}
 			NewUserRepositoryMock := mocks.NewUserRepository(t)
-			NewUserRepositoryMock.On("CreateUser", mock.Anything, mock.Anything).
-				Return(tc.repositoryWant, tc.repositoryErr).
-				Once()
+			if !errors.Is(tc.wantErr, entity.ErrPasswordPolicy) {
+				NewUserRepositoryMock.On("CreateUser", mock.Anything, mock.Anything).
+					Return(tc.repositoryWant, tc.repositoryErr).
+					Once()
+			}
+			if tc.wantErr == nil {
+				NewUserRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			}
 			log := logger.NewLogger()
-			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, log, "secret")
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, policy, log, "secret")
 			//Body of test
 			got, err := s.Register(tc.args.ctx, tc.args.login, tc.args.password)
 			//Asserts
@@ -89,6 +118,34 @@ func TestUserService_Registration(t *testing.T) {
 	}
 }
 
+func TestUserService_Registration_Transaction(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	type txKey struct{}
+	inTransaction := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+
+	transactorMock := mocks.NewTransactor(t)
+	transactorMock.On("WithinTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		}).
+		Once()
+	userRepositoryMock := mocks.NewUserRepository(t)
+	userRepositoryMock.On("CreateUser", inTransaction, mock.Anything).
+		Return(entity.NewUser("test", "", "", uuid.New()), nil).
+		Once()
+	balanceCreatorMock := mocks.NewBalanceCreator(t)
+	balanceCreatorMock.On("CreateBalanceForUser", inTransaction, mock.Anything).
+		Return(errors.New("db error")).
+		Once()
+	s := service.NewUserService(userRepositoryMock, balanceCreatorMock, newPasswordPolicy(t), logger.NewLogger(), "secret")
+	s.SetTransactor(transactorMock)
+
+	got, err := s.Register(ctx, "test", "Str0ngPassw0rd")
+
+	assert.Error(t, err)
+	assert.Empty(t, got)
+}
+
 func TestUserService_Authenticate(t *testing.T) {
 	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
 	password := "password"
@@ -146,8 +203,13 @@ func TestUserService_Authenticate(t *testing.T) {
 			NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, mock.Anything).
 				Return(tc.repositoryWant, tc.repositoryErr).
 				Once()
+			if tc.wantErr == nil {
+				NewUserRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			}
 			log := logger.NewLogger()
-			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, log, "secret")
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), log, "secret")
 			//Body of test
 			got, err := s.Authenticate(tc.args.ctx, tc.args.login, tc.args.password)
 			//Asserts
@@ -163,11 +225,14 @@ func TestUserService_Authenticate(t *testing.T) {
 
 func TestUserService_Authorize(t *testing.T) {
 	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	ctx = context.WithValue(ctx, contexter.ClientIP, "192.0.2.1")
+	ctx = context.WithValue(ctx, contexter.UserAgent, "test-agent")
 	userUUID, err := uuid.NewRandom()
 	if err != nil {
 		t.Fatalf("Failed to create user UUID: %v", err)
 	}
-	jwtString, err := tool.CreateJWT(userUUID, "secret")
+	session := entity.NewSession(userUUID, tool.JWTLifetime)
+	jwtString, err := tool.CreateJWT(userUUID, session.UUID, entity.RoleUser, "secret")
 
 	if err != nil {
 		t.Fatalf("Failed to create JWT: %v", err)
@@ -177,10 +242,12 @@ func TestUserService_Authorize(t *testing.T) {
 		token string
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    *entity.User
-		wantErr bool
+		name       string
+		args       args
+		want       *entity.User
+		wantErr    bool
+		session    *entity.Session
+		sessionErr error
 	}{
 		{
 			name: "Authorize a user: success",
@@ -189,11 +256,14 @@ func TestUserService_Authorize(t *testing.T) {
 				token: jwtString,
 			},
 			want: &entity.User{
-				UUID:  userUUID,
-				Login: "",
-				JWT:   jwtString,
+				UUID:        userUUID,
+				Login:       "",
+				JWT:         jwtString,
+				SessionUUID: session.UUID,
+				Role:        entity.RoleUser,
 			},
 			wantErr: false,
+			session: session,
 		},
 		{
 			name: "Authorize a user: invalid JWT",
@@ -204,6 +274,16 @@ func TestUserService_Authorize(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "Authorize a user: session revoked",
+			args: args{
+				ctx:   ctx,
+				token: jwtString,
+			},
+			want:       nil,
+			wantErr:    true,
+			sessionErr: entity.ErrUserSessionNotFound,
+		},
 	}
 
 	for _, tc := range tests {
@@ -213,8 +293,18 @@ func TestUserService_Authorize(t *testing.T) {
 			//Prepare mocks
 			NewBalanceCreatorMock := mocks.NewBalanceCreator(t)
 			NewUserRepositoryMock := mocks.NewUserRepository(t)
+			if tc.session != nil || tc.sessionErr != nil {
+				NewUserRepositoryMock.On("GetSession", mock.Anything, session.UUID).
+					Return(tc.session, tc.sessionErr).
+					Once()
+			}
+			if tc.session != nil {
+				NewUserRepositoryMock.On("TouchSession", mock.Anything, session.UUID, "192.0.2.1", "test-agent").
+					Return(nil).
+					Once()
+			}
 			log := logger.NewLogger()
-			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, log, "secret")
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), log, "secret")
 			//Body of test
 			got, err := s.Authorize(tc.args.ctx, tc.args.token)
 			//Asserts
@@ -225,3 +315,665 @@ func TestUserService_Authorize(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_ChangePassword(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	oldPassword := "0ldPassword"
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(oldPassword), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Did not generate password hash: %v", err)
+	}
+	user := &entity.User{UUID: uuid.New(), SessionUUID: uuid.New()}
+	storedUser := &entity.User{UUID: user.UUID, Login: "test", PasswordHash: string(passwordHash)}
+
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantErr     error
+	}{
+		{
+			name:        "Change password: success",
+			oldPassword: oldPassword,
+			newPassword: "N3wPassword",
+			wantErr:     nil,
+		},
+		{
+			name:        "Change password: wrong old password",
+			oldPassword: "wrong",
+			newPassword: "N3wPassword",
+			wantErr:     entity.ErrUserWrongPasswordOrLogin,
+		},
+		{
+			name:        "Change password: new password contains login",
+			oldPassword: oldPassword,
+			newPassword: "MyTestPassw0rd",
+			wantErr:     entity.ErrPasswordContainsLogin,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			//Prepare mocks
+			NewBalanceCreatorMock := mocks.NewBalanceCreator(t)
+			NewUserRepositoryMock := mocks.NewUserRepository(t)
+			NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+				Return(storedUser, nil).
+				Once()
+			if tc.wantErr == nil {
+				NewUserRepositoryMock.On("UpdatePassword", mock.Anything, user.UUID, mock.Anything).
+					Return(nil).
+					Once()
+				NewUserRepositoryMock.On("DeleteUserSessions", mock.Anything, user.UUID, user.SessionUUID).
+					Return(nil).
+					Once()
+			}
+			log := logger.NewLogger()
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), log, "secret")
+			//Body of test
+			err := s.ChangePassword(ctx, user, tc.oldPassword, tc.newPassword)
+			//Asserts
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestUserService_RequestPasswordReset(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	user := &entity.User{UUID: uuid.New(), Login: "test"}
+
+	tests := []struct {
+		name          string
+		repositoryErr error
+		wantSent      bool
+		wantErr       error
+	}{
+		{
+			name:     "Request password reset: success",
+			wantSent: true,
+		},
+		{
+			name:          "Request password reset: unknown login is not reported",
+			repositoryErr: entity.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			//Prepare mocks
+			NewBalanceCreatorMock := mocks.NewBalanceCreator(t)
+			NewUserRepositoryMock := mocks.NewUserRepository(t)
+			NewNotifierMock := mocks.NewPasswordResetNotifier(t)
+			var repositoryUser *entity.User
+			if tc.repositoryErr == nil {
+				repositoryUser = user
+			}
+			NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, user.Login).
+				Return(repositoryUser, tc.repositoryErr).
+				Once()
+			var sentToken string
+			var storedHash string
+			if tc.wantSent {
+				NewUserRepositoryMock.On("CreatePasswordResetToken", mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						storedHash = args.Get(1).(*entity.PasswordResetToken).TokenHash
+					}).
+					Return(nil).
+					Once()
+				NewNotifierMock.On("SendPasswordReset", mock.Anything, user, mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						sentToken = args.String(2)
+					}).
+					Return(nil).
+					Once()
+			}
+			log := logger.NewLogger()
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), log, "secret")
+			s.SetPasswordResetNotifier(NewNotifierMock, time.Minute)
+			//Body of test
+			err := s.RequestPasswordReset(ctx, user.Login)
+			//Asserts
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantSent {
+				assert.NotEmpty(t, sentToken)
+				assert.NotEqual(t, sentToken, storedHash, "only the token hash must be stored")
+				assert.Equal(t, tool.HashToken(sentToken), storedHash)
+			}
+		})
+	}
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	user := &entity.User{UUID: uuid.New(), Login: "test"}
+	token := "reset-token"
+	resetToken := entity.NewPasswordResetToken(user.UUID, tool.HashToken(token), time.Minute)
+
+	tests := []struct {
+		name        string
+		newPassword string
+		tokenErr    error
+		consumeErr  error
+		wantErr     error
+	}{
+		{
+			name:        "Reset password: success",
+			newPassword: "N3wPassword",
+		},
+		{
+			name:        "Reset password: invalid token",
+			newPassword: "N3wPassword",
+			tokenErr:    entity.ErrPasswordResetTokenInvalid,
+			wantErr:     entity.ErrPasswordResetTokenInvalid,
+		},
+		{
+			name:        "Reset password: rejected by policy keeps the token",
+			newPassword: "short",
+			wantErr:     entity.ErrPasswordTooShort,
+		},
+		{
+			name:        "Reset password: token used concurrently",
+			newPassword: "N3wPassword",
+			consumeErr:  entity.ErrPasswordResetTokenInvalid,
+			wantErr:     entity.ErrPasswordResetTokenInvalid,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			//Prepare mocks
+			NewBalanceCreatorMock := mocks.NewBalanceCreator(t)
+			NewUserRepositoryMock := mocks.NewUserRepository(t)
+			if tc.tokenErr != nil {
+				NewUserRepositoryMock.On("GetPasswordResetToken", mock.Anything, resetToken.TokenHash).
+					Return(nil, tc.tokenErr).
+					Once()
+			} else {
+				NewUserRepositoryMock.On("GetPasswordResetToken", mock.Anything, resetToken.TokenHash).
+					Return(resetToken, nil).
+					Once()
+				NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+					Return(user, nil).
+					Once()
+			}
+			if !errors.Is(tc.wantErr, entity.ErrPasswordPolicy) && tc.tokenErr == nil {
+				NewUserRepositoryMock.On("ConsumePasswordResetToken", mock.Anything, resetToken.TokenHash).
+					Return(tc.consumeErr).
+					Once()
+			}
+			if tc.wantErr == nil {
+				NewUserRepositoryMock.On("UpdatePassword", mock.Anything, user.UUID, mock.Anything).
+					Return(nil).
+					Once()
+				NewUserRepositoryMock.On("DeleteUserSessions", mock.Anything, user.UUID, uuid.Nil).
+					Return(nil).
+					Once()
+			}
+			log := logger.NewLogger()
+			s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), log, "secret")
+			//Body of test
+			err := s.ResetPassword(ctx, token, tc.newPassword)
+			//Asserts
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestUserService_TwoFactor(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	password := "Str0ngPassw0rd"
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Did not generate password hash: %v", err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Did not generate TOTP secret: %v", err)
+	}
+	user := &entity.User{
+		UUID:         uuid.New(),
+		Login:        "test",
+		PasswordHash: string(passwordHash),
+		TOTPSecret:   secret,
+		TOTPEnabled:  true,
+	}
+
+	t.Run("Authenticate returns a challenge instead of JWT", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, user.Login).
+			Return(user, nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		challenge, err := s.Authenticate(ctx, user.Login, password)
+
+		assert.Equal(t, entity.ErrUserTwoFactorRequired, err)
+		userUUID, err := tool.CheckChallengeJWT(challenge, "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, user.UUID, userUUID)
+	})
+
+	t.Run("Second step with TOTP code", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("AddTwoFactorAttempt", mock.Anything, user.UUID, 5, 15*time.Minute).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("UseTOTPStep", mock.Anything, user.UUID, time.Now().Unix()/int64(totp.Period.Seconds())).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("ResetTwoFactorAttempts", mock.Anything, user.UUID).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		challenge, err := tool.CreateChallengeJWT(user.UUID, "secret")
+		assert.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		assert.NoError(t, err)
+
+		jwtString, err := s.AuthenticateTwoFactor(ctx, challenge, code)
+
+		assert.NoError(t, err)
+		claims, err := tool.CheckJWT(jwtString, "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, user.UUID, claims.UserID)
+	})
+
+	t.Run("Second step with recovery code", func(t *testing.T) {
+		t.Parallel()
+		recoveryCode := "abcde-fghij"
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("AddTwoFactorAttempt", mock.Anything, user.UUID, 5, 15*time.Minute).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("ConsumeRecoveryCode", mock.Anything, user.UUID, tool.HashToken("abcdefghij")).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("ResetTwoFactorAttempts", mock.Anything, user.UUID).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		challenge, err := tool.CreateChallengeJWT(user.UUID, "secret")
+		assert.NoError(t, err)
+
+		_, err = s.AuthenticateTwoFactor(ctx, challenge, recoveryCode)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Second step with wrong code", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("AddTwoFactorAttempt", mock.Anything, user.UUID, 5, 15*time.Minute).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("ConsumeRecoveryCode", mock.Anything, user.UUID, mock.Anything).
+			Return(entity.ErrUserTwoFactorCodeInvalid).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		challenge, err := tool.CreateChallengeJWT(user.UUID, "secret")
+		assert.NoError(t, err)
+
+		_, err = s.AuthenticateTwoFactor(ctx, challenge, "000000")
+
+		assert.Equal(t, entity.ErrUserTwoFactorCodeInvalid, err)
+	})
+
+	t.Run("Second step with used TOTP code", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("AddTwoFactorAttempt", mock.Anything, user.UUID, 5, 15*time.Minute).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("UseTOTPStep", mock.Anything, user.UUID, mock.Anything).
+			Return(entity.ErrUserTwoFactorCodeInvalid).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		challenge, err := tool.CreateChallengeJWT(user.UUID, "secret")
+		assert.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		assert.NoError(t, err)
+
+		_, err = s.AuthenticateTwoFactor(ctx, challenge, code)
+
+		assert.Equal(t, entity.ErrUserTwoFactorCodeInvalid, err)
+	})
+
+	t.Run("Second step after the attempts are used up", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("AddTwoFactorAttempt", mock.Anything, user.UUID, mock.Anything, mock.Anything).
+			Return(entity.ErrUserTwoFactorLocked).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		challenge, err := tool.CreateChallengeJWT(user.UUID, "secret")
+		assert.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		assert.NoError(t, err)
+
+		// even a right code is not checked
+		_, err = s.AuthenticateTwoFactor(ctx, challenge, code)
+
+		assert.Equal(t, entity.ErrUserTwoFactorLocked, err)
+	})
+
+	t.Run("Second step with session JWT instead of challenge", func(t *testing.T) {
+		t.Parallel()
+		s := service.NewUserService(mocks.NewUserRepository(t), mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		jwtString, err := tool.CreateJWT(user.UUID, uuid.New(), entity.RoleUser, "secret")
+		assert.NoError(t, err)
+
+		_, err = s.AuthenticateTwoFactor(ctx, jwtString, "000000")
+
+		assert.Equal(t, entity.ErrUserTwoFactorChallengeInvalid, err)
+	})
+
+	t.Run("Confirm enrollment returns recovery codes", func(t *testing.T) {
+		t.Parallel()
+		pending := &entity.User{UUID: user.UUID, Login: user.Login, TOTPSecret: secret}
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, user.UUID).
+			Return(pending, nil).
+			Once()
+		NewUserRepositoryMock.On("ReplaceRecoveryCodes", mock.Anything, user.UUID, mock.Anything).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("UseTOTPStep", mock.Anything, user.UUID, mock.Anything).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("SetTOTP", mock.Anything, user.UUID, secret, true).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+		code, err := totp.Code(secret, time.Now())
+		assert.NoError(t, err)
+
+		codes, err := s.ConfirmTwoFactor(ctx, &entity.User{UUID: user.UUID}, code)
+
+		assert.NoError(t, err)
+		assert.Len(t, codes, 10)
+	})
+}
+
+func TestUserService_APIKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	key, keyHash, err := tool.NewAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	apiKey := entity.NewAPIKey(uuid.New(), "POS", keyHash, []string{entity.ScopeOrdersWrite})
+
+	tests := []struct {
+		name    string
+		scope   string
+		want    *entity.User
+		wantErr error
+	}{
+		{
+			name:  "Authorize by API key: success",
+			scope: entity.ScopeOrdersWrite,
+			want: &entity.User{
+				UUID:       apiKey.UserUUID,
+				Role:       entity.RoleUser,
+				APIKeyUUID: apiKey.UUID,
+			},
+		},
+		{
+			name:    "Authorize by API key: scope is not granted",
+			scope:   entity.ScopeBalanceRead,
+			wantErr: entity.ErrAccessForbidden,
+		},
+		{
+			name:    "Authorize by API key: route for JWT only",
+			wantErr: entity.ErrAccessForbidden,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			NewUserRepositoryMock := mocks.NewUserRepository(t)
+			NewUserRepositoryMock.On("GetAPIKeyByHash", mock.Anything, keyHash).
+				Return(apiKey, nil).
+				Once()
+			if tc.wantErr == nil {
+				NewUserRepositoryMock.On("TouchAPIKey", mock.Anything, apiKey.UUID).
+					Return(nil).
+					Once()
+			}
+			s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+			scopeCtx := ctx
+			if tc.scope != "" {
+				scopeCtx = context.WithValue(ctx, contexter.RequiredScope, tc.scope)
+			}
+			got, err := s.Authorize(scopeCtx, key)
+
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+
+	t.Run("Authorize by revoked API key", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetAPIKeyByHash", mock.Anything, keyHash).
+			Return(nil, entity.ErrAPIKeyNotFound).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		_, err := s.Authorize(context.WithValue(ctx, contexter.RequiredScope, entity.ScopeOrdersWrite), key)
+
+		assert.Equal(t, entity.ErrAPIKeyNotFound, err)
+	})
+
+	t.Run("Create API key", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByUUID", mock.Anything, apiKey.UserUUID).
+			Return(&entity.User{UUID: apiKey.UserUUID}, nil).
+			Once()
+		NewUserRepositoryMock.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*entity.APIKey")).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		created, newKey, err := s.CreateAPIKey(ctx, &entity.User{UUID: apiKey.UserUUID}, "POS", []string{entity.ScopeOrdersWrite})
+
+		assert.NoError(t, err)
+		assert.True(t, tool.IsAPIKey(newKey))
+		assert.Equal(t, tool.HashToken(newKey), created.KeyHash)
+	})
+
+	t.Run("Create API key with unknown scope", func(t *testing.T) {
+		t.Parallel()
+		s := service.NewUserService(mocks.NewUserRepository(t), mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		_, _, err := s.CreateAPIKey(ctx, &entity.User{UUID: apiKey.UserUUID}, "POS", []string{"orders:delete"})
+
+		assert.Equal(t, entity.ErrAPIKeyScopeInvalid, err)
+	})
+}
+
+func TestUserService_Roles(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+
+	t.Run("Authorize: role has the permission", func(t *testing.T) {
+		t.Parallel()
+		session := entity.NewSession(uuid.New(), tool.JWTLifetime)
+		jwtString, err := tool.CreateJWT(session.UserUUID, session.UUID, entity.RoleSupport, "secret")
+		assert.NoError(t, err)
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetSession", mock.Anything, session.UUID).
+			Return(session, nil).
+			Once()
+		NewUserRepositoryMock.On("TouchSession", mock.Anything, session.UUID, "", "").
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		user, err := s.Authorize(context.WithValue(ctx, contexter.RequiredPermission, entity.PermissionUsersRead), jwtString)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.RoleSupport, user.Role)
+	})
+
+	t.Run("Authorize: role has no permission", func(t *testing.T) {
+		t.Parallel()
+		session := entity.NewSession(uuid.New(), tool.JWTLifetime)
+		jwtString, err := tool.CreateJWT(session.UserUUID, session.UUID, entity.RoleSupport, "secret")
+		assert.NoError(t, err)
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetSession", mock.Anything, session.UUID).
+			Return(session, nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		_, err = s.Authorize(context.WithValue(ctx, contexter.RequiredPermission, entity.PermissionUsersWrite), jwtString)
+
+		assert.Equal(t, entity.ErrAccessForbidden, err)
+	})
+
+	t.Run("Set role revokes sessions", func(t *testing.T) {
+		t.Parallel()
+		userUUID := uuid.New()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("UpdateUserRole", mock.Anything, userUUID, entity.RoleSupport).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("DeleteUserSessions", mock.Anything, userUUID, uuid.Nil).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		err := s.SetUserRole(ctx, userUUID, entity.RoleSupport)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Bootstrap admin: new user", func(t *testing.T) {
+		t.Parallel()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, "admin").
+			Return(nil, entity.ErrUserNotFound).
+			Once()
+		NewUserRepositoryMock.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+			return user.Login == "admin" && user.Role == entity.RoleAdmin
+		})).
+			Return(func(_ context.Context, user *entity.User) *entity.User { return user }, nil).
+			Once()
+		NewBalanceCreatorMock := mocks.NewBalanceCreator(t)
+		NewBalanceCreatorMock.On("CreateBalanceForUser", mock.Anything, mock.Anything).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, NewBalanceCreatorMock, newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		err := s.BootstrapAdmin(ctx, "admin", "Str0ngPassw0rd")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Bootstrap admin: existing user is promoted", func(t *testing.T) {
+		t.Parallel()
+		user := entity.NewUser("admin", "hash", "", uuid.Nil)
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, "admin").
+			Return(user, nil).
+			Once()
+		NewUserRepositoryMock.On("UpdateUserRole", mock.Anything, user.UUID, entity.RoleAdmin).
+			Return(nil).
+			Once()
+		NewUserRepositoryMock.On("DeleteUserSessions", mock.Anything, user.UUID, uuid.Nil).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		err := s.BootstrapAdmin(ctx, "admin", "")
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserService_Sessions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	user := &entity.User{UUID: uuid.New(), SessionUUID: uuid.New()}
+
+	t.Run("Login remembers the client", func(t *testing.T) {
+		t.Parallel()
+		password := "Str0ngPassw0rd"
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		assert.NoError(t, err)
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("GetUserByLogin", mock.Anything, "test").
+			Return(&entity.User{UUID: user.UUID, Login: "test", PasswordHash: string(passwordHash), Role: entity.RoleUser}, nil).
+			Once()
+		NewUserRepositoryMock.On("CreateSession", mock.Anything, mock.MatchedBy(func(session *entity.Session) bool {
+			return session.IP == "192.0.2.1" && session.UserAgent == "test-agent"
+		})).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		clientCtx := context.WithValue(ctx, contexter.ClientIP, "192.0.2.1")
+		clientCtx = context.WithValue(clientCtx, contexter.UserAgent, "test-agent")
+		_, err = s.Authenticate(clientCtx, "test", password)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Revoke a session", func(t *testing.T) {
+		t.Parallel()
+		sessionUUID := uuid.New()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("DeleteSession", mock.Anything, user.UUID, sessionUUID).
+			Return(nil).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		err := s.RevokeSession(ctx, user, sessionUUID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Revoke a session of another user", func(t *testing.T) {
+		t.Parallel()
+		sessionUUID := uuid.New()
+		NewUserRepositoryMock := mocks.NewUserRepository(t)
+		NewUserRepositoryMock.On("DeleteSession", mock.Anything, user.UUID, sessionUUID).
+			Return(entity.ErrUserSessionNotFound).
+			Once()
+		s := service.NewUserService(NewUserRepositoryMock, mocks.NewBalanceCreator(t), newPasswordPolicy(t), logger.NewLogger(), "secret")
+
+		err := s.RevokeSession(ctx, user, sessionUUID)
+
+		assert.Equal(t, entity.ErrUserSessionNotFound, err)
+	})
+}
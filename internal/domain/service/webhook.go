@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/netguard"
+)
+
+const (
+	// webhookMaxAttempts is the number of attempts after which a delivery is given up.
+	webhookMaxAttempts = 8
+	// webhookBaseBackoff is the delay before the second attempt, it doubles with every next one.
+	webhookBaseBackoff = 10 * time.Second
+	// webhookMaxBackoff caps the delay between attempts.
+	webhookMaxBackoff = time.Hour
+	// webhookLease is how long a claimed delivery is hidden from other workers.
+	// A delivery of a crashed worker is retried after it.
+	webhookLease = time.Minute
+	// webhookDeliveryLogSize is the number of the latest deliveries returned in the log.
+	webhookDeliveryLogSize = 100
+)
+
+// Webhook delivery headers.
+const (
+	WebhookEventHeader     = "X-Gophermart-Event"
+	WebhookDeliveryHeader  = "X-Gophermart-Delivery"
+	WebhookTimestampHeader = "X-Gophermart-Timestamp"
+	WebhookSignatureHeader = "X-Gophermart-Signature"
+)
+
+// WebhookRepository is an interface for webhooks repository.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookRepository
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	ListWebhooks(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userUUID, webhookUUID uuid.UUID) error
+	ListEventWebhooks(ctx context.Context, userUUID uuid.UUID, eventType entity.EventType) ([]entity.Webhook, error)
+	AddDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, userUUID, webhookUUID uuid.UUID, limit int) ([]entity.WebhookDelivery, error)
+}
+
+// WebhookSender is an interface for posting a delivery to a webhook URL.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookSender
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// HostResolver is an interface for resolving the host of a webhook URL.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=HostResolver
+type HostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// WebhookService is a service for managing webhooks and delivering events to them.
+type WebhookService struct {
+	repository WebhookRepository
+	sender     WebhookSender
+	resolver   HostResolver
+	logger     *logger.Logger
+}
+
+// NewWebhookService returns a new webhook service.
+func NewWebhookService(logger *logger.Logger, repository WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repository: repository,
+		resolver:   net.DefaultResolver,
+		logger:     logger,
+	}
+}
+
+// SetResolver sets the resolver of the webhook hosts checked on creation.
+func (s *WebhookService) SetResolver(resolver HostResolver) {
+	s.resolver = resolver
+}
+
+// SetSender sets the sender of the deliveries, it is needed only to deliver.
+func (s *WebhookService) SetSender(sender WebhookSender) {
+	s.sender = sender
+}
+
+// Create creates a webhook of the user and returns it with the signing secret.
+func (s *WebhookService) Create(ctx context.Context, userUUID uuid.UUID, rawURL string, eventTypes []entity.EventType) (*entity.Webhook, error) {
+	const op = "domain.services.WebhookService.Create"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", userUUID.String()),
+	)
+
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		log.Info("Invalid webhook URL", log.StringField("url", rawURL))
+		return nil, entity.ErrWebhookURLInvalid
+	}
+	err = s.checkHost(ctx, target.Hostname())
+	if err != nil {
+		log.Info("Webhook URL rejected", log.StringField("url", rawURL), log.ErrorField(err))
+		return nil, err
+	}
+	if len(eventTypes) == 0 {
+		return nil, entity.ErrWebhookEventTypeInvalid
+	}
+	for _, eventType := range eventTypes {
+		if !entity.ValidEventType(eventType) {
+			log.Info("Unknown webhook event type", log.AnyField("event_type", eventType))
+			return nil, entity.ErrWebhookEventTypeInvalid
+		}
+	}
+
+	secret, _, err := tool.NewSecretToken()
+	if err != nil {
+		log.Error("Failed to generate webhook secret", log.ErrorField(err))
+		return nil, err
+	}
+
+	webhook := entity.NewWebhook(userUUID, rawURL, eventTypes, secret)
+	err = s.repository.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Webhook created", log.StringField("webhook_uuid", webhook.UUID.String()))
+	return webhook, nil
+}
+
+// checkHost rejects a host that is or resolves to an internal address.
+// The sender checks the address again on every connection, the host may be resolved differently later.
+func (s *WebhookService) checkHost(ctx context.Context, host string) error {
+	addrs := make([]netip.Addr, 0, 1)
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = s.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil || len(addrs) == 0 {
+			return fmt.Errorf("%w: host %q is not resolved", entity.ErrWebhookURLInvalid, host)
+		}
+	}
+	for _, addr := range addrs {
+		if !netguard.Public(addr) {
+			return fmt.Errorf("%w: %s", entity.ErrWebhookURLForbidden, addr)
+		}
+	}
+	return nil
+}
+
+// List returns the webhooks of the user.
+func (s *WebhookService) List(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error) {
+	return s.repository.ListWebhooks(ctx, userUUID)
+}
+
+// Delete deletes a webhook of the user with its delivery log.
+func (s *WebhookService) Delete(ctx context.Context, userUUID, webhookUUID uuid.UUID) error {
+	return s.repository.DeleteWebhook(ctx, userUUID, webhookUUID)
+}
+
+// Deliveries returns the latest deliveries of a webhook of the user, newest first.
+func (s *WebhookService) Deliveries(ctx context.Context, userUUID, webhookUUID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	return s.repository.ListDeliveries(ctx, userUUID, webhookUUID, webhookDeliveryLogSize)
+}
+
+// webhookPayload is the body of a webhook delivery.
+type webhookPayload struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      entity.EventType `json:"type"`
+	CreatedAt string           `json:"created_at"`
+	Data      any              `json:"data"`
+}
+
+type webhookOrder struct {
+	Number     string  `json:"number"`
+	Status     string  `json:"status"`
+	Accrual    float64 `json:"accrual,omitempty"`
+	UploadedAt string  `json:"uploaded_at"`
+}
+
+type webhookBalance struct {
+	Current   float64 `json:"current"`
+	Withdrawn float64 `json:"withdrawn"`
+}
+
+// Publish stores a delivery of the event for every webhook of the user subscribed to it.
+// The deliveries are sent by Deliver, so a slow partner does not hold the publisher.
+func (s *WebhookService) Publish(ctx context.Context, event entity.Event) {
+	const op = "domain.services.WebhookService.Publish"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		s.logger.StringField("user_uuid", event.UserUUID.String()),
+		s.logger.AnyField("event_type", event.Type),
+	)
+
+	webhooks, err := s.repository.ListEventWebhooks(ctx, event.UserUUID, event.Type)
+	if err != nil {
+		log.Error("Failed to get webhooks", log.ErrorField(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	var data any
+	switch {
+	case event.Order != nil:
+		data = webhookOrder{
+			Number:     string(event.Order.Number),
+			Status:     string(event.Order.Status),
+			Accrual:    event.Order.Accrual,
+			UploadedAt: event.Order.UploadedAt.Format(time.RFC3339),
+		}
+	case event.Balance != nil:
+		data = webhookBalance{
+			Current:   event.Balance.Current,
+			Withdrawn: event.Balance.Withdraw,
+		}
+	default:
+		return
+	}
+	createdAt := event.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		delivery := entity.NewWebhookDelivery(webhook.UUID, event.Type, nil)
+		delivery.Payload, err = json.Marshal(webhookPayload{
+			ID:        delivery.UUID,
+			Type:      event.Type,
+			CreatedAt: createdAt.Format(time.RFC3339),
+			Data:      data,
+		})
+		if err != nil {
+			log.Error("Failed to encode webhook payload", log.ErrorField(err))
+			return
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	err = s.repository.AddDeliveries(ctx, deliveries)
+	if err != nil {
+		log.Error("Failed to store webhook deliveries", log.ErrorField(err))
+		return
+	}
+	log.Info("Webhook deliveries stored", log.AnyField("count", len(deliveries)))
+}
+
+// Deliver sends up to limit due deliveries and returns how many were claimed.
+func (s *WebhookService) Deliver(ctx context.Context, limit int) (int, error) {
+	const op = "domain.services.WebhookService.Deliver"
+	log := s.logger.With(
+		s.logger.StringField("op", op),
+		s.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	deliveries, err := s.repository.ClaimDueDeliveries(ctx, limit, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		now := time.Now()
+		headers := map[string]string{
+			"Content-Type":         "application/json",
+			WebhookEventHeader:     string(delivery.EventType),
+			WebhookDeliveryHeader:  delivery.UUID.String(),
+			WebhookTimestampHeader: strconv.FormatInt(now.Unix(), 10),
+			WebhookSignatureHeader: "sha256=" + tool.SignWebhook(delivery.Secret, now.Unix(), delivery.Payload),
+		}
+
+		code, err := s.sender.Send(ctx, delivery.URL, headers, delivery.Payload)
+		delivery.Attempts++
+		delivery.ResponseCode = code
+		switch {
+		case err == nil && code >= 200 && code < 300:
+			delivery.Status = entity.WebhookDeliveryDelivered
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+		case delivery.Attempts >= webhookMaxAttempts:
+			delivery.Status = entity.WebhookDeliveryFailed
+			delivery.LastError = deliveryError(code, err)
+		default:
+			delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+			delivery.LastError = deliveryError(code, err)
+		}
+
+		err = s.repository.UpdateDelivery(ctx, delivery)
+		if err != nil {
+			// the lease expires and the delivery is sent again
+			log.Error("Failed to update webhook delivery", log.ErrorField(err))
+			return len(deliveries), err
+		}
+		log.Info("Webhook delivery attempted",
+			log.StringField("delivery_uuid", delivery.UUID.String()),
+			log.AnyField("status", delivery.Status),
+			log.AnyField("attempts", delivery.Attempts),
+			log.AnyField("response_code", code),
+		)
+	}
+	return len(deliveries), nil
+}
+
+// webhookBackoff returns the delay after the failed attempt.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return backoff
+}
+
+// deliveryError describes a failed attempt for the delivery log.
+func deliveryError(code int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("unexpected response status %d", code)
+}
@@ -0,0 +1,258 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service"
+	"github.com/mbiwapa/gophermart.git/internal/domain/service/mocks"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestWebhookService_Create(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+
+	resolved := map[string][]netip.Addr{
+		"partner.example":  {netip.MustParseAddr("93.184.216.34")},
+		"internal.example": {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")},
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []entity.EventType
+		wantErr    error
+	}{
+		{
+			name:       "Created with a secret",
+			url:        "https://partner.example/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+		},
+		{
+			name:       "Public address",
+			url:        "https://93.184.216.34/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+		},
+		{
+			name:       "Loopback",
+			url:        "http://127.0.0.1:5432/",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Loopback IPv6",
+			url:        "http://[::1]/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "IPv4-mapped loopback",
+			url:        "http://[::ffff:127.0.0.1]/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Link-local metadata",
+			url:        "http://169.254.169.254/latest/meta-data/",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Private",
+			url:        "http://192.168.1.10/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Unspecified",
+			url:        "http://0.0.0.0:8080/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Host resolved to a private address",
+			url:        "https://internal.example/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLForbidden,
+		},
+		{
+			name:       "Host not resolved",
+			url:        "https://unknown.example/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLInvalid,
+		},
+		{
+			name:       "Not an http URL",
+			url:        "ftp://partner.example/hooks",
+			eventTypes: []entity.EventType{entity.EventOrderStatus},
+			wantErr:    entity.ErrWebhookURLInvalid,
+		},
+		{
+			name:       "Unknown event type",
+			url:        "https://partner.example/hooks",
+			eventTypes: []entity.EventType{"order_deleted"},
+			wantErr:    entity.ErrWebhookEventTypeInvalid,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			repositoryMock := mocks.NewWebhookRepository(t)
+			if tc.wantErr == nil {
+				repositoryMock.On("CreateWebhook", mock.Anything, mock.AnythingOfType("*entity.Webhook")).
+					Return(nil).
+					Once()
+			}
+			resolverMock := mocks.NewHostResolver(t)
+			resolverMock.On("LookupNetIP", mock.Anything, "ip", mock.Anything).
+				Return(func(_ context.Context, _, host string) ([]netip.Addr, error) {
+					if addrs, ok := resolved[host]; ok {
+						return addrs, nil
+					}
+					return nil, errors.New("no such host")
+				}).
+				Maybe()
+			s := service.NewWebhookService(logger.NewLogger(), repositoryMock)
+			s.SetResolver(resolverMock)
+
+			webhook, err := s.Create(ctx, userUUID, tc.url, tc.eventTypes)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, webhook.Secret)
+			assert.Equal(t, userUUID, webhook.UserUUID)
+		})
+	}
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+	webhook := entity.Webhook{UUID: uuid.New(), UserUUID: userUUID}
+
+	repositoryMock := mocks.NewWebhookRepository(t)
+	repositoryMock.On("ListEventWebhooks", mock.Anything, userUUID, entity.EventOrderStatus).
+		Return([]entity.Webhook{webhook}, nil).
+		Once()
+	repositoryMock.On("AddDeliveries", mock.Anything, mock.MatchedBy(func(deliveries []entity.WebhookDelivery) bool {
+		if len(deliveries) != 1 || deliveries[0].WebhookUUID != webhook.UUID || deliveries[0].Status != entity.WebhookDeliveryPending {
+			return false
+		}
+		var payload struct {
+			ID   uuid.UUID         `json:"id"`
+			Type string            `json:"type"`
+			Data map[string]string `json:"data"`
+		}
+		return json.Unmarshal(deliveries[0].Payload, &payload) == nil &&
+			payload.ID == deliveries[0].UUID &&
+			payload.Type == "order_status" &&
+			payload.Data["number"] == "12345678903" &&
+			payload.Data["status"] == "PROCESSED"
+	})).
+		Return(nil).
+		Once()
+	s := service.NewWebhookService(logger.NewLogger(), repositoryMock)
+
+	s.Publish(ctx, entity.Event{
+		UserUUID: userUUID,
+		Type:     entity.EventOrderStatus,
+		Order:    &entity.Order{Number: "12345678903", Status: entity.OrderProcessed},
+	})
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+
+	tests := []struct {
+		name       string
+		attempts   int
+		code       int
+		sendErr    error
+		wantStatus entity.WebhookDeliveryStatus
+	}{
+		{
+			name:       "Delivered",
+			code:       200,
+			wantStatus: entity.WebhookDeliveryDelivered,
+		},
+		{
+			name:       "Server error is retried",
+			code:       500,
+			wantStatus: entity.WebhookDeliveryPending,
+		},
+		{
+			name:       "Network error is retried",
+			sendErr:    errors.New("connection refused"),
+			wantStatus: entity.WebhookDeliveryPending,
+		},
+		{
+			name:       "Last attempt fails the delivery",
+			attempts:   7,
+			code:       500,
+			wantStatus: entity.WebhookDeliveryFailed,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			delivery := entity.NewWebhookDelivery(uuid.New(), entity.EventBalance, []byte(`{"type":"balance"}`))
+			delivery.Attempts = tc.attempts
+			delivery.URL = "https://partner.example/hooks"
+			delivery.Secret = "secret"
+
+			repositoryMock := mocks.NewWebhookRepository(t)
+			repositoryMock.On("ClaimDueDeliveries", mock.Anything, 10, mock.Anything).
+				Return([]entity.WebhookDelivery{delivery}, nil).
+				Once()
+			repositoryMock.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+				if d.Status != tc.wantStatus || d.Attempts != tc.attempts+1 {
+					return false
+				}
+				if tc.wantStatus == entity.WebhookDeliveryPending {
+					// the first retry waits for the base backoff
+					return d.NextAttemptAt.After(time.Now().Add(5*time.Second)) && d.LastError != ""
+				}
+				return true
+			})).
+				Return(nil).
+				Once()
+
+			senderMock := mocks.NewWebhookSender(t)
+			senderMock.On("Send", mock.Anything, delivery.URL, mock.MatchedBy(func(headers map[string]string) bool {
+				timestamp, err := strconv.ParseInt(headers[service.WebhookTimestampHeader], 10, 64)
+				return err == nil &&
+					headers[service.WebhookDeliveryHeader] == delivery.UUID.String() &&
+					headers[service.WebhookSignatureHeader] == "sha256="+tool.SignWebhook("secret", timestamp, delivery.Payload)
+			}), delivery.Payload).
+				Return(tc.code, tc.sendErr).
+				Once()
+
+			s := service.NewWebhookService(logger.NewLogger(), repositoryMock)
+			s.SetSender(senderMock)
+
+			claimed, err := s.Deliver(ctx, 10)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+		})
+	}
+}
@@ -6,20 +6,35 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+const (
+	// JWTLifetime is how long an issued JWT stays valid.
+	JWTLifetime = time.Hour * 24
+	// ChallengeLifetime is how long a two-factor challenge token stays valid.
+	ChallengeLifetime = time.Minute * 5
+
+	challengeAudience = "2fa"
 )
 
 // JWTClaims is struct for managing JWTs
 type JWTClaims struct {
 	jwt.RegisteredClaims
-	UserID uuid.UUID `json:"user_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	SessionID uuid.UUID   `json:"session_id"`
+	Role      entity.Role `json:"role"`
 }
 
-// CreateJWT creates a JWT for a user.
-func CreateJWT(userUUID uuid.UUID, secretKey string) (string, error) {
+// CreateJWT creates a JWT for a user session.
+func CreateJWT(userUUID, sessionUUID uuid.UUID, role entity.Role, secretKey string) (string, error) {
 	claims := JWTClaims{
-		UserID: userUUID,
+		UserID:    userUUID,
+		SessionID: sessionUUID,
+		Role:      role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(JWTLifetime)),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -31,8 +46,8 @@ func CreateJWT(userUUID uuid.UUID, secretKey string) (string, error) {
 	return jwtString, nil
 }
 
-// CheckJWT verifies a JWT.
-func CheckJWT(tokenString string, secretKey string) (uuid.UUID, error) {
+// CheckJWT verifies a JWT and returns its claims.
+func CheckJWT(tokenString string, secretKey string) (*JWTClaims, error) {
 	claims := JWTClaims{}
 	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -41,12 +56,48 @@ func CheckJWT(tokenString string, secretKey string) (uuid.UUID, error) {
 		return []byte(secretKey), nil
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if len(claims.Audience) > 0 {
+		return nil, fmt.Errorf("token is not a session token")
+	}
+
+	return &claims, nil
+}
+
+// CreateChallengeJWT creates a short-lived token that proves the password step of two-factor login.
+// It is not accepted by CheckJWT.
+func CreateChallengeJWT(userUUID uuid.UUID, secretKey string) (string, error) {
+	claims := JWTClaims{
+		UserID: userUUID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeLifetime)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secretKey))
+}
+
+// CheckChallengeJWT verifies a two-factor challenge token and returns the user UUID.
+func CheckChallengeJWT(tokenString string, secretKey string) (uuid.UUID, error) {
+	claims := JWTClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil {
 		return uuid.Nil, err
 	}
+	if !token.Valid || !claims.VerifyAudience(challengeAudience, true) {
+		return uuid.Nil, fmt.Errorf("invalid challenge token")
+	}
 
 	return claims.UserID, nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
 )
 
@@ -15,8 +16,10 @@ func TestCreateJWT(t *testing.T) {
 		t.Fatalf("Failed to create user UUID: %v", err)
 	}
 
+	sessionUUID := uuid.New()
+
 	secretKey := "secret"
-	jwtString, err := tool.CreateJWT(userUUID, secretKey)
+	jwtString, err := tool.CreateJWT(userUUID, sessionUUID, entity.RoleAdmin, secretKey)
 	if err != nil {
 		t.Fatalf("Failed to create JWT: %v", err)
 	}
@@ -32,23 +35,70 @@ func TestCreateJWT(t *testing.T) {
 	if claims.UserID != userUUID {
 		t.Errorf("Expected user ID %v, got %v", userUUID, claims.UserID)
 	}
+	if claims.SessionID != sessionUUID {
+		t.Errorf("Expected session ID %v, got %v", sessionUUID, claims.SessionID)
+	}
+	if claims.Role != entity.RoleAdmin {
+		t.Errorf("Expected role %v, got %v", entity.RoleAdmin, claims.Role)
+	}
 }
 
 func TestCheckJWT(t *testing.T) {
 	userUUID := uuid.New()
+	sessionUUID := uuid.New()
 
 	secretKey := "secret"
-	jwtString, err := tool.CreateJWT(userUUID, secretKey)
+	jwtString, err := tool.CreateJWT(userUUID, sessionUUID, entity.RoleAdmin, secretKey)
 	if err != nil {
 		t.Fatalf("Failed to create JWT: %v", err)
 	}
 
-	userID, err := tool.CheckJWT(jwtString, secretKey)
+	claims, err := tool.CheckJWT(jwtString, secretKey)
 	if err != nil {
 		t.Fatalf("Failed to verify JWT: %v", err)
 	}
 
+	if claims.UserID != userUUID {
+		t.Errorf("Expected user ID %v, got %v", userUUID, claims.UserID)
+	}
+	if claims.SessionID != sessionUUID {
+		t.Errorf("Expected session ID %v, got %v", sessionUUID, claims.SessionID)
+	}
+
+	_, err = tool.CheckJWT(jwtString, "another secret")
+	if err == nil {
+		t.Errorf("Expected error for JWT signed with another key")
+	}
+}
+
+func TestChallengeJWT(t *testing.T) {
+	userUUID := uuid.New()
+
+	secretKey := "secret"
+	challenge, err := tool.CreateChallengeJWT(userUUID, secretKey)
+	if err != nil {
+		t.Fatalf("Failed to create challenge JWT: %v", err)
+	}
+
+	userID, err := tool.CheckChallengeJWT(challenge, secretKey)
+	if err != nil {
+		t.Fatalf("Failed to verify challenge JWT: %v", err)
+	}
 	if userID != userUUID {
 		t.Errorf("Expected user ID %v, got %v", userUUID, userID)
 	}
+
+	_, err = tool.CheckJWT(challenge, secretKey)
+	if err == nil {
+		t.Errorf("Challenge JWT must not be accepted as a session JWT")
+	}
+
+	sessionJWT, err := tool.CreateJWT(userUUID, uuid.New(), entity.RoleUser, secretKey)
+	if err != nil {
+		t.Fatalf("Failed to create JWT: %v", err)
+	}
+	_, err = tool.CheckChallengeJWT(sessionJWT, secretKey)
+	if err == nil {
+		t.Errorf("Session JWT must not be accepted as a challenge JWT")
+	}
 }
@@ -0,0 +1,124 @@
+package tool
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// minLoginPartLength is the shortest login part that is searched for inside a password.
+const minLoginPartLength = 4
+
+// PasswordPolicy checks passwords on registration and on password change.
+type PasswordPolicy struct {
+	minLength  int
+	minClasses int
+	common     map[string]struct{}
+}
+
+// NewPasswordPolicy returns a new password policy.
+// The built-in list of common passwords is extended with the file at commonListFile, if it is set.
+func NewPasswordPolicy(minLength, minClasses int, commonListFile string) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		minLength:  minLength,
+		minClasses: minClasses,
+		common:     make(map[string]struct{}),
+	}
+	policy.addCommon(strings.NewReader(commonPasswords))
+
+	if commonListFile != "" {
+		file, err := os.Open(commonListFile)
+		if err != nil {
+			return nil, fmt.Errorf("open common passwords list: %w", err)
+		}
+		defer file.Close()
+		err = policy.addCommon(file)
+		if err != nil {
+			return nil, fmt.Errorf("read common passwords list: %w", err)
+		}
+	}
+
+	return policy, nil
+}
+
+// addCommon reads one password per line into the list of common passwords.
+func (p *PasswordPolicy) addCommon(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		password := strings.TrimSpace(scanner.Text())
+		if password == "" || strings.HasPrefix(password, "#") {
+			continue
+		}
+		p.common[strings.ToLower(password)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// Validate returns an error wrapping entity.ErrPasswordPolicy if the password is not allowed for the login.
+func (p *PasswordPolicy) Validate(login, password string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return entity.ErrPasswordTooShort
+	}
+
+	if countClasses(password) < p.minClasses {
+		return entity.ErrPasswordTooSimple
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if lowerPassword == strings.ToLower(login) {
+		return entity.ErrPasswordContainsLogin
+	}
+	for _, part := range loginParts(login) {
+		if strings.Contains(lowerPassword, part) {
+			return entity.ErrPasswordContainsLogin
+		}
+	}
+
+	if _, ok := p.common[lowerPassword]; ok {
+		return entity.ErrPasswordTooCommon
+	}
+
+	return nil
+}
+
+// countClasses returns how many of lower case, upper case, digit and other characters the password has.
+func countClasses(password string) int {
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return lower + upper + digit + other
+}
+
+// loginParts returns the lower-cased login and, for e-mail logins, its local part.
+func loginParts(login string) []string {
+	login = strings.ToLower(login)
+	parts := make([]string, 0, 2)
+	if utf8.RuneCountInString(login) >= minLoginPartLength {
+		parts = append(parts, login)
+	}
+	local, _, found := strings.Cut(login, "@")
+	if found && utf8.RuneCountInString(local) >= minLoginPartLength {
+		parts = append(parts, local)
+	}
+	return parts
+}
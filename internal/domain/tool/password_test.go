@@ -0,0 +1,54 @@
+package tool_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	listFile := filepath.Join(t.TempDir(), "common.txt")
+	err := os.WriteFile(listFile, []byte("Tr0ub4dor&3\n"), 0o600)
+	require.NoError(t, err)
+
+	policy, err := tool.NewPasswordPolicy(8, 3, listFile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		login    string
+		password string
+		wantErr  error
+	}{
+		{name: "Valid password", login: "user@example.com", password: "Corr3ct-Horse", wantErr: nil},
+		{name: "Too short", login: "user@example.com", password: "Ab1!", wantErr: entity.ErrPasswordTooShort},
+		{name: "Too few classes", login: "user@example.com", password: "onlylowercase", wantErr: entity.ErrPasswordTooSimple},
+		{name: "Equals login", login: "Ab1-Ab1-Ab1", password: "ab1-ab1-ab1", wantErr: entity.ErrPasswordContainsLogin},
+		{name: "Contains e-mail local part", login: "johnny@example.com", password: "Johnny2024!", wantErr: entity.ErrPasswordContainsLogin},
+		{name: "Built-in common password", login: "user@example.com", password: "P@ssw0rd", wantErr: entity.ErrPasswordTooCommon},
+		{name: "Common password from file", login: "user@example.com", password: "tr0ub4dor&3", wantErr: entity.ErrPasswordTooCommon},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := policy.Validate(tc.login, tc.password)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, entity.ErrPasswordPolicy)
+			}
+		})
+	}
+}
+
+func TestNewPasswordPolicy_MissingFile(t *testing.T) {
+	_, err := tool.NewPasswordPolicy(8, 2, filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
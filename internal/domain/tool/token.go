@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// secretTokenSize is the number of random bytes in a secret token.
+const secretTokenSize = 32
+
+// NewSecretToken returns a new random URL-safe token and its hash for storing.
+func NewSecretToken() (token string, hash string, err error) {
+	buf := make([]byte, secretTokenSize)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hash of a token in hex.
+// Secret tokens have enough entropy, so a fast hash is enough to store them.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// recoveryCodeSize is the number of random bytes in a recovery code.
+const recoveryCodeSize = 5
+
+// NewRecoveryCode returns a new human-friendly recovery code like "abcde-fghij".
+func NewRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeSize)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+	return code[:5] + "-" + code[5:], nil
+}
+
+// NormalizeRecoveryCode removes the separators and spaces the user may type in a recovery code.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// APIKeyPrefix marks API keys, so they can be told apart from JWTs in the Authorization header.
+const APIKeyPrefix = "gm_"
+
+// NewAPIKey returns a new API key and its hash for storing.
+func NewAPIKey() (key string, hash string, err error) {
+	token, _, err := NewSecretToken()
+	if err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + token
+	return key, HashToken(key), nil
+}
+
+// IsAPIKey reports whether the credential looks like an API key.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
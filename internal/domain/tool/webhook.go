@@ -0,0 +1,32 @@
+package tool
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// SignWebhook returns the hex HMAC-SHA256 of the timestamp and the body joined by a dot.
+// Signing the timestamp lets the receiver reject replayed deliveries.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidWebhookSignature reports whether the signature is the hex HMAC-SHA256 of the timestamp and the body.
+// The comparison takes constant time.
+func ValidWebhookSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(SignWebhook(secret, timestamp, body))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
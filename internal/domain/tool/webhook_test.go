@@ -0,0 +1,31 @@
+package tool_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/tool"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1700000000.{"type":"balance"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"f0424cc2e2176c76e245521aa149fc256bb21097c265164f1a435c68c183bcef",
+		tool.SignWebhook("secret", 1700000000, []byte(`{"type":"balance"}`)),
+	)
+	assert.NotEqual(t,
+		tool.SignWebhook("secret", 1700000000, []byte(`{"type":"balance"}`)),
+		tool.SignWebhook("secret", 1700000001, []byte(`{"type":"balance"}`)),
+	)
+}
+
+func TestValidWebhookSignature(t *testing.T) {
+	body := []byte(`{"order":"12345678903","status":"PROCESSED"}`)
+	signature := tool.SignWebhook("secret", 1700000000, body)
+
+	assert.True(t, tool.ValidWebhookSignature("secret", 1700000000, body, signature))
+	assert.False(t, tool.ValidWebhookSignature("other", 1700000000, body, signature))
+	assert.False(t, tool.ValidWebhookSignature("secret", 1700000001, body, signature))
+	assert.False(t, tool.ValidWebhookSignature("secret", 1700000000, body, "not hex"))
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+const (
+	// historySize is the number of the latest events of all users kept for resuming a stream,
+	// so the history does not grow with the number of users.
+	historySize = 10000
+	// subscriberBuffer is the number of events a subscriber may lag behind before it is dropped.
+	subscriberBuffer = 64
+)
+
+// Broker delivers events to the subscribed users within the process.
+type Broker struct {
+	mu     sync.Mutex
+	lastID int64
+	// history is a ring of the latest events, next is the position of the oldest event once it is full
+	history     []entity.Event
+	next        int
+	subscribers map[uuid.UUID]map[*subscriber]struct{}
+	logger      *logger.Logger
+}
+
+type subscriber struct {
+	events chan entity.Event
+	closed bool
+}
+
+// NewBroker returns a new event broker.
+func NewBroker(logger *logger.Logger) *Broker {
+	return &Broker{
+		// IDs start from the start time, so they keep growing across restarts
+		// and an ID remembered by a client before a restart does not hide new events.
+		lastID:      time.Now().UnixNano(),
+		subscribers: make(map[uuid.UUID]map[*subscriber]struct{}),
+		logger:      logger,
+	}
+}
+
+// Publish assigns the event an ID and sends it to the subscribers of the user.
+// A subscriber that does not keep up is dropped and may resume from the history.
+func (b *Broker) Publish(ctx context.Context, event entity.Event) {
+	const op = "infrastructure.events.Broker.Publish"
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if len(b.history) < historySize {
+		b.history = append(b.history, event)
+	} else {
+		b.history[b.next] = event
+		b.next = (b.next + 1) % historySize
+	}
+
+	for sub := range b.subscribers[event.UserUUID] {
+		select {
+		case sub.events <- event:
+		default:
+			b.logger.Info("Dropping slow subscriber",
+				b.logger.StringField("op", op),
+				b.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+				b.logger.AnyField("user_uuid", event.UserUUID),
+			)
+			b.remove(event.UserUUID, sub)
+		}
+	}
+}
+
+// Subscribe returns the events of the user published after lastEventID and a channel with the next events.
+// Zero lastEventID means no history. The channel is closed when the subscriber is dropped or cancel is called.
+func (b *Broker) Subscribe(userUUID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []entity.Event
+	if lastEventID > 0 {
+		for i := range b.history {
+			event := b.history[(b.next+i)%len(b.history)]
+			if event.UserUUID == userUUID && event.ID > lastEventID {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub := &subscriber{events: make(chan entity.Event, subscriberBuffer)}
+	if b.subscribers[userUUID] == nil {
+		b.subscribers[userUUID] = make(map[*subscriber]struct{})
+	}
+	b.subscribers[userUUID][sub] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userUUID, sub)
+	}
+	return backlog, sub.events, cancel
+}
+
+// remove closes the subscriber channel, b.mu must be held.
+func (b *Broker) remove(userUUID uuid.UUID, sub *subscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.events)
+	delete(b.subscribers[userUUID], sub)
+	if len(b.subscribers[userUUID]) == 0 {
+		delete(b.subscribers, userUUID)
+	}
+}
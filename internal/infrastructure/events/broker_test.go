@@ -0,0 +1,71 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/events"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestBroker(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	userUUID := uuid.New()
+	b := events.NewBroker(logger.NewLogger())
+
+	_, live, cancel := b.Subscribe(userUUID, 0)
+	defer cancel()
+
+	b.Publish(ctx, entity.Event{UserUUID: userUUID, Type: entity.EventBalance, Balance: &entity.Balance{Current: 1}})
+	b.Publish(ctx, entity.Event{UserUUID: uuid.New(), Type: entity.EventBalance, Balance: &entity.Balance{Current: 2}})
+	b.Publish(ctx, entity.Event{UserUUID: userUUID, Type: entity.EventOrderStatus, Order: &entity.Order{Number: "12345678903"}})
+
+	first := <-live
+	second := <-live
+	assert.Equal(t, entity.EventBalance, first.Type)
+	assert.Equal(t, entity.EventOrderStatus, second.Type)
+	assert.Greater(t, second.ID, first.ID)
+	assert.False(t, first.CreatedAt.IsZero())
+
+	t.Run("Resume after the last event ID", func(t *testing.T) {
+		backlog, _, cancel := b.Subscribe(userUUID, first.ID)
+		defer cancel()
+		require.Len(t, backlog, 1)
+		assert.Equal(t, second.ID, backlog[0].ID)
+	})
+
+	t.Run("Oldest events of all users are dropped from the history", func(t *testing.T) {
+		otherUUID := uuid.New()
+		for i := 0; i < 10000; i++ {
+			b.Publish(ctx, entity.Event{UserUUID: otherUUID, Type: entity.EventBalance, Balance: &entity.Balance{}})
+		}
+		backlog, _, cancel := b.Subscribe(userUUID, first.ID)
+		cancel()
+		assert.Empty(t, backlog)
+
+		backlog, _, cancel = b.Subscribe(otherUUID, second.ID)
+		cancel()
+		require.Len(t, backlog, 10000)
+		assert.Less(t, backlog[0].ID, backlog[len(backlog)-1].ID)
+	})
+
+	t.Run("Slow subscriber is dropped", func(t *testing.T) {
+		slowUUID := uuid.New()
+		_, slow, cancel := b.Subscribe(slowUUID, 0)
+		defer cancel()
+		for i := 0; i < 100; i++ {
+			b.Publish(ctx, entity.Event{UserUUID: slowUUID, Type: entity.EventBalance, Balance: &entity.Balance{}})
+		}
+		received := 0
+		for range slow {
+			received++
+		}
+		assert.Less(t, received, 100)
+	})
+}
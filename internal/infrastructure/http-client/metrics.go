@@ -0,0 +1,94 @@
+package httpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+// Metrics collects the request metrics of the accrual system clients, one set per provider.
+// It is safe for concurrent use, a nil Metrics records nothing.
+type Metrics struct {
+	mu        sync.Mutex
+	providers map[string]*entity.AccrualClientMetrics
+}
+
+// NewMetrics returns an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{providers: make(map[string]*entity.AccrualClientMetrics)}
+}
+
+// provider returns the metrics of the provider, the caller holds the lock.
+func (m *Metrics) provider(name string) *entity.AccrualClientMetrics {
+	metrics, ok := m.providers[name]
+	if !ok {
+		metrics = &entity.AccrualClientMetrics{Provider: name, Responses: make(map[int]int64)}
+		m.providers[name] = metrics
+	}
+	return metrics
+}
+
+// started records a request sent to the provider.
+func (m *Metrics) started(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metrics := m.provider(name)
+	metrics.Requests++
+	metrics.InFlight++
+}
+
+// finished records the outcome of a request: the status code of the response, or 0 when it failed.
+func (m *Metrics) finished(name string, code int, timeout bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metrics := m.provider(name)
+	metrics.InFlight--
+	metrics.Duration += duration
+	if duration > metrics.MaxDuration {
+		metrics.MaxDuration = duration
+	}
+	switch {
+	case code != 0:
+		metrics.Responses[code]++
+	case timeout:
+		metrics.Errors++
+		metrics.Timeouts++
+	default:
+		metrics.Errors++
+	}
+}
+
+// rateLimited records a request not sent because of the provider rate limit.
+func (m *Metrics) rateLimited(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.provider(name).RateLimited++
+}
+
+// AccrualClientMetrics returns a copy of the metrics of every provider, sorted by the provider name.
+func (m *Metrics) AccrualClientMetrics() []entity.AccrualClientMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]entity.AccrualClientMetrics, 0, len(m.providers))
+	for _, metrics := range m.providers {
+		snapshot := *metrics
+		snapshot.Responses = make(map[int]int64, len(metrics.Responses))
+		for code, count := range metrics.Responses {
+			snapshot.Responses[code] = count
+		}
+		result = append(result, snapshot)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Provider < result[j].Provider })
+	return result
+}
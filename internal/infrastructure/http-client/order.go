@@ -1,96 +1,406 @@
 package httpc
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
 	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
 )
 
+// defaultOrderPath is the order path of the accrual system API, {number} is replaced with the order number.
+const defaultOrderPath = "/api/orders/{number}"
+
+// rateLimitWindow is the window the provider request limit is counted in.
+const rateLimitWindow = time.Minute
+
+// defaultRetryAfter is the pause after a 429 response without a valid Retry-After header.
+const defaultRetryAfter = time.Minute
+
+// maxResponseSize is the largest order response accepted.
+const maxResponseSize = 64 << 10
+
+// lastResponsesLimit is the number of orders the previous response is kept for,
+// the least recently polled order is dropped first.
+const lastResponsesLimit = 10000
+
+// ResponseAuditor is an interface for storing the raw accrual system responses.
+type ResponseAuditor interface {
+	AddAccrualResponse(ctx context.Context, response entity.AccrualResponse) error
+}
+
+// ProviderOptions describes the API of an accrual system.
+type ProviderOptions struct {
+	// Name identifies the provider in the metrics.
+	Name string
+	URL  string
+	// Path is the order path template, {number} is replaced with the order number.
+	Path string
+	// AuthHeader and AuthToken are sent with every request when set.
+	AuthHeader string
+	AuthToken  string
+	// RateLimit is the number of requests per minute, 0 is unlimited.
+	RateLimit int
+	Fields    ResponseFields
+	// Statuses maps the provider statuses to the order statuses, the statuses are taken as they are when empty.
+	Statuses  map[string]entity.Status
+	Transport TransportOptions
+}
+
+// ResponseFields are the names of the order response fields.
+type ResponseFields struct {
+	Order   string
+	Status  string
+	Accrual string
+}
+
 // OrderClient структура возвращаемая для работы, клиент
 type OrderClient struct {
-	url    string
-	client *http.Client
-	logger *logger.Logger
+	options ProviderOptions
+	client  *http.Client
+	metrics *Metrics
+	auditor ResponseAuditor
+	logger  *logger.Logger
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	// lastResponses indexes the elements of recentOrders, the most recently polled order is in front
+	lastResponses      map[entity.OrderNumber]*list.Element
+	recentOrders       *list.List
+	lastResponsesLimit int
 }
 
-// NewOrderClient возвращает экземпляр клиента
-func NewOrderClient(url string, logger *logger.Logger) (*OrderClient, error) {
+// lastResponse is the previous response of an order kept to skip the repeated audit.
+type lastResponse struct {
+	number entity.OrderNumber
+	key    string
+}
+
+// NewOrderClient возвращает экземпляр клиента, metrics может быть nil
+func NewOrderClient(options ProviderOptions, metrics *Metrics, logger *logger.Logger) (*OrderClient, error) {
+	if _, err := url.ParseRequestURI(options.URL); err != nil {
+		return nil, fmt.Errorf("invalid accrual system url %q: %w", options.URL, err)
+	}
+	if options.Path == "" {
+		options.Path = defaultOrderPath
+	}
+	if options.Fields.Order == "" {
+		options.Fields.Order = "order"
+	}
+	if options.Fields.Status == "" {
+		options.Fields.Status = "status"
+	}
+	if options.Fields.Accrual == "" {
+		options.Fields.Accrual = "accrual"
+	}
+	transport, err := newTransport(options.Transport)
+	if err != nil {
+		return nil, err
+	}
 	var client OrderClient
-	client.url = url
+	client.options = options
 	client.client = &http.Client{
-		Transport: &http.Transport{},
+		Transport: transport,
 	}
+	client.metrics = metrics
 	client.logger = logger
+	client.lastResponses = make(map[entity.OrderNumber]*list.Element)
+	client.recentOrders = list.New()
+	client.lastResponsesLimit = lastResponsesLimit
 	return &client, nil
 }
 
-// get отправляет запрос к указанному адресу и возвращает ответ
-func (c *OrderClient) get(ctx context.Context, path string) ([]byte, error) {
+// SetAuditor sets the storage of the raw responses.
+func (c *OrderClient) SetAuditor(auditor ResponseAuditor) {
+	c.auditor = auditor
+}
+
+// allow counts the request in the current window and reports whether it is within the rate limit,
+// otherwise it returns the time left until the window resets.
+func (c *OrderClient) allow() (bool, time.Duration) {
+	if c.options.RateLimit <= 0 {
+		return true, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.windowStart) >= rateLimitWindow {
+		c.windowStart = now
+		c.windowCount = 0
+	}
+	if c.windowCount >= c.options.RateLimit {
+		return false, c.windowStart.Add(rateLimitWindow).Sub(now)
+	}
+	c.windowCount++
+	return true, 0
+}
+
+// retryAfter parses the Retry-After header given in seconds or as an HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return defaultRetryAfter
+}
+
+// get отправляет запрос к указанному адресу и возвращает код, заголовки и тело ответа
+func (c *OrderClient) get(ctx context.Context, path string) (int, http.Header, []byte, error) {
 	const op = "http-client.send.get"
-	log := c.logger.With(c.logger.StringField("op", op))
+	log := c.logger.With(c.logger.StringField("op", op), c.logger.StringField("provider", c.options.Name))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", c.url+path, nil)
-	log.Info("Send order request", log.AnyField("path", c.url+path))
+	if ok, wait := c.allow(); !ok {
+		log.Info("Provider rate limit exceeded")
+		c.metrics.rateLimited(c.options.Name)
+		return 0, nil, nil, &entity.RateLimitError{RetryAfter: wait}
+	}
+	if c.options.Transport.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Transport.RequestTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.options.URL+path, nil)
+	log.Info("Send order request", log.AnyField("path", c.options.URL+path))
 	if err != nil {
 		log.Error("Cant create request", log.ErrorField(err))
-		return nil, err
+		return 0, nil, nil, err
 	}
-	req.Close = true // Close the connection after sending the request
-
 	req.Header.Set("Content-Type", "application/json")
+	if c.options.AuthHeader != "" {
+		req.Header.Set(c.options.AuthHeader, c.options.AuthToken)
+	}
 
+	c.metrics.started(c.options.Name)
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		c.metrics.finished(c.options.Name, 0, timeout(err), time.Since(start))
 		log.Error("Failed to send request", log.ErrorField(err))
-		return nil, err
+		return 0, nil, nil, err
 	}
-	//TODO это должно быть в другом методе, это просто трансопрт
-	if resp.StatusCode != http.StatusOK {
-		log.Info("No response", log.AnyField("code", resp.StatusCode))
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, entity.ErrExternalOrderNotRegistered
+	defer func() {
+		// the body is drained, so the connection goes back to the pool
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		err := resp.Body.Close()
+		if err != nil {
+			log.Error("Failed to close response body", log.ErrorField(err))
 		}
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, entity.ErrExternalOrderRateLimitExceeded
-		}
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+		c.metrics.finished(c.options.Name, resp.StatusCode, false, time.Since(start))
+	}()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// one byte over the limit tells a truncated body
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
 	if err != nil {
 		log.Error("Cant  read response", log.ErrorField(err))
-		return nil, err
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		log.Error("Failed to close response body", log.ErrorField(err))
+		return 0, nil, nil, err
 	}
-	return bodyBytes, nil
+	return resp.StatusCode, resp.Header, bodyBytes, nil
+}
 
+// timeout reports whether the request failed by a deadline.
+func timeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
 // Check возвращает информацию о заказе по номеру
-func (c *OrderClient) Check(ctx context.Context, number int) (entity.Order, error) {
+func (c *OrderClient) Check(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
 	const op = "http-client.send.GetOrderInfo"
-	log := c.logger.With(c.logger.StringField("op", op))
+	log := c.logger.With(c.logger.StringField("op", op), c.logger.StringField("provider", c.options.Name))
 
-	stringNumber := fmt.Sprintf("%d", number)
-	path := "/api/orders/" + stringNumber
-	bodyBytes, err := c.get(ctx, path)
+	path := strings.ReplaceAll(c.options.Path, "{number}", url.PathEscape(string(number)))
+	code, header, bodyBytes, err := c.get(ctx, path)
 	if err != nil {
 		return entity.Order{}, err
 	}
 
-	var order entity.Order
-	err = json.Unmarshal(bodyBytes, &order)
+	switch code {
+	case http.StatusOK:
+		order, err := c.decode(number, bodyBytes)
+		if err != nil {
+			log.Error("Invalid accrual response", log.ErrorField(err))
+			c.audit(ctx, number, code, bodyBytes, err)
+			return entity.Order{}, err
+		}
+		c.audit(ctx, number, code, bodyBytes, nil)
+		if order.Status.Final() {
+			c.forget(number)
+		}
+		return order, nil
+	case http.StatusNoContent:
+		return entity.Order{}, entity.ErrExternalOrderNotRegistered
+	case http.StatusTooManyRequests:
+		return entity.Order{}, &entity.RateLimitError{RetryAfter: retryAfter(header)}
+	default:
+		log.Info("Unexpected response", log.AnyField("code", code))
+		err = fmt.Errorf("unexpected status code: %d", code)
+		if code >= http.StatusInternalServerError {
+			err = fmt.Errorf("%w: status code %d", entity.ErrExternalUnavailable, code)
+		}
+		c.audit(ctx, number, code, bodyBytes, err)
+		return entity.Order{}, err
+	}
+}
+
+// accrualResponse is an order in the accrual system, decoded by the configured field names.
+// The fields are raw to tell an absent field from a zero one.
+type accrualResponse struct {
+	Order   json.RawMessage
+	Status  json.RawMessage
+	Accrual json.RawMessage
+}
+
+// invalid wraps the reason of a rejected response.
+func invalid(reason error, format string, args ...any) error {
+	return fmt.Errorf("%w: %w: %s", entity.ErrExternalResponseInvalid, reason, fmt.Sprintf(format, args...))
+}
+
+// decode validates the response and maps it to an order: the response must echo the requested number,
+// sent as a string or as a JSON number, the status must map to a known order status
+// and the accrual, absent or null for no accrual, must be a non-negative number.
+func (c *OrderClient) decode(number entity.OrderNumber, body []byte) (entity.Order, error) {
+	if len(body) > maxResponseSize {
+		return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "response is larger than %d bytes", maxResponseSize)
+	}
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(body, &fields)
+	if err != nil || fields == nil {
+		return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "response is not a JSON object")
+	}
+	response := accrualResponse{
+		Order:   fields[c.options.Fields.Order],
+		Status:  fields[c.options.Fields.Status],
+		Accrual: fields[c.options.Fields.Accrual],
+	}
+
+	var echo string
+	raw := strings.TrimSpace(string(response.Order))
+	switch {
+	case raw == "" || raw == "null":
+		return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "field %q is missing", c.options.Fields.Order)
+	case strings.HasPrefix(raw, `"`):
+		err = json.Unmarshal(response.Order, &echo)
+		if err != nil {
+			return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "field %q is not a string", c.options.Fields.Order)
+		}
+	default:
+		echo = raw
+	}
+	if entity.OrderNumber(echo) != number {
+		return entity.Order{}, invalid(entity.ErrExternalOrderMismatch, "requested %s, received %q", number, echo)
+	}
+
+	var external string
+	err = json.Unmarshal(response.Status, &external)
 	if err != nil {
-		log.Error("Cant unmarshal response", log.ErrorField(err))
-		return order, err
+		return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "field %q is missing or not a string", c.options.Fields.Status)
+	}
+	status := entity.Status(external)
+	if c.options.Statuses != nil {
+		status = c.options.Statuses[external]
+	}
+	if !status.External() {
+		return entity.Order{}, invalid(entity.ErrExternalStatusUnknown, "status %q", external)
+	}
+
+	var accrual float64
+	if len(response.Accrual) > 0 && string(response.Accrual) != "null" {
+		err = json.Unmarshal(response.Accrual, &accrual)
+		if err != nil {
+			return entity.Order{}, invalid(entity.ErrExternalResponseMalformed, "field %q is not a number", c.options.Fields.Accrual)
+		}
+		if accrual < 0 || math.IsInf(accrual, 0) {
+			return entity.Order{}, invalid(entity.ErrExternalAccrualInvalid, "accrual %v", accrual)
+		}
+	}
+
+	return entity.Order{
+		Number:  number,
+		Status:  status,
+		Accrual: accrual,
+	}, nil
+}
+
+// audit stores the raw response unless it repeats the previous one of the order,
+// so polling an order in the same state does not flood the audit.
+func (c *OrderClient) audit(ctx context.Context, number entity.OrderNumber, code int, body []byte, reason error) {
+	if c.auditor == nil {
+		return
+	}
+	key := strconv.Itoa(code) + " " + string(body)
+	if !c.remember(number, key) {
+		return
+	}
+
+	response := entity.AccrualResponse{
+		Provider:    c.options.Name,
+		OrderNumber: number,
+		StatusCode:  code,
+		Body:        body,
+		ReceivedAt:  time.Now(),
+	}
+	if reason != nil {
+		response.Error = reason.Error()
+	}
+	err := c.auditor.AddAccrualResponse(ctx, response)
+	if err != nil {
+		// the audit never blocks the order processing
+		c.logger.Error("Failed to audit accrual response", c.logger.ErrorField(err))
+	}
+}
+
+// remember keeps the response as the previous one of the order and reports whether it differs from the kept one.
+// The orders the service stops polling without a final status are dropped once the limit is reached.
+func (c *OrderClient) remember(number entity.OrderNumber, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.lastResponses[number]; ok {
+		c.recentOrders.MoveToFront(element)
+		previous := element.Value.(*lastResponse)
+		if previous.key == key {
+			return false
+		}
+		previous.key = key
+		return true
+	}
+	c.lastResponses[number] = c.recentOrders.PushFront(&lastResponse{number: number, key: key})
+	for c.recentOrders.Len() > c.lastResponsesLimit {
+		oldest := c.recentOrders.Back()
+		c.recentOrders.Remove(oldest)
+		delete(c.lastResponses, oldest.Value.(*lastResponse).number)
+	}
+	return true
+}
+
+// forget drops the previous response of an order that will not be polled any more.
+func (c *OrderClient) forget(number entity.OrderNumber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.lastResponses[number]; ok {
+		c.recentOrders.Remove(element)
+		delete(c.lastResponses, number)
 	}
-	return order, nil
 }
@@ -0,0 +1,55 @@
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+type countingAuditor struct {
+	count int
+}
+
+func (a *countingAuditor) AddAccrualResponse(_ context.Context, _ entity.AccrualResponse) error {
+	a.count++
+	return nil
+}
+
+func TestOrderClient_Audit_Limit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the orders stay unfinished, so the client never forgets them by itself
+		number := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		_, _ = fmt.Fprintf(w, `{"order":%q,"status":"PROCESSING"}`, number)
+	}))
+	defer server.Close()
+
+	client, err := NewOrderClient(ProviderOptions{Name: "default", URL: server.URL}, nil, logger.NewLogger())
+	require.NoError(t, err)
+	client.lastResponsesLimit = 2
+	auditor := &countingAuditor{}
+	client.SetAuditor(auditor)
+
+	for _, number := range []entity.OrderNumber{"1", "2", "1", "3"} {
+		_, err = client.Check(context.Background(), number)
+		require.NoError(t, err)
+	}
+	// the repeated response of order 1 is skipped, order 2 is dropped as the least recently polled
+	assert.Equal(t, 3, auditor.count)
+	assert.Len(t, client.lastResponses, 2)
+	assert.Equal(t, 2, client.recentOrders.Len())
+	assert.NotContains(t, client.lastResponses, entity.OrderNumber("2"))
+
+	_, err = client.Check(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Equal(t, 4, auditor.count)
+	assert.Len(t, client.lastResponses, 2)
+}
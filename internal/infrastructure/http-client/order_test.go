@@ -0,0 +1,203 @@
+package httpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	httpc "github.com/mbiwapa/gophermart.git/internal/infrastructure/http-client"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestOrderClient_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/orders/12345678903":
+			if r.Header.Get("X-Api-Key") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 12345678903, "state": "done", "points": 729.98}`))
+		case "/v2/orders/79927398713":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	metrics := httpc.NewMetrics()
+	client, err := httpc.NewOrderClient(httpc.ProviderOptions{
+		Name:       "partner",
+		URL:        server.URL,
+		Path:       "/v2/orders/{number}",
+		AuthHeader: "X-Api-Key",
+		AuthToken:  "secret",
+		Fields:     httpc.ResponseFields{Order: "id", Status: "state", Accrual: "points"},
+		Statuses:   map[string]entity.Status{"done": entity.OrderProcessed},
+		Transport:  httpc.TransportOptions{MaxIdleConnsPerHost: 2, RequestTimeout: 50 * time.Millisecond},
+	}, metrics, logger.NewLogger())
+	require.NoError(t, err)
+
+	order, err := client.Check(context.Background(), "12345678903")
+	require.NoError(t, err)
+	assert.Equal(t, entity.Order{Number: "12345678903", Status: entity.OrderProcessed, Accrual: 729.98}, order)
+
+	_, err = client.Check(context.Background(), "79927398713")
+	assert.ErrorIs(t, err, entity.ErrExternalOrderNotRegistered)
+
+	_, err = client.Check(context.Background(), "4561261212345467")
+	assert.Error(t, err)
+
+	snapshot := metrics.AccrualClientMetrics()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "partner", snapshot[0].Provider)
+	assert.Equal(t, int64(3), snapshot[0].Requests)
+	assert.Equal(t, int64(0), snapshot[0].InFlight)
+	assert.Equal(t, map[int]int64{http.StatusOK: 1, http.StatusNoContent: 1}, snapshot[0].Responses)
+	assert.Equal(t, int64(1), snapshot[0].Errors)
+	assert.Equal(t, int64(1), snapshot[0].Timeouts)
+}
+
+func TestOrderClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	metrics := httpc.NewMetrics()
+	client, err := httpc.NewOrderClient(httpc.ProviderOptions{Name: "default", URL: server.URL, RateLimit: 1}, metrics, logger.NewLogger())
+	require.NoError(t, err)
+
+	_, err = client.Check(context.Background(), "12345678903")
+	assert.ErrorIs(t, err, entity.ErrExternalOrderNotRegistered)
+	_, err = client.Check(context.Background(), "12345678903")
+	assert.ErrorIs(t, err, entity.ErrExternalOrderRateLimitExceeded)
+	// the pause lasts until the window resets
+	var rateLimitErr *entity.RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.Greater(t, rateLimitErr.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, rateLimitErr.RetryAfter, time.Minute)
+
+	snapshot := metrics.AccrualClientMetrics()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, int64(1), snapshot[0].Requests)
+	assert.Equal(t, int64(1), snapshot[0].RateLimited)
+}
+
+func TestOrderClient_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "Seconds", retryAfter: "3", want: 3 * time.Second},
+		{name: "Date in the past", retryAfter: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+		{name: "No header", want: time.Minute},
+		{name: "Invalid header", retryAfter: "soon", want: time.Minute},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+			client, err := httpc.NewOrderClient(httpc.ProviderOptions{Name: "default", URL: server.URL}, nil, logger.NewLogger())
+			require.NoError(t, err)
+
+			_, err = client.Check(context.Background(), "12345678903")
+
+			var rateLimitErr *entity.RateLimitError
+			require.ErrorAs(t, err, &rateLimitErr)
+			assert.Equal(t, tc.want, rateLimitErr.RetryAfter)
+		})
+	}
+}
+
+func TestNewOrderClient_InvalidTransport(t *testing.T) {
+	_, err := httpc.NewOrderClient(httpc.ProviderOptions{URL: "http://localhost:8080", Transport: httpc.TransportOptions{
+		ClientCert: "client.pem",
+	}}, nil, logger.NewLogger())
+	assert.Error(t, err)
+
+	_, err = httpc.NewOrderClient(httpc.ProviderOptions{URL: "http://localhost:8080", Transport: httpc.TransportOptions{
+		CACert: "missing.pem",
+	}}, nil, logger.NewLogger())
+	assert.Error(t, err)
+}
+
+type auditorMock struct {
+	responses []entity.AccrualResponse
+}
+
+func (a *auditorMock) AddAccrualResponse(_ context.Context, response entity.AccrualResponse) error {
+	a.responses = append(a.responses, response)
+	return nil
+}
+
+func TestOrderClient_Check_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{name: "Not JSON", body: `<html>`, err: entity.ErrExternalResponseMalformed},
+		{name: "No order", body: `{"status":"PROCESSED"}`, err: entity.ErrExternalResponseMalformed},
+		{name: "Another order", body: `{"order":"79927398713","status":"PROCESSED"}`, err: entity.ErrExternalOrderMismatch},
+		{name: "Unknown status", body: `{"order":"12345678903","status":"DONE"}`, err: entity.ErrExternalStatusUnknown},
+		{name: "Status not string", body: `{"order":"12345678903","status":1}`, err: entity.ErrExternalResponseMalformed},
+		{name: "Negative accrual", body: `{"order":"12345678903","status":"PROCESSED","accrual":-1}`, err: entity.ErrExternalAccrualInvalid},
+		{name: "Accrual not number", body: `{"order":"12345678903","status":"PROCESSED","accrual":"1"}`, err: entity.ErrExternalResponseMalformed},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client, err := httpc.NewOrderClient(httpc.ProviderOptions{Name: "default", URL: server.URL}, nil, logger.NewLogger())
+			require.NoError(t, err)
+			auditor := &auditorMock{}
+			client.SetAuditor(auditor)
+
+			_, err = client.Check(context.Background(), "12345678903")
+			assert.ErrorIs(t, err, entity.ErrExternalResponseInvalid)
+			assert.ErrorIs(t, err, tc.err)
+
+			// the same response again is not audited twice
+			_, err = client.Check(context.Background(), "12345678903")
+			assert.Error(t, err)
+			require.Len(t, auditor.responses, 1)
+			assert.Equal(t, tc.body, string(auditor.responses[0].Body))
+			assert.NotEmpty(t, auditor.responses[0].Error)
+		})
+	}
+}
+
+func TestOrderClient_Check_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client, err := httpc.NewOrderClient(httpc.ProviderOptions{Name: "default", URL: server.URL}, nil, logger.NewLogger())
+	require.NoError(t, err)
+
+	_, err = client.Check(context.Background(), "12345678903")
+
+	assert.ErrorIs(t, err, entity.ErrExternalUnavailable)
+}
@@ -0,0 +1,103 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// keepAlive is the period of the TCP keep-alive probes of the accrual system connections.
+const keepAlive = 30 * time.Second
+
+// TransportOptions configures the connections to an accrual system. Zero durations and limits disable them.
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	// RequestTimeout limits a whole request, including reading the response body.
+	RequestTimeout time.Duration
+
+	// ClientCert and ClientKey are the PEM files of the client certificate for mTLS.
+	ClientCert string
+	ClientKey  string
+	// CACert is the PEM file of the certificates the server certificate is verified with instead of the system ones.
+	CACert string
+	// Proxy is the proxy url, the proxy is taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY when empty.
+	Proxy string
+}
+
+// newTransport returns a pooled transport configured by the options.
+func newTransport(options TransportOptions) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout:   options.DialTimeout,
+		KeepAlive: keepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          options.MaxIdleConns,
+		MaxIdleConnsPerHost:   options.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       options.MaxConnsPerHost,
+		IdleConnTimeout:       options.IdleConnTimeout,
+		TLSHandshakeTimeout:   options.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: options.ResponseHeaderTimeout,
+	}
+
+	if options.Proxy != "" {
+		proxy, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.ClientCert != "" || options.ClientKey != "" || options.CACert != "" {
+		tlsConfig, err := newTLSConfig(options)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// newTLSConfig returns the TLS configuration with the client certificate and the trusted certificates.
+func newTLSConfig(options TransportOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if options.ClientCert != "" || options.ClientKey != "" {
+		if options.ClientCert == "" || options.ClientKey == "" {
+			return nil, errors.New("client certificate and key must be set together")
+		}
+		certificate, err := tls.LoadX509KeyPair(options.ClientCert, options.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if options.CACert != "" {
+		pem, err := os.ReadFile(options.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("read ca certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in ca certificate file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
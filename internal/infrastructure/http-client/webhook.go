@@ -0,0 +1,82 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/netguard"
+)
+
+// webhookTimeout limits a single webhook delivery, so a slow partner does not stall the worker.
+const webhookTimeout = 10 * time.Second
+
+// WebhookClient posts webhook deliveries to the partner endpoints.
+type WebhookClient struct {
+	client *http.Client
+	logger *logger.Logger
+}
+
+// NewWebhookClient returns a new webhook client.
+// The webhook URLs are given by users, so the client connects only to public addresses, checked after
+// every name resolution, does not use a proxy that would connect for it, and does not follow redirects.
+func NewWebhookClient(logger *logger.Logger) *WebhookClient {
+	return newWebhookClient(logger, netguard.Control)
+}
+
+// newWebhookClient returns a webhook client checking the addresses with the dialer control function.
+func newWebhookClient(logger *logger.Logger, control func(network, address string, c syscall.RawConn) error) *WebhookClient {
+	dialer := &net.Dialer{
+		Timeout:   webhookTimeout,
+		KeepAlive: keepAlive,
+		Control:   control,
+	}
+	return &WebhookClient{
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: webhookTimeout,
+			},
+			// a redirect is reported as the response, the partner has to fix the URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}
+}
+
+// Send posts the body with the headers to the url and returns the response status code.
+func (c *WebhookClient) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	const op = "http-client.WebhookClient.Send"
+	log := c.logger.With(c.logger.StringField("op", op), c.logger.StringField("url", url))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Error("Cant create request", log.ErrorField(err))
+		return 0, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Info("Failed to send webhook", log.ErrorField(err))
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// the body is drained, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,46 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+	"github.com/mbiwapa/gophermart.git/internal/lib/netguard"
+)
+
+func TestWebhookClient_Send_InternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := NewWebhookClient(logger.NewLogger()).Send(context.Background(), server.URL, nil, []byte("{}"))
+
+	assert.ErrorIs(t, err, netguard.ErrAddressForbidden)
+	assert.False(t, called)
+}
+
+func TestWebhookClient_Send_Redirect(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// the test server is on the loopback, the address check is left out
+	code, err := newWebhookClient(logger.NewLogger(), nil).Send(context.Background(), server.URL+"/hooks", nil, []byte("{}"))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, code)
+	assert.False(t, followed)
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccrualRepository is an in-memory implementation of the built-in accrual engine repository.
+type AccrualRepository struct {
+	storage *Storage
+	log     *logger.Logger
+}
+
+// NewAccrualRepository returns a new in-memory accrual repository
+func NewAccrualRepository(storage *Storage, log *logger.Logger) *AccrualRepository {
+	return &AccrualRepository{storage: storage, log: log}
+}
+
+// AddRewardRule adds a reward rule.
+func (r *AccrualRepository) AddRewardRule(ctx context.Context, rule entity.RewardRule) error {
+	const op = "infrastructure.memory.AccrualRepository.AddRewardRule"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("match", rule.Match),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	if _, ok := r.storage.rewardRules[rule.Match]; ok {
+		log.Info("Reward rule already exists")
+		return entity.ErrRewardRuleAlreadyExists
+	}
+	r.storage.rewardRules[rule.Match] = rule
+	return nil
+}
+
+// GetRewardRules returns all reward rules, the oldest first.
+func (r *AccrualRepository) GetRewardRules(_ context.Context) ([]entity.RewardRule, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var rules []entity.RewardRule
+	for _, rule := range r.storage.rewardRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if !rules[i].CreatedAt.Equal(rules[j].CreatedAt) {
+			return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+		}
+		return rules[i].Match < rules[j].Match
+	})
+	return rules, nil
+}
+
+// AddAccrualOrder adds an order with its goods and calculated accrual.
+func (r *AccrualRepository) AddAccrualOrder(ctx context.Context, order entity.AccrualOrder) error {
+	const op = "infrastructure.memory.AccrualRepository.AddAccrualOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", order.Number),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	if _, ok := r.storage.accrualOrders[order.Number]; ok {
+		log.Info("Accrual order already registered")
+		return entity.ErrAccrualOrderAlreadyRegistered
+	}
+	order.Goods = append([]entity.Good(nil), order.Goods...)
+	r.storage.accrualOrders[order.Number] = order
+	return nil
+}
+
+// GetAccrualOrder returns a registered order by its number.
+func (r *AccrualRepository) GetAccrualOrder(_ context.Context, number entity.OrderNumber) (entity.AccrualOrder, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	order, ok := r.storage.accrualOrders[number]
+	if !ok {
+		return entity.AccrualOrder{}, entity.ErrAccrualOrderNotFound
+	}
+	order.Goods = append([]entity.Good(nil), order.Goods...)
+	return order, nil
+}
+
+// AccrualResponseRepository is an in-memory implementation of the accrual system responses audit.
+type AccrualResponseRepository struct {
+	storage *Storage
+}
+
+// NewAccrualResponseRepository returns a new in-memory accrual responses repository
+func NewAccrualResponseRepository(storage *Storage) *AccrualResponseRepository {
+	return &AccrualResponseRepository{storage: storage}
+}
+
+// AddAccrualResponse stores a raw accrual system response.
+func (r *AccrualResponseRepository) AddAccrualResponse(_ context.Context, response entity.AccrualResponse) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	response.Body = append([]byte(nil), response.Body...)
+	r.storage.accrualResponses = append(r.storage.accrualResponses, response)
+	return nil
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+var (
+	// errBalanceNotFound is returned when the user has no balance, like a missing row in the database.
+	errBalanceNotFound = errors.New("balance not found")
+	// errBalanceExists is returned when the user already has a balance, like a duplicate row in the database.
+	errBalanceExists = errors.New("balance already exists")
+)
+
+// BalanceRepository is an in-memory implementation of balance repository.
+type BalanceRepository struct {
+	storage *Storage
+	log     *logger.Logger
+}
+
+// NewBalanceRepository returns a new in-memory balance repository
+func NewBalanceRepository(storage *Storage, log *logger.Logger) *BalanceRepository {
+	return &BalanceRepository{storage: storage, log: log}
+}
+
+// GetBalance returns the balance of the user
+func (r *BalanceRepository) GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error) {
+	const op = "infrastructure.memory.BalanceRepository.GetBalance"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	balance, ok := r.storage.balances[userUUID]
+	if !ok {
+		log.Error("Failed to get balance", log.ErrorField(errBalanceNotFound))
+		return nil, fmt.Errorf("%s: %w", op, errBalanceNotFound)
+	}
+	return &entity.Balance{Current: balance.Current, Withdraw: balance.Withdraw}, nil
+}
+
+// GetWithdrawOperations returns a list of withdrawal operations
+func (r *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	const op = "infrastructure.memory.BalanceRepository.GetWithdrawOperations"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var operations []entity.BalanceOperation
+	for _, operation := range r.storage.operations {
+		if operation.UserUUID == userUUID && operation.Type == entity.BalanceOperationWithdrawal {
+			operations = append(operations, operation)
+		}
+	}
+	if len(operations) == 0 {
+		log.Info("No withdraw operations found")
+		return nil, entity.ErrBalanceOperationsNotFound
+	}
+	return operations, nil
+}
+
+// GetOperations returns all balance operations of the user, the oldest first
+func (r *BalanceRepository) GetOperations(_ context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var operations []entity.BalanceOperation
+	for _, operation := range r.storage.operations {
+		if operation.UserUUID == userUUID {
+			operations = append(operations, operation)
+		}
+	}
+	sort.SliceStable(operations, func(i, j int) bool { return operations[i].ProcessedAt.Before(operations[j].ProcessedAt) })
+	return operations, nil
+}
+
+// ReassignUserBalance moves the balance and all balance operations of the user to another user UUID
+func (r *BalanceRepository) ReassignUserBalance(_ context.Context, fromUserUUID, toUserUUID uuid.UUID) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	balance, ok := r.storage.balances[fromUserUUID]
+	if ok {
+		delete(r.storage.balances, fromUserUUID)
+		balance.UserUUID = toUserUUID
+		r.storage.balances[toUserUUID] = balance
+	}
+	for i := range r.storage.operations {
+		if r.storage.operations[i].UserUUID == fromUserUUID {
+			r.storage.operations[i].UserUUID = toUserUUID
+		}
+	}
+	return nil
+}
+
+// CreateBalance creates a new balance for the user
+func (r *BalanceRepository) CreateBalance(ctx context.Context, userUUID uuid.UUID) error {
+	const op = "infrastructure.memory.BalanceRepository.CreateBalance"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	if _, ok := r.storage.balances[userUUID]; ok {
+		log.Error("Failed to create balance", log.ErrorField(errBalanceExists))
+		return fmt.Errorf("%s: %w", op, errBalanceExists)
+	}
+	r.storage.balances[userUUID] = entity.Balance{UserUUID: userUUID}
+	return nil
+}
+
+// Withdraw executes a balance operation
+func (r *BalanceRepository) Withdraw(ctx context.Context, operation entity.BalanceOperation) error {
+	const op = "infrastructure.memory.BalanceRepository.Withdraw"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", operation.UserUUID.String()),
+		r.log.AnyField("order_number", operation.OrderNumber),
+		r.log.AnyField("accrual", operation.Accrual),
+		r.log.AnyField("withdrawal", operation.Withdrawal),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	balance, ok := r.storage.balances[operation.UserUUID]
+	if !ok {
+		log.Error("Failed to get current balance", log.ErrorField(errBalanceNotFound))
+		return fmt.Errorf("%s: %w", op, errBalanceNotFound)
+	}
+	if balance.Current < operation.Withdrawal {
+		log.Error("Insufficient funds in the account")
+		return entity.ErrBalanceInsufficientFunds
+	}
+	balance.Current -= operation.Withdrawal
+	balance.Withdraw += operation.Withdrawal
+	r.storage.balances[operation.UserUUID] = balance
+	r.storage.operations = append(r.storage.operations, operation)
+	return nil
+}
+
+// Accrue executes an accrual, one per order
+func (r *BalanceRepository) Accrue(ctx context.Context, operation entity.BalanceOperation) error {
+	const op = "infrastructure.memory.BalanceRepository.Accrue"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", operation.UserUUID.String()),
+		r.log.AnyField("order_number", operation.OrderNumber),
+		r.log.AnyField("accrual", operation.Accrual),
+		r.log.AnyField("withdrawal", operation.Withdrawal),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	balance, ok := r.storage.balances[operation.UserUUID]
+	if !ok {
+		log.Error("Failed to get current balance", log.ErrorField(errBalanceNotFound))
+		return fmt.Errorf("%s: %w", op, errBalanceNotFound)
+	}
+	if r.storage.hasAccrual(operation.OrderNumber) {
+		log.Info("Accrual for the order already exists")
+		return entity.ErrBalanceAccrualExists
+	}
+
+	balance.Current += operation.Accrual
+	r.storage.balances[operation.UserUUID] = balance
+	r.storage.operations = append(r.storage.operations, operation)
+	return nil
+}
+
+// hasAccrual reports whether the order has an accrual, the caller holds the lock.
+func (s *Storage) hasAccrual(number entity.OrderNumber) bool {
+	for _, operation := range s.operations {
+		if operation.OrderNumber == number && operation.Type == entity.BalanceOperationAccrual {
+			return true
+		}
+	}
+	return false
+}
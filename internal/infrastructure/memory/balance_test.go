@@ -0,0 +1,120 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/memory"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestBalanceRepository(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewBalanceRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+	require.NoError(t, repository.CreateBalance(ctx, userUUID))
+	assert.Error(t, repository.CreateBalance(ctx, userUUID))
+	_, err := repository.GetBalance(ctx, uuid.New())
+	assert.Error(t, err)
+
+	require.NoError(t, repository.Accrue(ctx, entity.NewBalanceOperation(userUUID, 100, 0, "1")))
+
+	tests := []struct {
+		name      string
+		operation entity.BalanceOperation
+		accrue    bool
+		err       error
+	}{
+		{name: "Accrual exists", operation: entity.NewBalanceOperation(userUUID, 100, 0, "1"), accrue: true, err: entity.ErrBalanceAccrualExists},
+		{name: "Insufficient funds", operation: entity.NewBalanceOperation(userUUID, 0, 150, "2"), err: entity.ErrBalanceInsufficientFunds},
+		{name: "Withdraw", operation: entity.NewBalanceOperation(userUUID, 0, 40, "3")},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.accrue {
+				err = repository.Accrue(ctx, tc.operation)
+			} else {
+				err = repository.Withdraw(ctx, tc.operation)
+			}
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	balance, err := repository.GetBalance(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, balance.Current)
+	assert.Equal(t, 40.0, balance.Withdraw)
+
+	withdrawals, err := repository.GetWithdrawOperations(ctx, userUUID)
+	require.NoError(t, err)
+	require.Len(t, withdrawals, 1)
+	assert.Equal(t, entity.OrderNumber("3"), withdrawals[0].OrderNumber)
+
+	_, err = repository.GetWithdrawOperations(ctx, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrBalanceOperationsNotFound)
+}
+
+func TestBalanceRepository_Withdraw_Concurrent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewBalanceRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+	require.NoError(t, repository.CreateBalance(ctx, userUUID))
+	require.NoError(t, repository.Accrue(ctx, entity.NewBalanceOperation(userUUID, 100, 0, "accrual")))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repository.Withdraw(ctx, entity.NewBalanceOperation(userUUID, 0, 10, entity.OrderNumber(uuid.NewString())))
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	balance, err := repository.GetBalance(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, balance.Current)
+	assert.Equal(t, 100.0, balance.Withdraw)
+}
+
+func TestBalanceRepository_SameOrder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewBalanceRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+	require.NoError(t, repository.CreateBalance(ctx, userUUID))
+
+	require.NoError(t, repository.Accrue(ctx, entity.NewBalanceOperation(userUUID, 100, 0, "1")))
+	require.NoError(t, repository.Withdraw(ctx, entity.NewBalanceOperation(userUUID, 0, 30, "1")))
+	require.NoError(t, repository.Withdraw(ctx, entity.NewBalanceOperation(userUUID, 0, 20, "1")))
+	assert.ErrorIs(t, repository.Accrue(ctx, entity.NewBalanceOperation(userUUID, 100, 0, "1")), entity.ErrBalanceAccrualExists)
+
+	operations, err := repository.GetOperations(ctx, userUUID)
+	require.NoError(t, err)
+	require.Len(t, operations, 3)
+	assert.Equal(t, entity.BalanceOperationAccrual, operations[0].Type)
+	withdrawals, err := repository.GetWithdrawOperations(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Len(t, withdrawals, 2)
+
+	balance, err := repository.GetBalance(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, balance.Current)
+	assert.Equal(t, 50.0, balance.Withdraw)
+}
@@ -0,0 +1,313 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// OrderRepository is an in-memory implementation of order repository.
+type OrderRepository struct {
+	storage *Storage
+	log     *logger.Logger
+}
+
+// NewOrderRepository returns a new in-memory order repository
+func NewOrderRepository(storage *Storage, log *logger.Logger) *OrderRepository {
+	return &OrderRepository{storage: storage, log: log}
+}
+
+// AddOrderForUser adds a new order to the user.
+func (r *OrderRepository) AddOrderForUser(ctx context.Context, order entity.Order) error {
+	const op = "infrastructure.memory.OrderRepository.AddOrderForUser"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", order.Number),
+		r.log.AnyField("user_uuid", order.UserUUID),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.orders[order.Number]
+	if ok {
+		if stored.UserUUID == order.UserUUID {
+			log.Info("Order already uploaded from current user")
+			return entity.ErrOrderAlreadyUploaded
+		}
+		log.Info("Order already uploaded from another user")
+		return entity.ErrOrderAlreadyUploadedByAnotherUser
+	}
+	order.Accrual = 0
+	r.storage.orders[order.Number] = order
+	return nil
+}
+
+// AddOrdersForUser adds new orders to the user at once and returns the upload result of every number.
+// Numbers that are already uploaded are left as they are.
+func (r *OrderRepository) AddOrdersForUser(_ context.Context, userUUID uuid.UUID, orders []entity.Order) (map[entity.OrderNumber]entity.OrderUploadResult, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	results := make(map[entity.OrderNumber]entity.OrderUploadResult, len(orders))
+	for _, order := range orders {
+		stored, ok := r.storage.orders[order.Number]
+		switch {
+		case !ok:
+			r.storage.orders[order.Number] = entity.Order{
+				Number:     order.Number,
+				Status:     entity.OrderNew,
+				UserUUID:   userUUID,
+				UploadedAt: order.UploadedAt,
+				Store:      order.Store,
+			}
+			r.storage.orderHistory[order.Number] = append(r.storage.orderHistory[order.Number], entity.OrderStatusChange{
+				OrderNumber: order.Number,
+				Status:      entity.OrderNew,
+				ChangedAt:   order.UploadedAt,
+			})
+			results[order.Number] = entity.OrderUploadAccepted
+		case stored.UserUUID == userUUID:
+			results[order.Number] = entity.OrderUploadAlreadyUploaded
+		default:
+			results[order.Number] = entity.OrderUploadOwnedByAnotherUser
+		}
+	}
+	return results, nil
+}
+
+// GetAllUserOrders returns all orders for a user.
+func (r *OrderRepository) GetAllUserOrders(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
+	const op = "infrastructure.memory.OrderRepository.GetAllUserOrders"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	orders := r.storage.userOrders(userUUID, entity.OrderFilter{})
+	if len(orders) == 0 {
+		log.Info("No orders found")
+		return nil, entity.ErrOrderNotFound
+	}
+	return orders, nil
+}
+
+// GetUnfinishedOrders returns the NEW and PROCESSING orders of all users, the oldest first.
+func (r *OrderRepository) GetUnfinishedOrders(_ context.Context) ([]entity.Order, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var orders []entity.Order
+	for _, order := range r.storage.orders {
+		if order.Status == entity.OrderNew || order.Status == entity.OrderProcessing {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].UploadedAt.Equal(orders[j].UploadedAt) {
+			return orders[i].UploadedAt.Before(orders[j].UploadedAt)
+		}
+		return orders[i].Number < orders[j].Number
+	})
+	return orders, nil
+}
+
+// GetUserOrdersPage returns a page of the user orders selected by the filter.
+func (r *OrderRepository) GetUserOrdersPage(_ context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	orders := r.storage.userOrders(userUUID, filter)
+	page := entity.OrderPage{Total: len(orders)}
+	if filter.Descending {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+	if filter.After != nil {
+		start := len(orders)
+		for i, order := range orders {
+			if afterCursor(order, *filter.After, filter.Descending) {
+				start = i
+				break
+			}
+		}
+		orders = orders[start:]
+	}
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		last := orders[len(orders)-1]
+		page.Next = &entity.OrderCursor{UploadedAt: last.UploadedAt, Number: last.Number}
+	}
+	page.Orders = orders
+	return page, nil
+}
+
+// userOrders returns the orders of the user selected by the statuses and upload time of the filter,
+// ordered by upload time and number. The caller holds the lock.
+func (s *Storage) userOrders(userUUID uuid.UUID, filter entity.OrderFilter) []entity.Order {
+	statuses := make(map[entity.Status]bool, len(filter.Statuses))
+	for _, status := range filter.Statuses {
+		statuses[status] = true
+	}
+	var orders []entity.Order
+	for _, order := range s.orders {
+		if order.UserUUID != userUUID ||
+			(len(statuses) > 0 && !statuses[order.Status]) ||
+			(!filter.UploadedFrom.IsZero() && order.UploadedAt.Before(filter.UploadedFrom)) ||
+			(!filter.UploadedTo.IsZero() && !order.UploadedAt.Before(filter.UploadedTo)) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].UploadedAt.Equal(orders[j].UploadedAt) {
+			return orders[i].UploadedAt.Before(orders[j].UploadedAt)
+		}
+		return orders[i].Number < orders[j].Number
+	})
+	return orders
+}
+
+// afterCursor reports whether the order follows the cursor in the page order.
+func afterCursor(order entity.Order, cursor entity.OrderCursor, descending bool) bool {
+	if order.UploadedAt.Equal(cursor.UploadedAt) {
+		if descending {
+			return order.Number < cursor.Number
+		}
+		return order.Number > cursor.Number
+	}
+	if descending {
+		return order.UploadedAt.Before(cursor.UploadedAt)
+	}
+	return order.UploadedAt.After(cursor.UploadedAt)
+}
+
+// UpdateOrderForUser sets the status and the accrual of an order of the user.
+// An order with a final status is not changed.
+func (r *OrderRepository) UpdateOrderForUser(_ context.Context, order entity.Order) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.orders[order.Number]
+	if ok && stored.UserUUID == order.UserUUID {
+		if stored.Status.Final() {
+			return entity.ErrOrderStatusFinal
+		}
+		stored.Status = order.Status
+		stored.Accrual = order.Accrual
+		r.storage.orders[order.Number] = stored
+	}
+	return nil
+}
+
+// GetUserOrder returns an order of the user by number.
+func (r *OrderRepository) GetUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, error) {
+	const op = "infrastructure.memory.OrderRepository.GetUserOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	order, ok := r.storage.orders[number]
+	if !ok || order.UserUUID != userUUID {
+		log.Info("Order not found")
+		return entity.Order{}, entity.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// GetOrderByNumber returns an order by its number regardless of the owner.
+func (r *OrderRepository) GetOrderByNumber(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
+	const op = "infrastructure.memory.OrderRepository.GetOrderByNumber"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	order, ok := r.storage.orders[number]
+	if !ok {
+		log.Info("Order not found")
+		return entity.Order{}, entity.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// AddOrderStatusChange records a status transition of an order.
+func (r *OrderRepository) AddOrderStatusChange(_ context.Context, change entity.OrderStatusChange) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	r.storage.orderHistory[change.OrderNumber] = append(r.storage.orderHistory[change.OrderNumber], change)
+	return nil
+}
+
+// GetOrderStatusHistory returns the status transitions of an order, the oldest first.
+func (r *OrderRepository) GetOrderStatusHistory(_ context.Context, number entity.OrderNumber) ([]entity.OrderStatusChange, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	history := append([]entity.OrderStatusChange(nil), r.storage.orderHistory[number]...)
+	sort.SliceStable(history, func(i, j int) bool { return history[i].ChangedAt.Before(history[j].ChangedAt) })
+	return history, nil
+}
+
+// RetractUserOrder removes a retractable order of the user with its status history
+// and records the retraction.
+func (r *OrderRepository) RetractUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.OrderRetraction, error) {
+	const op = "infrastructure.memory.OrderRepository.RetractUserOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	order, ok := r.storage.orders[number]
+	if !ok || order.UserUUID != userUUID {
+		log.Info("Order not found")
+		return entity.OrderRetraction{}, entity.ErrOrderNotFound
+	}
+	if !order.Status.Retractable() {
+		log.Info("Order is not retractable", log.AnyField("status", order.Status))
+		return entity.OrderRetraction{}, entity.ErrOrderNotRetractable
+	}
+
+	delete(r.storage.orders, number)
+	// the number may be uploaded again, by its real owner too, so the history starts anew
+	delete(r.storage.orderHistory, number)
+	retraction := entity.OrderRetraction{
+		OrderNumber: number,
+		UserUUID:    userUUID,
+		Status:      order.Status,
+		UploadedAt:  order.UploadedAt,
+		RetractedAt: time.Now(),
+	}
+	r.storage.retractions = append(r.storage.retractions, retraction)
+	return retraction, nil
+}
+
+// ReassignUserOrders moves all orders of the user to another user UUID.
+func (r *OrderRepository) ReassignUserOrders(_ context.Context, fromUserUUID, toUserUUID uuid.UUID) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for number, order := range r.storage.orders {
+		if order.UserUUID == fromUserUUID {
+			order.UserUUID = toUserUUID
+			r.storage.orders[number] = order
+		}
+	}
+	for i := range r.storage.retractions {
+		if r.storage.retractions[i].UserUUID == fromUserUUID {
+			r.storage.retractions[i].UserUUID = toUserUUID
+		}
+	}
+	return nil
+}
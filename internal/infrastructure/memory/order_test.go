@@ -0,0 +1,157 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/memory"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestOrderRepository_AddOrderForUser(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewOrderRepository(memory.NewStorage(), logger.NewLogger())
+	owner := uuid.New()
+	require.NoError(t, repository.AddOrderForUser(ctx, entity.Order{Number: "12345678903", Status: entity.OrderNew, UserUUID: owner}))
+
+	tests := []struct {
+		name  string
+		order entity.Order
+		err   error
+	}{
+		{name: "Same user", order: entity.Order{Number: "12345678903", UserUUID: owner}, err: entity.ErrOrderAlreadyUploaded},
+		{name: "Another user", order: entity.Order{Number: "12345678903", UserUUID: uuid.New()}, err: entity.ErrOrderAlreadyUploadedByAnotherUser},
+		{name: "New order", order: entity.Order{Number: "2377225624", UserUUID: owner}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, repository.AddOrderForUser(ctx, tc.order), tc.err)
+		})
+	}
+
+	results, err := repository.AddOrdersForUser(ctx, owner, []entity.Order{
+		{Number: "12345678903"},
+		{Number: "4561261212345467"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.OrderUploadAlreadyUploaded, results["12345678903"])
+	assert.Equal(t, entity.OrderUploadAccepted, results["4561261212345467"])
+
+	results, err = repository.AddOrdersForUser(ctx, uuid.New(), []entity.Order{{Number: "4561261212345467"}})
+	require.NoError(t, err)
+	assert.Equal(t, entity.OrderUploadOwnedByAnotherUser, results["4561261212345467"])
+
+	_, err = repository.GetAllUserOrders(ctx, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	_, err = repository.GetUserOrder(ctx, uuid.New(), "12345678903")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+}
+
+func TestOrderRepository_GetUserOrdersPage(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewOrderRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, number := range []entity.OrderNumber{"1", "2", "3", "4", "5"} {
+		status := entity.OrderNew
+		if i%2 == 1 {
+			status = entity.OrderProcessed
+		}
+		require.NoError(t, repository.AddOrderForUser(ctx, entity.Order{
+			Number:     number,
+			Status:     status,
+			UserUUID:   userUUID,
+			UploadedAt: start.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	numbers := func(orders []entity.Order) []entity.OrderNumber {
+		var result []entity.OrderNumber
+		for _, order := range orders {
+			result = append(result, order.Number)
+		}
+		return result
+	}
+
+	page, err := repository.GetUserOrdersPage(ctx, userUUID, entity.OrderFilter{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, []entity.OrderNumber{"1", "2"}, numbers(page.Orders))
+	require.NotNil(t, page.Next)
+
+	page, err = repository.GetUserOrdersPage(ctx, userUUID, entity.OrderFilter{Limit: 2, After: page.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.OrderNumber{"3", "4"}, numbers(page.Orders))
+
+	page, err = repository.GetUserOrdersPage(ctx, userUUID, entity.OrderFilter{Limit: 10, Descending: true, Statuses: []entity.Status{entity.OrderNew}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, []entity.OrderNumber{"5", "3", "1"}, numbers(page.Orders))
+	assert.Nil(t, page.Next)
+
+	page, err = repository.GetUserOrdersPage(ctx, userUUID, entity.OrderFilter{Limit: 10, UploadedFrom: start.Add(time.Hour), UploadedTo: start.Add(3 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.OrderNumber{"2", "3"}, numbers(page.Orders))
+}
+
+func TestOrderRepository_RetractUserOrder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewOrderRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+	require.NoError(t, repository.AddOrderForUser(ctx, entity.Order{Number: "1", Status: entity.OrderNew, UserUUID: userUUID}))
+	require.NoError(t, repository.AddOrderForUser(ctx, entity.Order{Number: "2", Status: entity.OrderProcessing, UserUUID: userUUID}))
+	require.NoError(t, repository.AddOrderStatusChange(ctx, entity.OrderStatusChange{OrderNumber: "1", Status: entity.OrderNew}))
+
+	tests := []struct {
+		name     string
+		userUUID uuid.UUID
+		number   entity.OrderNumber
+		err      error
+	}{
+		{name: "Another user", userUUID: uuid.New(), number: "1", err: entity.ErrOrderNotFound},
+		{name: "Processing", userUUID: userUUID, number: "2", err: entity.ErrOrderNotRetractable},
+		{name: "New", userUUID: userUUID, number: "1"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := repository.RetractUserOrder(ctx, tc.userUUID, tc.number)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	_, err := repository.GetOrderByNumber(ctx, "1")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	history, err := repository.GetOrderStatusHistory(ctx, "1")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestOrderRepository_UpdateOrderForUser(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewOrderRepository(memory.NewStorage(), logger.NewLogger())
+	order := entity.NewOrder(uuid.New(), "12345678903")
+	require.NoError(t, repository.AddOrderForUser(ctx, order))
+
+	processed := order
+	processed.Status = entity.OrderProcessed
+	processed.Accrual = 500
+	require.NoError(t, repository.UpdateOrderForUser(ctx, processed))
+
+	invalid := order
+	invalid.Status = entity.OrderInvalid
+	assert.ErrorIs(t, repository.UpdateOrderForUser(ctx, invalid), entity.ErrOrderStatusFinal)
+
+	stored, err := repository.GetUserOrder(ctx, order.UserUUID, order.Number)
+	require.NoError(t, err)
+	assert.Equal(t, entity.OrderProcessed, stored.Status)
+	assert.Equal(t, 500.0, stored.Accrual)
+}
@@ -0,0 +1,91 @@
+// Package memory implements the repositories in process memory, for local demos and tests.
+// The data is lost when the process exits.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+)
+
+// Storage holds the data of the in-memory repositories. The repositories created on the same storage
+// share the data like the postgre repositories share a database. A single lock makes every
+// repository call atomic, as the postgre repositories are with their transactions.
+type Storage struct {
+	mu sync.Mutex
+
+	users            map[uuid.UUID]*userRow
+	sessions         map[uuid.UUID]entity.Session
+	resetTokens      map[string]*resetToken
+	recoveryCodes    map[uuid.UUID][]*recoveryCode
+	apiKeys          map[uuid.UUID]*apiKey
+	orders           map[entity.OrderNumber]entity.Order
+	orderHistory     map[entity.OrderNumber][]entity.OrderStatusChange
+	retractions      []entity.OrderRetraction
+	balances         map[uuid.UUID]entity.Balance
+	operations       []entity.BalanceOperation
+	webhooks         map[uuid.UUID]entity.Webhook
+	deliveries       map[uuid.UUID]entity.WebhookDelivery
+	rewardRules      map[string]entity.RewardRule
+	accrualOrders    map[entity.OrderNumber]entity.AccrualOrder
+	accrualResponses []entity.AccrualResponse
+}
+
+// userRow is a stored user, a deleted user keeps its row like in the database.
+type userRow struct {
+	entity.User
+	deleted bool
+	// totpLastStep is the time step of the last accepted TOTP code, zero when there is none.
+	totpLastStep int64
+	// totpAttempts are the second factor attempts counted since totpAttemptsFrom.
+	totpAttempts     int
+	totpAttemptsFrom time.Time
+}
+
+// resetToken is a stored password reset token.
+type resetToken struct {
+	entity.PasswordResetToken
+	used bool
+}
+
+// recoveryCode is a stored two-factor recovery code.
+type recoveryCode struct {
+	hash string
+	used bool
+}
+
+// apiKey is a stored API key.
+type apiKey struct {
+	entity.APIKey
+	revoked bool
+}
+
+// NewStorage returns a new empty storage.
+func NewStorage() *Storage {
+	return &Storage{
+		users:         make(map[uuid.UUID]*userRow),
+		sessions:      make(map[uuid.UUID]entity.Session),
+		resetTokens:   make(map[string]*resetToken),
+		recoveryCodes: make(map[uuid.UUID][]*recoveryCode),
+		apiKeys:       make(map[uuid.UUID]*apiKey),
+		orders:        make(map[entity.OrderNumber]entity.Order),
+		orderHistory:  make(map[entity.OrderNumber][]entity.OrderStatusChange),
+		balances:      make(map[uuid.UUID]entity.Balance),
+		webhooks:      make(map[uuid.UUID]entity.Webhook),
+		deliveries:    make(map[uuid.UUID]entity.WebhookDelivery),
+		rewardRules:   make(map[string]entity.RewardRule),
+		accrualOrders: make(map[entity.OrderNumber]entity.AccrualOrder),
+	}
+}
+
+// copyTime returns a copy of the time pointer, so the caller cannot change the stored value.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	result := *t
+	return &result
+}
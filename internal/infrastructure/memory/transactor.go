@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"context"
+)
+
+// Transactor runs the repository calls one after another. Every in-memory repository call is atomic,
+// but the calls are not rolled back when a later one fails, the memory storage is for demos and tests.
+type Transactor struct{}
+
+// NewTransactor returns a new Transactor
+func NewTransactor() *Transactor {
+	return &Transactor{}
+}
+
+// WithinTransaction calls fn with the context.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
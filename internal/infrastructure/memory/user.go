@@ -0,0 +1,522 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// sessionTouchInterval is how often the last use of a session used by the same client is updated.
+const sessionTouchInterval = time.Minute
+
+// UserRepository is an in-memory implementation of user repository.
+type UserRepository struct {
+	storage *Storage
+	log     *logger.Logger
+}
+
+// NewUserRepository returns a new in-memory user repository
+func NewUserRepository(storage *Storage, log *logger.Logger) *UserRepository {
+	return &UserRepository{storage: storage, log: log}
+}
+
+// GetUserByLogin returns a user by login.
+func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*entity.User, error) {
+	const op = "infrastructure.memory.UserRepository.GetUserByLogin"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_login", login),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for _, stored := range r.storage.users {
+		if stored.Login == login {
+			result := stored.User
+			return &result, nil
+		}
+	}
+	log.Info("User not found", log.StringField("user_login", login))
+	return nil, entity.ErrUserNotFound
+}
+
+// CreateUser creates a new user.
+func (r *UserRepository) CreateUser(ctx context.Context, user *entity.User) (*entity.User, error) {
+	const op = "infrastructure.memory.UserRepository.CreateUser"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_login", user.Login),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	_, exists := r.storage.users[user.UUID]
+	for _, stored := range r.storage.users {
+		if stored.Login == user.Login {
+			exists = true
+		}
+	}
+	if exists {
+		log.Info("User already exists!")
+		return nil, entity.ErrUserExists
+	}
+	r.storage.users[user.UUID] = &userRow{User: entity.User{
+		UUID:         user.UUID,
+		Login:        user.Login,
+		PasswordHash: user.PasswordHash,
+		Role:         user.Role,
+	}}
+	return user, nil
+}
+
+// GetUserByUUID returns a user by UUID.
+func (r *UserRepository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (*entity.User, error) {
+	const op = "infrastructure.memory.UserRepository.GetUserByUUID"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.users[userUUID]
+	if !ok {
+		log.Info("User not found", log.StringField("user_uuid", userUUID.String()))
+		return nil, entity.ErrUserNotFound
+	}
+	result := stored.User
+	return &result, nil
+}
+
+// UpdatePassword sets a new password hash for the user.
+func (r *UserRepository) UpdatePassword(ctx context.Context, userUUID uuid.UUID, passwordHash string) error {
+	return r.updateUser(ctx, "infrastructure.memory.UserRepository.UpdatePassword", userUUID, func(user *userRow) {
+		user.PasswordHash = passwordHash
+	})
+}
+
+// SetTOTP sets the TOTP secret of the user and whether two-factor authentication is enabled.
+func (r *UserRepository) SetTOTP(ctx context.Context, userUUID uuid.UUID, secret string, enabled bool) error {
+	return r.updateUser(ctx, "infrastructure.memory.UserRepository.SetTOTP", userUUID, func(user *userRow) {
+		// the used TOTP step belongs to the secret, a new secret starts without it
+		if user.TOTPSecret != secret {
+			user.totpLastStep = 0
+		}
+		user.TOTPSecret = secret
+		user.TOTPEnabled = enabled
+	})
+}
+
+// UpdateUserRole sets a new role for the user.
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error {
+	return r.updateUser(ctx, "infrastructure.memory.UserRepository.UpdateUserRole", userUUID, func(user *userRow) {
+		user.Role = role
+	})
+}
+
+// updateUser applies the update to the stored user.
+func (r *UserRepository) updateUser(ctx context.Context, op string, userUUID uuid.UUID, update func(user *userRow)) error {
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.users[userUUID]
+	if !ok {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	update(stored)
+	return nil
+}
+
+// CreateSession stores a new session and removes the expired sessions of the user.
+func (r *UserRepository) CreateSession(_ context.Context, session *entity.Session) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for sessionUUID, stored := range r.storage.sessions {
+		if stored.UserUUID == session.UserUUID && !stored.ExpiresAt.After(session.CreatedAt) {
+			delete(r.storage.sessions, sessionUUID)
+		}
+	}
+	r.storage.sessions[session.UUID] = *session
+	return nil
+}
+
+// GetSession returns an active session by UUID.
+func (r *UserRepository) GetSession(ctx context.Context, sessionUUID uuid.UUID) (*entity.Session, error) {
+	const op = "infrastructure.memory.UserRepository.GetSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("session_uuid", sessionUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	session, ok := r.storage.sessions[sessionUUID]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		log.Info("Session not found")
+		return nil, entity.ErrUserSessionNotFound
+	}
+	return &session, nil
+}
+
+// ListSessions returns the active sessions of the user, the most recently used first.
+func (r *UserRepository) ListSessions(_ context.Context, userUUID uuid.UUID) ([]entity.Session, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	now := time.Now()
+	var sessions []entity.Session
+	for _, session := range r.storage.sessions {
+		if session.UserUUID == userUUID && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// TouchSession records the last use of a session and the client that used it.
+// Like in the database, a session used by the same client is updated at most once a minute.
+func (r *UserRepository) TouchSession(_ context.Context, sessionUUID uuid.UUID, ip, userAgent string) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	session, ok := r.storage.sessions[sessionUUID]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	if session.LastUsedAt.Before(now.Add(-sessionTouchInterval)) || session.IP != ip || session.UserAgent != userAgent {
+		session.LastUsedAt = now
+		session.IP = ip
+		session.UserAgent = userAgent
+		r.storage.sessions[sessionUUID] = session
+	}
+	return nil
+}
+
+// DeleteSession deletes a session of the user.
+func (r *UserRepository) DeleteSession(ctx context.Context, userUUID uuid.UUID, sessionUUID uuid.UUID) error {
+	const op = "infrastructure.memory.UserRepository.DeleteSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("session_uuid", sessionUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	session, ok := r.storage.sessions[sessionUUID]
+	if !ok || session.UserUUID != userUUID {
+		log.Info("Session not found")
+		return entity.ErrUserSessionNotFound
+	}
+	delete(r.storage.sessions, sessionUUID)
+	return nil
+}
+
+// DeleteUserSessions deletes all the user sessions except exceptSessionUUID.
+func (r *UserRepository) DeleteUserSessions(_ context.Context, userUUID uuid.UUID, exceptSessionUUID uuid.UUID) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for sessionUUID, session := range r.storage.sessions {
+		if session.UserUUID == userUUID && sessionUUID != exceptSessionUUID {
+			delete(r.storage.sessions, sessionUUID)
+		}
+	}
+	return nil
+}
+
+// CreatePasswordResetToken stores a new password reset token.
+// The tokens issued to the user earlier and not used yet are invalidated.
+func (r *UserRepository) CreatePasswordResetToken(_ context.Context, token *entity.PasswordResetToken) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for tokenHash, stored := range r.storage.resetTokens {
+		if stored.UserUUID == token.UserUUID && !stored.used {
+			delete(r.storage.resetTokens, tokenHash)
+		}
+	}
+	r.storage.resetTokens[token.TokenHash] = &resetToken{PasswordResetToken: *token}
+	return nil
+}
+
+// GetPasswordResetToken returns an unused and unexpired password reset token by its hash.
+func (r *UserRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	const op = "infrastructure.memory.UserRepository.GetPasswordResetToken"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.resetTokens[tokenHash]
+	if !ok || stored.used || !stored.ExpiresAt.After(time.Now()) {
+		log.Info("Password reset token not found")
+		return nil, entity.ErrPasswordResetTokenInvalid
+	}
+	token := stored.PasswordResetToken
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks a password reset token as used.
+// Only one of concurrent calls for the same token succeeds.
+func (r *UserRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) error {
+	const op = "infrastructure.memory.UserRepository.ConsumePasswordResetToken"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.resetTokens[tokenHash]
+	if !ok || stored.used || !stored.ExpiresAt.After(time.Now()) {
+		log.Info("Password reset token already used or expired")
+		return entity.ErrPasswordResetTokenInvalid
+	}
+	stored.used = true
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all the recovery codes of the user with the given hashes.
+func (r *UserRepository) ReplaceRecoveryCodes(_ context.Context, userUUID uuid.UUID, codeHashes []string) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	codes := make([]*recoveryCode, 0, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		codes = append(codes, &recoveryCode{hash: codeHash})
+	}
+	r.storage.recoveryCodes[userUUID] = codes
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code of the user as used.
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, userUUID uuid.UUID, codeHash string) error {
+	const op = "infrastructure.memory.UserRepository.ConsumeRecoveryCode"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for _, code := range r.storage.recoveryCodes[userUUID] {
+		if code.hash == codeHash && !code.used {
+			code.used = true
+			return nil
+		}
+	}
+	log.Info("Recovery code not found or already used")
+	return entity.ErrUserTwoFactorCodeInvalid
+}
+
+// AddTwoFactorAttempt counts a second factor attempt of the user, the attempts are counted in the window
+// from the first one. It returns ErrUserTwoFactorLocked when the limit is reached.
+func (r *UserRepository) AddTwoFactorAttempt(ctx context.Context, userUUID uuid.UUID, limit int, window time.Duration) error {
+	const op = "infrastructure.memory.UserRepository.AddTwoFactorAttempt"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.users[userUUID]
+	if !ok {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	now := time.Now()
+	if stored.totpAttemptsFrom.IsZero() || !stored.totpAttemptsFrom.After(now.Add(-window)) {
+		stored.totpAttempts = 1
+		stored.totpAttemptsFrom = now
+		return nil
+	}
+	if stored.totpAttempts >= limit {
+		log.Info("Second factor attempts are used up")
+		return entity.ErrUserTwoFactorLocked
+	}
+	stored.totpAttempts++
+	return nil
+}
+
+// ResetTwoFactorAttempts clears the second factor attempts of the user.
+func (r *UserRepository) ResetTwoFactorAttempts(ctx context.Context, userUUID uuid.UUID) error {
+	return r.updateUser(ctx, "infrastructure.memory.UserRepository.ResetTwoFactorAttempts", userUUID, func(user *userRow) {
+		user.totpAttempts = 0
+		user.totpAttemptsFrom = time.Time{}
+	})
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code.
+// It returns ErrUserTwoFactorCodeInvalid when the step or a later one is already used.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) error {
+	const op = "infrastructure.memory.UserRepository.UseTOTPStep"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.users[userUUID]
+	if !ok {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	if stored.totpLastStep >= step {
+		log.Info("TOTP step already used")
+		return entity.ErrUserTwoFactorCodeInvalid
+	}
+	stored.totpLastStep = step
+	return nil
+}
+
+// CreateAPIKey stores a new API key.
+func (r *UserRepository) CreateAPIKey(_ context.Context, key *entity.APIKey) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	r.storage.apiKeys[key.UUID] = &apiKey{APIKey: copyAPIKey(*key)}
+	return nil
+}
+
+// GetAPIKeyByHash returns a not revoked API key by its hash.
+func (r *UserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	const op = "infrastructure.memory.UserRepository.GetAPIKeyByHash"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for _, stored := range r.storage.apiKeys {
+		if stored.KeyHash == keyHash && !stored.revoked {
+			key := copyAPIKey(stored.APIKey)
+			return &key, nil
+		}
+	}
+	log.Info("API key not found")
+	return nil, entity.ErrAPIKeyNotFound
+}
+
+// ListAPIKeys returns the not revoked API keys of the user, oldest first.
+func (r *UserRepository) ListAPIKeys(_ context.Context, userUUID uuid.UUID) ([]entity.APIKey, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var keys []entity.APIKey
+	for _, stored := range r.storage.apiKeys {
+		if stored.UserUUID == userUUID && !stored.revoked {
+			keys = append(keys, copyAPIKey(stored.APIKey))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of the user.
+func (r *UserRepository) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error {
+	const op = "infrastructure.memory.UserRepository.RevokeAPIKey"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("api_key_uuid", keyUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.apiKeys[keyUUID]
+	if !ok || stored.UserUUID != userUUID || stored.revoked {
+		log.Info("API key not found")
+		return entity.ErrAPIKeyNotFound
+	}
+	stored.revoked = true
+	return nil
+}
+
+// TouchAPIKey records the last use of an API key.
+func (r *UserRepository) TouchAPIKey(_ context.Context, keyUUID uuid.UUID) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.apiKeys[keyUUID]
+	if ok {
+		now := time.Now()
+		stored.LastUsedAt = &now
+	}
+	return nil
+}
+
+// AnonymizeUser removes the personal data and credentials of the user and marks the user deleted.
+// The user itself is kept, so the login cannot be used and the UUID is not reused.
+func (r *UserRepository) AnonymizeUser(ctx context.Context, userUUID uuid.UUID) error {
+	const op = "infrastructure.memory.UserRepository.AnonymizeUser"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.users[userUUID]
+	if !ok || stored.deleted {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	stored.User = entity.User{
+		UUID:  userUUID,
+		Login: "deleted-" + userUUID.String(),
+		Role:  entity.RoleUser,
+	}
+	stored.deleted = true
+
+	for sessionUUID, session := range r.storage.sessions {
+		if session.UserUUID == userUUID {
+			delete(r.storage.sessions, sessionUUID)
+		}
+	}
+	delete(r.storage.recoveryCodes, userUUID)
+	for tokenHash, token := range r.storage.resetTokens {
+		if token.UserUUID == userUUID {
+			delete(r.storage.resetTokens, tokenHash)
+		}
+	}
+	for _, key := range r.storage.apiKeys {
+		if key.UserUUID == userUUID {
+			key.revoked = true
+		}
+	}
+	for webhookUUID, webhook := range r.storage.webhooks {
+		if webhook.UserUUID == userUUID {
+			r.storage.deleteWebhook(webhookUUID)
+		}
+	}
+	return nil
+}
+
+// copyAPIKey returns a copy of the key that does not share the scopes and the last use with it.
+func copyAPIKey(key entity.APIKey) entity.APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	key.LastUsedAt = copyTime(key.LastUsedAt)
+	return key
+}
@@ -0,0 +1,159 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/memory"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestUserRepository_CreateUser(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewUserRepository(memory.NewStorage(), logger.NewLogger())
+	alice := &entity.User{UUID: uuid.New(), Login: "alice", PasswordHash: "hash", Role: entity.RoleUser}
+	_, err := repository.CreateUser(ctx, alice)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		user *entity.User
+		err  error
+	}{
+		{name: "Same login", user: &entity.User{UUID: uuid.New(), Login: "alice"}, err: entity.ErrUserExists},
+		{name: "Same UUID", user: &entity.User{UUID: alice.UUID, Login: "bob"}, err: entity.ErrUserExists},
+		{name: "New user", user: &entity.User{UUID: uuid.New(), Login: "carol"}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := repository.CreateUser(ctx, tc.user)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	user, err := repository.GetUserByLogin(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, alice.UUID, user.UUID)
+	assert.Equal(t, "hash", user.PasswordHash)
+
+	_, err = repository.GetUserByLogin(ctx, "dave")
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	_, err = repository.GetUserByUUID(ctx, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	assert.ErrorIs(t, repository.UpdatePassword(ctx, uuid.New(), "hash"), entity.ErrUserNotFound)
+}
+
+func TestUserRepository_Sessions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewUserRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+
+	expired := entity.NewSession(userUUID, -time.Minute)
+	require.NoError(t, repository.CreateSession(ctx, expired))
+	_, err := repository.GetSession(ctx, expired.UUID)
+	assert.ErrorIs(t, err, entity.ErrUserSessionNotFound)
+
+	first := entity.NewSession(userUUID, time.Hour)
+	second := entity.NewSession(userUUID, time.Hour)
+	require.NoError(t, repository.CreateSession(ctx, first))
+	require.NoError(t, repository.CreateSession(ctx, second))
+	require.NoError(t, repository.TouchSession(ctx, first.UUID, "127.0.0.1", "test"))
+
+	sessions, err := repository.ListSessions(ctx, userUUID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, first.UUID, sessions[0].UUID)
+	assert.Equal(t, "127.0.0.1", sessions[0].IP)
+
+	assert.ErrorIs(t, repository.DeleteSession(ctx, uuid.New(), first.UUID), entity.ErrUserSessionNotFound)
+	require.NoError(t, repository.DeleteUserSessions(ctx, userUUID, second.UUID))
+	_, err = repository.GetSession(ctx, first.UUID)
+	assert.ErrorIs(t, err, entity.ErrUserSessionNotFound)
+	_, err = repository.GetSession(ctx, second.UUID)
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_PasswordResetToken(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewUserRepository(memory.NewStorage(), logger.NewLogger())
+	userUUID := uuid.New()
+
+	previous := entity.NewPasswordResetToken(userUUID, "previous", time.Hour)
+	token := entity.NewPasswordResetToken(userUUID, "current", time.Hour)
+	require.NoError(t, repository.CreatePasswordResetToken(ctx, previous))
+	require.NoError(t, repository.CreatePasswordResetToken(ctx, token))
+
+	_, err := repository.GetPasswordResetToken(ctx, "previous")
+	assert.ErrorIs(t, err, entity.ErrPasswordResetTokenInvalid)
+	_, err = repository.GetPasswordResetToken(ctx, "current")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repository.ConsumePasswordResetToken(ctx, "current"))
+	assert.ErrorIs(t, repository.ConsumePasswordResetToken(ctx, "current"), entity.ErrPasswordResetTokenInvalid)
+}
+
+func TestUserRepository_AnonymizeUser(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	storage := memory.NewStorage()
+	repository := memory.NewUserRepository(storage, logger.NewLogger())
+	webhooks := memory.NewWebhookRepository(storage, logger.NewLogger())
+	user := &entity.User{UUID: uuid.New(), Login: "alice", PasswordHash: "hash", Role: entity.RoleAdmin}
+	_, err := repository.CreateUser(ctx, user)
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateAPIKey(ctx, &entity.APIKey{UUID: uuid.New(), UserUUID: user.UUID, KeyHash: "key"}))
+	require.NoError(t, repository.ReplaceRecoveryCodes(ctx, user.UUID, []string{"code"}))
+	require.NoError(t, webhooks.CreateWebhook(ctx, &entity.Webhook{UUID: uuid.New(), UserUUID: user.UUID}))
+
+	require.NoError(t, repository.AnonymizeUser(ctx, user.UUID))
+	assert.ErrorIs(t, repository.AnonymizeUser(ctx, user.UUID), entity.ErrUserNotFound)
+
+	anonymized, err := repository.GetUserByUUID(ctx, user.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "deleted-"+user.UUID.String(), anonymized.Login)
+	assert.Empty(t, anonymized.PasswordHash)
+	assert.Equal(t, entity.RoleUser, anonymized.Role)
+
+	_, err = repository.GetUserByLogin(ctx, "alice")
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	_, err = repository.GetAPIKeyByHash(ctx, "key")
+	assert.ErrorIs(t, err, entity.ErrAPIKeyNotFound)
+	assert.ErrorIs(t, repository.ConsumeRecoveryCode(ctx, user.UUID, "code"), entity.ErrUserTwoFactorCodeInvalid)
+	list, err := webhooks.ListWebhooks(ctx, user.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestUserRepository_TwoFactorAttempts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "test")
+	repository := memory.NewUserRepository(memory.NewStorage(), logger.NewLogger())
+	user := &entity.User{UUID: uuid.New(), Login: "alice", PasswordHash: "hash", Role: entity.RoleUser}
+	_, err := repository.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repository.AddTwoFactorAttempt(ctx, user.UUID, 3, time.Hour))
+	}
+	assert.ErrorIs(t, repository.AddTwoFactorAttempt(ctx, user.UUID, 3, time.Hour), entity.ErrUserTwoFactorLocked)
+	// the attempts outside the window are not counted
+	assert.NoError(t, repository.AddTwoFactorAttempt(ctx, user.UUID, 3, 0))
+
+	require.NoError(t, repository.ResetTwoFactorAttempts(ctx, user.UUID))
+	assert.NoError(t, repository.AddTwoFactorAttempt(ctx, user.UUID, 1, time.Hour))
+
+	require.NoError(t, repository.UseTOTPStep(ctx, user.UUID, 10))
+	assert.ErrorIs(t, repository.UseTOTPStep(ctx, user.UUID, 10), entity.ErrUserTwoFactorCodeInvalid)
+	assert.ErrorIs(t, repository.UseTOTPStep(ctx, user.UUID, 9), entity.ErrUserTwoFactorCodeInvalid)
+	assert.NoError(t, repository.UseTOTPStep(ctx, user.UUID, 11))
+
+	// a new secret starts the steps over
+	require.NoError(t, repository.SetTOTP(ctx, user.UUID, "secret", true))
+	assert.NoError(t, repository.UseTOTPStep(ctx, user.UUID, 5))
+}
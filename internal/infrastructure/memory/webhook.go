@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// WebhookRepository is an in-memory implementation of webhook repository.
+type WebhookRepository struct {
+	storage *Storage
+	log     *logger.Logger
+}
+
+// NewWebhookRepository returns a new in-memory webhook repository
+func NewWebhookRepository(storage *Storage, log *logger.Logger) *WebhookRepository {
+	return &WebhookRepository{storage: storage, log: log}
+}
+
+// CreateWebhook stores a new webhook.
+func (r *WebhookRepository) CreateWebhook(_ context.Context, webhook *entity.Webhook) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	r.storage.webhooks[webhook.UUID] = copyWebhook(*webhook)
+	return nil
+}
+
+// ListWebhooks returns the webhooks of the user, oldest first.
+func (r *WebhookRepository) ListWebhooks(_ context.Context, userUUID uuid.UUID) ([]entity.Webhook, error) {
+	return r.listWebhooks(userUUID, func(entity.Webhook) bool { return true }), nil
+}
+
+// ListEventWebhooks returns the webhooks of the user subscribed to the event type.
+func (r *WebhookRepository) ListEventWebhooks(_ context.Context, userUUID uuid.UUID, eventType entity.EventType) ([]entity.Webhook, error) {
+	return r.listWebhooks(userUUID, func(webhook entity.Webhook) bool {
+		for _, subscribed := range webhook.EventTypes {
+			if subscribed == eventType {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// listWebhooks returns the webhooks of the user selected by the function, oldest first.
+func (r *WebhookRepository) listWebhooks(userUUID uuid.UUID, selected func(webhook entity.Webhook) bool) []entity.Webhook {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	var webhooks []entity.Webhook
+	for _, webhook := range r.storage.webhooks {
+		if webhook.UserUUID == userUUID && selected(webhook) {
+			webhooks = append(webhooks, copyWebhook(webhook))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt) })
+	return webhooks
+}
+
+// DeleteWebhook deletes a webhook of the user, its deliveries are deleted with it.
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, userUUID, webhookUUID uuid.UUID) error {
+	const op = "infrastructure.memory.WebhookRepository.DeleteWebhook"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("webhook_uuid", webhookUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	webhook, ok := r.storage.webhooks[webhookUUID]
+	if !ok || webhook.UserUUID != userUUID {
+		log.Info("Webhook not found")
+		return entity.ErrWebhookNotFound
+	}
+	r.storage.deleteWebhook(webhookUUID)
+	return nil
+}
+
+// deleteWebhook deletes a webhook with its deliveries, the caller holds the lock.
+func (s *Storage) deleteWebhook(webhookUUID uuid.UUID) {
+	delete(s.webhooks, webhookUUID)
+	for deliveryUUID, delivery := range s.deliveries {
+		if delivery.WebhookUUID == webhookUUID {
+			delete(s.deliveries, deliveryUUID)
+		}
+	}
+}
+
+// AddDeliveries stores new deliveries.
+func (r *WebhookRepository) AddDeliveries(_ context.Context, deliveries []entity.WebhookDelivery) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	for _, delivery := range deliveries {
+		delivery.URL = ""
+		delivery.Secret = ""
+		r.storage.deliveries[delivery.UUID] = copyDelivery(delivery)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due, with their target.
+// The claimed deliveries are postponed by lease, so concurrent workers skip them.
+func (r *WebhookRepository) ClaimDueDeliveries(_ context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	now := time.Now()
+	var due []entity.WebhookDelivery
+	for _, delivery := range r.storage.deliveries {
+		if delivery.Status == entity.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		delivery.NextAttemptAt = now.Add(lease)
+		r.storage.deliveries[delivery.UUID] = delivery
+		claimed := copyDelivery(delivery)
+		webhook := r.storage.webhooks[delivery.WebhookUUID]
+		claimed.URL = webhook.URL
+		claimed.Secret = webhook.Secret
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (r *WebhookRepository) UpdateDelivery(_ context.Context, delivery entity.WebhookDelivery) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	stored, ok := r.storage.deliveries[delivery.UUID]
+	if !ok {
+		return nil
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastError = delivery.LastError
+	stored.ResponseCode = delivery.ResponseCode
+	stored.DeliveredAt = copyTime(delivery.DeliveredAt)
+	r.storage.deliveries[delivery.UUID] = stored
+	return nil
+}
+
+// ListDeliveries returns up to limit latest deliveries of a webhook of the user, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, userUUID, webhookUUID uuid.UUID, limit int) ([]entity.WebhookDelivery, error) {
+	const op = "infrastructure.memory.WebhookRepository.ListDeliveries"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("webhook_uuid", webhookUUID.String()),
+	)
+
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+	webhook, ok := r.storage.webhooks[webhookUUID]
+	if !ok || webhook.UserUUID != userUUID {
+		log.Info("Webhook not found")
+		return nil, entity.ErrWebhookNotFound
+	}
+
+	var deliveries []entity.WebhookDelivery
+	for _, delivery := range r.storage.deliveries {
+		if delivery.WebhookUUID == webhookUUID {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// copyWebhook returns a copy of the webhook that does not share the event types with it.
+func copyWebhook(webhook entity.Webhook) entity.Webhook {
+	webhook.EventTypes = append([]entity.EventType(nil), webhook.EventTypes...)
+	return webhook
+}
+
+// copyDelivery returns a copy of the delivery that does not share the payload and the delivery time with it.
+func copyDelivery(delivery entity.WebhookDelivery) entity.WebhookDelivery {
+	delivery.Payload = append([]byte(nil), delivery.Payload...)
+	delivery.DeliveredAt = copyTime(delivery.DeliveredAt)
+	return delivery
+}
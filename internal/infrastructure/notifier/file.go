@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// FileNotifier writes notifications as JSON lines to a file or to stdout.
+// It is meant for local development and testing, where there is no mail delivery.
+type FileNotifier struct {
+	mu     sync.Mutex
+	out    io.Writer
+	logger *logger.Logger
+}
+
+// message is a single notification line.
+type message struct {
+	Type      string    `json:"type"`
+	Login     string    `json:"login"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// NewFileNotifier returns a notifier that appends to the file at path.
+// An empty path or "-" means stdout.
+func NewFileNotifier(path string, logger *logger.Logger) (*FileNotifier, error) {
+	if path == "" || path == "-" {
+		return &FileNotifier{out: os.Stdout, logger: logger}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open notification file: %w", err)
+	}
+	return &FileNotifier{out: file, logger: logger}, nil
+}
+
+// NewWriterNotifier returns a notifier that writes to out.
+func NewWriterNotifier(out io.Writer, logger *logger.Logger) *FileNotifier {
+	return &FileNotifier{out: out, logger: logger}
+}
+
+// SendPasswordReset writes the password reset token for the user.
+func (n *FileNotifier) SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	return n.write(ctx, message{
+		Type:      "password_reset",
+		Login:     user.Login,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		SentAt:    time.Now(),
+	})
+}
+
+// write writes one message as a JSON line.
+func (n *FileNotifier) write(ctx context.Context, msg message) error {
+	const op = "infrastructure.notifier.FileNotifier.write"
+	log := n.logger.With(
+		n.logger.StringField("op", op),
+		n.logger.StringField("request_id", contexter.GetRequestID(ctx)),
+		n.logger.StringField("type", msg.Type),
+	)
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("Failed to marshal notification", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	line = append(line, '\n')
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.out.Write(line)
+	if err != nil {
+		log.Error("Failed to write notification", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
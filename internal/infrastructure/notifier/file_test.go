@@ -0,0 +1,40 @@
+package notifier_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/notifier"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+func TestFileNotifier_SendPasswordReset(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contexter.RequestID, "req")
+	var out bytes.Buffer
+	n := notifier.NewWriterNotifier(&out, logger.NewLogger())
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+
+	err := n.SendPasswordReset(ctx, &entity.User{Login: "test"}, "token", expiresAt)
+	require.NoError(t, err)
+
+	var line struct {
+		Type      string    `json:"type"`
+		Login     string    `json:"login"`
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	err = json.Unmarshal(out.Bytes(), &line)
+	require.NoError(t, err)
+	assert.Equal(t, "password_reset", line.Type)
+	assert.Equal(t, "test", line.Login)
+	assert.Equal(t, "token", line.Token)
+	assert.True(t, expiresAt.Equal(line.ExpiresAt))
+}
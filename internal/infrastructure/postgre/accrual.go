@@ -0,0 +1,146 @@
+package postgre
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccrualRepository is an implementation of the built-in accrual engine repository.
+type AccrualRepository struct {
+	db  *pgxpool.Pool
+	log *logger.Logger
+}
+
+// NewAccrualRepository returns a new postgre accrual repository
+func NewAccrualRepository(db *pgxpool.Pool, log *logger.Logger) *AccrualRepository {
+	storage := &AccrualRepository{db: db, log: log}
+	return storage
+}
+
+// good is a goods item stored in the order goods JSON.
+type good struct {
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+// AddRewardRule adds a reward rule.
+func (r *AccrualRepository) AddRewardRule(ctx context.Context, rule entity.RewardRule) error {
+	const op = "infrastructure.postgre.AccrualRepository.AddRewardRule"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("match", rule.Match),
+	)
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO accrual_reward_rules (match, reward, reward_type, created_at)
+                	VALUES ($1, $2, $3, $4)`, rule.Match, rule.Reward, rule.RewardType, rule.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			log.Info("Reward rule already exists")
+			return entity.ErrRewardRuleAlreadyExists
+		}
+		log.Error("Failed to add reward rule", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetRewardRules returns all reward rules, the oldest first.
+func (r *AccrualRepository) GetRewardRules(ctx context.Context) ([]entity.RewardRule, error) {
+	const op = "infrastructure.postgre.AccrualRepository.GetRewardRules"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT match, reward, reward_type, created_at
+                	FROM accrual_reward_rules ORDER BY created_at, match`)
+	if err != nil {
+		log.Error("Failed to get reward rules", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var rules []entity.RewardRule
+	for rows.Next() {
+		var rule entity.RewardRule
+		err = rows.Scan(&rule.Match, &rule.Reward, &rule.RewardType, &rule.CreatedAt)
+		if err != nil {
+			log.Error("Failed to scan row", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to get reward rules", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rules, nil
+}
+
+// AddAccrualOrder adds an order with its goods and calculated accrual.
+func (r *AccrualRepository) AddAccrualOrder(ctx context.Context, order entity.AccrualOrder) error {
+	const op = "infrastructure.postgre.AccrualRepository.AddAccrualOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", order.Number),
+	)
+	goods := make([]good, 0, len(order.Goods))
+	for _, g := range order.Goods {
+		goods = append(goods, good{Description: g.Description, Price: g.Price})
+	}
+	goodsJSON, err := json.Marshal(goods)
+	if err != nil {
+		log.Error("Failed to marshal goods", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = conn(ctx, r.db).Exec(ctx, `INSERT INTO accrual_orders (number, goods, accrual, registered_at)
+                	VALUES ($1, $2, $3, $4)`, order.Number, goodsJSON, order.Accrual, order.RegisteredAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			log.Info("Accrual order already registered")
+			return entity.ErrAccrualOrderAlreadyRegistered
+		}
+		log.Error("Failed to add accrual order", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetAccrualOrder returns a registered order by its number.
+func (r *AccrualRepository) GetAccrualOrder(ctx context.Context, number entity.OrderNumber) (entity.AccrualOrder, error) {
+	const op = "infrastructure.postgre.AccrualRepository.GetAccrualOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+	)
+	order := entity.AccrualOrder{Number: number}
+	var goodsJSON []byte
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT goods, accrual, registered_at FROM accrual_orders WHERE number = $1`, number).
+		Scan(&goodsJSON, &order.Accrual, &order.RegisteredAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.AccrualOrder{}, entity.ErrAccrualOrderNotFound
+		}
+		log.Error("Failed to get accrual order", log.ErrorField(err))
+		return entity.AccrualOrder{}, fmt.Errorf("%s: %w", op, err)
+	}
+	var goods []good
+	err = json.Unmarshal(goodsJSON, &goods)
+	if err != nil {
+		log.Error("Failed to unmarshal goods", log.ErrorField(err))
+		return entity.AccrualOrder{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, g := range goods {
+		order.Goods = append(order.Goods, entity.Good{Description: g.Description, Price: g.Price})
+	}
+	return order, nil
+}
@@ -0,0 +1,46 @@
+package postgre
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// AccrualResponseRepository is an implementation of the accrual system responses audit.
+type AccrualResponseRepository struct {
+	db  *pgxpool.Pool
+	log *logger.Logger
+}
+
+// NewAccrualResponseRepository returns a new postgre accrual responses repository
+func NewAccrualResponseRepository(db *pgxpool.Pool, log *logger.Logger) *AccrualResponseRepository {
+	storage := &AccrualResponseRepository{db: db, log: log}
+	return storage
+}
+
+// AddAccrualResponse stores a raw accrual system response.
+func (r *AccrualResponseRepository) AddAccrualResponse(ctx context.Context, response entity.AccrualResponse) error {
+	const op = "infrastructure.postgre.AccrualResponseRepository.AddAccrualResponse"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("provider", response.Provider),
+		r.log.AnyField("order_number", response.OrderNumber),
+	)
+	body := response.Body
+	if body == nil {
+		body = []byte{}
+	}
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO accrual_responses (provider, order_number, status_code, body, error, received_at)
+                	VALUES ($1, $2, $3, $4, $5, $6)`,
+		response.Provider, response.OrderNumber, response.StatusCode, body, response.Error, response.ReceivedAt)
+	if err != nil {
+		log.Error("Failed to add accrual response", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
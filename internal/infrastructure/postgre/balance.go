@@ -2,10 +2,12 @@ package postgre
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
@@ -25,32 +27,6 @@ func NewBalanceRepository(db *pgxpool.Pool, log *logger.Logger) *BalanceReposito
 	return storage
 }
 
-// Migrate migrates the database
-func (r *BalanceRepository) Migrate(ctx context.Context) error {
-	const op = "infrastructure.postgre.BalanceRepository.Migrate"
-	log := r.log.With(r.log.StringField("op", op))
-	_, err := r.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS user_balances (
-        user_uuid uuid PRIMARY KEY NOT NULL,
-        current DOUBLE PRECISION NOT NULL DEFAULT 0,
-        withdraw DOUBLE PRECISION NOT NULL DEFAULT 0);`)
-	if err != nil {
-		log.Error("Failed to create table user_balances", log.ErrorField(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	_, err = r.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS balance_operations (
-        uuid uuid PRIMARY KEY NOT NULL,
-        user_uuid uuid NOT NULL,
-        accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
-        withdrawal DOUBLE PRECISION NOT NULL DEFAULT 0,
-        order_number BIGINT NOT NULL UNIQUE,
-        processed_at TIMESTAMP NOT NULL)`)
-	if err != nil {
-		log.Error("Failed to create table balance_operations", log.ErrorField(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	return nil
-}
-
 // GetBalance returns the balance of the user
 func (r *BalanceRepository) GetBalance(ctx context.Context, userUUID uuid.UUID) (*entity.Balance, error) {
 	const op = "infrastructure.postgre.BalanceRepository.GetBalance"
@@ -60,7 +36,7 @@ func (r *BalanceRepository) GetBalance(ctx context.Context, userUUID uuid.UUID)
 		r.log.StringField("user_uuid", userUUID.String()),
 	)
 	var balance entity.Balance
-	err := r.db.QueryRow(ctx, `SELECT current, withdraw FROM user_balances WHERE user_uuid = $1`, userUUID).Scan(&balance.Current, &balance.Withdraw)
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT current, withdraw FROM user_balances WHERE user_uuid = $1`, userUUID).Scan(&balance.Current, &balance.Withdraw)
 	if err != nil {
 		log.Error("Failed to get balance", log.ErrorField(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -77,9 +53,10 @@ func (r *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID
 		r.log.StringField("user_uuid", userUUID.String()),
 	)
 	var operations []entity.BalanceOperation
-	result, err := r.db.Query(ctx, `SELECT 
+	result, err := conn(ctx, r.db).Query(ctx, `SELECT 
 											uuid, 
 											user_uuid, 
+											type, 
 											accrual, 
 											withdrawal, 
 											order_number, 
@@ -89,7 +66,7 @@ func (r *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID
 										WHERE 
 										    user_uuid = $1 
 										  AND 
-										    withdrawal > 0`, userUUID)
+										    type = $2`, userUUID, entity.BalanceOperationWithdrawal)
 	if err != nil {
 		log.Error("Failed to get withdraw operations", log.ErrorField(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -97,7 +74,7 @@ func (r *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID
 	defer result.Close()
 	for result.Next() {
 		var operation entity.BalanceOperation
-		err = result.Scan(&operation.UUID, &operation.UserUUID, &operation.Accrual, &operation.Withdrawal, &operation.OrderNumber, &operation.ProcessedAt)
+		err = result.Scan(&operation.UUID, &operation.UserUUID, &operation.Type, &operation.Accrual, &operation.Withdrawal, &operation.OrderNumber, &operation.ProcessedAt)
 		if err != nil {
 			log.Error("Failed to scan row", log.ErrorField(err))
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -111,6 +88,85 @@ func (r *BalanceRepository) GetWithdrawOperations(ctx context.Context, userUUID
 	return operations, nil
 }
 
+// GetOperations returns all balance operations of the user, the oldest first
+func (r *BalanceRepository) GetOperations(ctx context.Context, userUUID uuid.UUID) ([]entity.BalanceOperation, error) {
+	const op = "infrastructure.postgre.BalanceRepository.GetOperations"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+	var operations []entity.BalanceOperation
+	result, err := conn(ctx, r.db).Query(ctx, `SELECT 
+											uuid, 
+											user_uuid, 
+											type, 
+											accrual, 
+											withdrawal, 
+											order_number, 
+											processed_at 
+										FROM 
+										    balance_operations 
+										WHERE 
+										    user_uuid = $1 
+										ORDER BY processed_at`, userUUID)
+	if err != nil {
+		log.Error("Failed to get balance operations", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer result.Close()
+	for result.Next() {
+		var operation entity.BalanceOperation
+		err = result.Scan(&operation.UUID, &operation.UserUUID, &operation.Type, &operation.Accrual, &operation.Withdrawal, &operation.OrderNumber, &operation.ProcessedAt)
+		if err != nil {
+			log.Error("Failed to scan row", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		operations = append(operations, operation)
+	}
+	if err = result.Err(); err != nil {
+		log.Error("Failed to read balance operations", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return operations, nil
+}
+
+// ReassignUserBalance moves the balance and all balance operations of the user to another user UUID
+func (r *BalanceRepository) ReassignUserBalance(ctx context.Context, fromUserUUID, toUserUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.BalanceRepository.ReassignUserBalance"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", fromUserUUID.String()),
+	)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		log.Error("Failed to begin transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE user_balances SET user_uuid = $1 WHERE user_uuid = $2`, toUserUUID, fromUserUUID)
+	if err != nil {
+		log.Error("Failed to reassign balance", log.ErrorField(err))
+		r.rollback(tx, ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE balance_operations SET user_uuid = $1 WHERE user_uuid = $2`, toUserUUID, fromUserUUID)
+	if err != nil {
+		log.Error("Failed to reassign balance operations", log.ErrorField(err))
+		r.rollback(tx, ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("Failed to commit transaction", log.ErrorField(err))
+		r.rollback(tx, ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
 // CreateBalance creates a new balance for the user
 func (r *BalanceRepository) CreateBalance(ctx context.Context, userUUID uuid.UUID) error {
 	const op = "infrastructure.postgre.BalanceRepository.CreateBalance"
@@ -119,7 +175,7 @@ func (r *BalanceRepository) CreateBalance(ctx context.Context, userUUID uuid.UUI
 		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
 		r.log.StringField("user_uuid", userUUID.String()),
 	)
-	_, err := r.db.Exec(ctx, `INSERT INTO user_balances (user_uuid, current, withdraw) VALUES ($1, 0, 0)`, userUUID)
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO user_balances (user_uuid, current, withdraw) VALUES ($1, 0, 0)`, userUUID)
 	if err != nil {
 		log.Error("Failed to create balance", log.ErrorField(err))
 		return fmt.Errorf("%s: %w", op, err)
@@ -139,7 +195,7 @@ func (r *BalanceRepository) Withdraw(ctx context.Context, operation entity.Balan
 		r.log.AnyField("withdrawal", operation.Withdrawal),
 	)
 
-	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		log.Error("Failed to begin transaction", log.ErrorField(err))
 		return fmt.Errorf("%s: %w", op, err)
@@ -170,13 +226,15 @@ func (r *BalanceRepository) Withdraw(ctx context.Context, operation entity.Balan
 	}
 	_, err = tx.Exec(ctx, `INSERT INTO balance_operations (
                                 user_uuid, 
+                                type, 
                                 accrual, 
                                 withdrawal, 
                                 order_number, 
                                 processed_at, 
                                 uuid
-                                ) VALUES ($1, $2, $3, $4, $5, $6)`,
+                                ) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 		operation.UserUUID,
+		operation.Type,
 		operation.Accrual,
 		operation.Withdrawal,
 		operation.OrderNumber,
@@ -196,6 +254,7 @@ func (r *BalanceRepository) Withdraw(ctx context.Context, operation entity.Balan
 	return nil
 }
 
+// Accrue executes an accrual, one per order
 func (r *BalanceRepository) Accrue(ctx context.Context, operation entity.BalanceOperation) error {
 	const op = "infrastructure.postgre.BalanceRepository.Accrue"
 	log := r.log.With(
@@ -207,7 +266,7 @@ func (r *BalanceRepository) Accrue(ctx context.Context, operation entity.Balance
 		r.log.AnyField("withdrawal", operation.Withdrawal),
 	)
 
-	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		log.Error("Failed to begin transaction", log.ErrorField(err))
 		return fmt.Errorf("%s: %w", op, err)
@@ -230,21 +289,28 @@ func (r *BalanceRepository) Accrue(ctx context.Context, operation entity.Balance
 	}
 	_, err = tx.Exec(ctx, `INSERT INTO balance_operations (
                                 user_uuid, 
+                                type, 
                                 accrual, 
                                 withdrawal, 
                                 order_number, 
                                 processed_at, 
                                 uuid
-                                ) VALUES ($1, $2, $3, $4, $5, $6)`,
+                                ) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 		operation.UserUUID,
+		operation.Type,
 		operation.Accrual,
 		operation.Withdrawal,
 		operation.OrderNumber,
 		operation.ProcessedAt,
 		operation.UUID)
 	if err != nil {
-		log.Error("Failed to create balance operation", log.ErrorField(err))
 		r.rollback(tx, ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "balance_operations_accrual_order_number_idx" {
+			log.Info("Accrual for the order already exists")
+			return entity.ErrBalanceAccrualExists
+		}
+		log.Error("Failed to create balance operation", log.ErrorField(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	err = tx.Commit(ctx)
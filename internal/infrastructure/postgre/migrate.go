@@ -0,0 +1,335 @@
+package postgre
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// migrationFiles are the schema migrations, NNNN_name.up.sql with the NNNN_name.down.sql reverting it.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsLockID is the advisory lock held while migrating, so the instances started together migrate one by one.
+const migrationsLockID = 7243158802
+
+var (
+	// ErrSchemaOutdated is returned when the database misses migrations of the binary.
+	ErrSchemaOutdated = errors.New("database schema is outdated, run gophermart migrate up")
+	// ErrSchemaUnknown is returned when the database has migrations the binary does not know.
+	ErrSchemaUnknown = errors.New("database schema is newer than the binary")
+	// ErrNoMigrationApplied is returned when there is no migration to revert.
+	ErrNoMigrationApplied = errors.New("no migration applied")
+)
+
+// Migration is a versioned schema change with its revert.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration of the binary or of the database, AppliedAt is zero for a pending migration.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+	// Unknown is set for an applied migration the binary does not have.
+	Unknown bool
+}
+
+// LoadMigrations reads the migrations from the migrations directory of the file system, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		name := path.Base(file)
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_name.up.sql or NNNN_name.down.sql", name)
+		}
+		number, title, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version", name)
+		}
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		}
+		if migration.Name != title {
+			return nil, fmt.Errorf("migration %d is named %s and %s", version, migration.Name, title)
+		}
+		if direction == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d %s must have both up and down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrations returns the migrations embedded in the binary.
+func Migrations() ([]Migration, error) {
+	return LoadMigrations(migrationFiles)
+}
+
+// Migrator applies and reverts the schema migrations embedded in the binary.
+type Migrator struct {
+	db         *pgxpool.Pool
+	log        *logger.Logger
+	migrations []Migration
+}
+
+// NewMigrator returns a new migrator of the embedded migrations.
+func NewMigrator(db *pgxpool.Pool, log *logger.Logger) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, log: log, migrations: migrations}, nil
+}
+
+// locked runs the function on a connection holding the migrations lock.
+func (m *Migrator) locked(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the lock is released with the session if the unlock fails
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockID)
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at TIMESTAMP NOT NULL);`)
+	if err != nil {
+		return err
+	}
+	return f(conn)
+}
+
+// applied returns the applied migrations by version.
+func applied(ctx context.Context, q interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}) (map[int]MigrationStatus, error) {
+	rows, err := q.Query(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make(map[int]MigrationStatus)
+	for rows.Next() {
+		var status MigrationStatus
+		err = rows.Scan(&status.Version, &status.Name, &status.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		result[status.Version] = status
+	}
+	return result, rows.Err()
+}
+
+// Up applies the pending migrations in order, each in its own transaction, and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	const op = "infrastructure.postgre.Migrator.Up"
+	log := m.log.With(m.log.StringField("op", op))
+
+	var done []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		current, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := current[migration.Version]; ok {
+				continue
+			}
+			err = m.run(ctx, conn, migration.Up, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+					migration.Version, migration.Name, time.Now())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			log.Info("Migration applied", log.AnyField("version", migration.Version), log.StringField("name", migration.Name))
+			done = append(done, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("Failed to migrate up", log.ErrorField(err))
+		return done, fmt.Errorf("%s: %w", op, err)
+	}
+	return done, nil
+}
+
+// Down reverts the last applied migration and returns it.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	const op = "infrastructure.postgre.Migrator.Down"
+	log := m.log.With(m.log.StringField("op", op))
+
+	var reverted Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		current, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		last := 0
+		for version := range current {
+			if version > last {
+				last = version
+			}
+		}
+		if last == 0 {
+			return ErrNoMigrationApplied
+		}
+		if last > len(m.migrations) {
+			return ErrSchemaUnknown
+		}
+		migration := m.migrations[last-1]
+		err = m.run(ctx, conn, migration.Down, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		log.Info("Migration reverted", log.AnyField("version", migration.Version), log.StringField("name", migration.Name))
+		reverted = migration
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrNoMigrationApplied) || errors.Is(err, ErrSchemaUnknown) {
+			return Migration{}, err
+		}
+		log.Error("Failed to migrate down", log.ErrorField(err))
+		return Migration{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return reverted, nil
+}
+
+// run executes the migration script and records it in one transaction.
+func (m *Migrator) run(ctx context.Context, conn *pgxpool.Conn, script string, record func(tx pgx.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.log.Error("Failed to rollback migration", m.log.ErrorField(err))
+		}
+	}()
+
+	_, err = tx.Exec(ctx, script)
+	if err != nil {
+		return err
+	}
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Status returns the migrations of the binary with their applied time,
+// followed by the applied migrations the binary does not know.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "infrastructure.postgre.Migrator.Status"
+
+	current := make(map[int]MigrationStatus)
+	var exists bool
+	err := m.db.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if exists {
+		current, err = applied(ctx, m.db)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if applied, ok := current[migration.Version]; ok {
+			status.AppliedAt = applied.AppliedAt
+			delete(current, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	unknown := make([]MigrationStatus, 0, len(current))
+	for _, status := range current {
+		status.Unknown = true
+		unknown = append(unknown, status)
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+	return append(statuses, unknown...), nil
+}
+
+// Check returns ErrSchemaOutdated when a migration is pending and ErrSchemaUnknown when the database is ahead.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.Unknown {
+			return ErrSchemaUnknown
+		}
+	}
+	for _, status := range statuses {
+		if status.AppliedAt.IsZero() {
+			return ErrSchemaOutdated
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgre_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/infrastructure/postgre"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := postgre.Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Name)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	migrations, err := postgre.LoadMigrations(fstest.MapFS{
+		"migrations/0002_b.up.sql":   file("CREATE TABLE b ();"),
+		"migrations/0002_b.down.sql": file("DROP TABLE b;"),
+		"migrations/0001_a.up.sql":   file("CREATE TABLE a ();"),
+		"migrations/0001_a.down.sql": file("DROP TABLE a;"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []postgre.Migration{
+		{Version: 1, Name: "a", Up: "CREATE TABLE a ();", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "b", Up: "CREATE TABLE b ();", Down: "DROP TABLE b;"},
+	}, migrations)
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "No down", fsys: fstest.MapFS{
+			"migrations/0001_a.up.sql": file("CREATE TABLE a ();"),
+		}},
+		{name: "Gap", fsys: fstest.MapFS{
+			"migrations/0002_b.up.sql":   file("CREATE TABLE b ();"),
+			"migrations/0002_b.down.sql": file("DROP TABLE b;"),
+		}},
+		{name: "Bad name", fsys: fstest.MapFS{
+			"migrations/a.up.sql": file("CREATE TABLE a ();"),
+		}},
+		{name: "Bad direction", fsys: fstest.MapFS{
+			"migrations/0001_a.sideways.sql": file("CREATE TABLE a ();"),
+		}},
+		{name: "Names differ", fsys: fstest.MapFS{
+			"migrations/0001_a.up.sql":   file("CREATE TABLE a ();"),
+			"migrations/0001_b.down.sql": file("DROP TABLE a;"),
+		}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := postgre.LoadMigrations(tc.fsys)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+-- Users, sessions, password reset tokens, recovery codes and API keys.
+-- The statements are idempotent, so the schema created before the versioned migrations is adopted as it is.
+CREATE TABLE IF NOT EXISTS users (
+    uuid UUID PRIMARY KEY,
+    login TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    uuid UUID PRIMARY KEY,
+    user_uuid UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS user_sessions_user_uuid_idx ON user_sessions(user_uuid);
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_uuid UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_uuid_idx ON password_reset_tokens(user_uuid);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    user_uuid UUID NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    PRIMARY KEY (user_uuid, code_hash));
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    uuid UUID PRIMARY KEY,
+    user_uuid UUID NOT NULL,
+    name TEXT NOT NULL,
+    key_hash TEXT UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP);
+CREATE INDEX IF NOT EXISTS api_keys_user_uuid_idx ON api_keys(user_uuid);
@@ -0,0 +1,47 @@
+-- Orders, their status history and the retraction audit.
+CREATE TABLE IF NOT EXISTS orders (
+    user_uuid UUID NOT NULL,
+    number TEXT PRIMARY KEY,
+    status TEXT NOT NULL,
+    accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
+    uploaded_at TIMESTAMP NOT NULL);
+-- the store the order was made in routes it to the accrual provider of the store
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS store TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS orders_user_uuid_idx ON orders(user_uuid);
+-- keyset pagination of the user orders, with and without the status filter
+CREATE INDEX IF NOT EXISTS orders_user_uuid_uploaded_at_idx ON orders(user_uuid, uploaded_at, number);
+CREATE INDEX IF NOT EXISTS orders_user_uuid_status_uploaded_at_idx ON orders(user_uuid, status, uploaded_at, number);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    order_number TEXT NOT NULL,
+    status TEXT NOT NULL,
+    accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
+    changed_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS order_status_history_order_number_idx ON order_status_history(order_number, changed_at);
+
+-- order numbers were stored as BIGINT before they became digit strings
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'orders' AND column_name = 'number') = 'bigint' THEN
+        ALTER TABLE orders ALTER COLUMN number TYPE TEXT USING number::text;
+    END IF;
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'order_status_history' AND column_name = 'order_number') = 'bigint' THEN
+        ALTER TABLE order_status_history ALTER COLUMN order_number TYPE TEXT USING order_number::text;
+    END IF;
+END $$;
+
+-- retracted orders are removed, the audit keeps who retracted what and when
+CREATE TABLE IF NOT EXISTS order_retractions (
+    order_number TEXT NOT NULL,
+    user_uuid UUID NOT NULL,
+    status TEXT NOT NULL,
+    uploaded_at TIMESTAMP NOT NULL,
+    retracted_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS order_retractions_order_number_idx ON order_retractions(order_number);
+
+-- orders uploaded before the history was recorded start with their upload
+INSERT INTO order_status_history (order_number, status, accrual, changed_at)
+    SELECT number, 'NEW', 0, uploaded_at FROM orders o
+    WHERE NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_number = o.number);
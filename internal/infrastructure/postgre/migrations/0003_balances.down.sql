@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS balance_operations;
+DROP TABLE IF EXISTS user_balances;
@@ -0,0 +1,24 @@
+-- User balances and the balance operations.
+-- The order number was unique here, one operation per order; 0007_balance_operation_types replaces it
+-- with a unique accrual per order, so an order may also have several withdrawals.
+CREATE TABLE IF NOT EXISTS user_balances (
+    user_uuid UUID PRIMARY KEY NOT NULL,
+    current DOUBLE PRECISION NOT NULL DEFAULT 0,
+    withdraw DOUBLE PRECISION NOT NULL DEFAULT 0);
+
+CREATE TABLE IF NOT EXISTS balance_operations (
+    uuid UUID PRIMARY KEY NOT NULL,
+    user_uuid UUID NOT NULL,
+    accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
+    withdrawal DOUBLE PRECISION NOT NULL DEFAULT 0,
+    order_number TEXT NOT NULL UNIQUE,
+    processed_at TIMESTAMP NOT NULL);
+
+-- order numbers were stored as BIGINT before they became digit strings
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'balance_operations' AND column_name = 'order_number') = 'bigint' THEN
+        ALTER TABLE balance_operations ALTER COLUMN order_number TYPE TEXT USING order_number::text;
+    END IF;
+END $$;
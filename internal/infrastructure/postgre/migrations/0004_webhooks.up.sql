@@ -0,0 +1,25 @@
+-- Webhooks and their durable deliveries.
+CREATE TABLE IF NOT EXISTS webhooks (
+    uuid UUID PRIMARY KEY,
+    user_uuid UUID NOT NULL,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS webhooks_user_uuid_idx ON webhooks(user_uuid);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    uuid UUID PRIMARY KEY,
+    webhook_uuid UUID NOT NULL REFERENCES webhooks(uuid) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    response_code INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP);
+-- the worker looks only for the pending deliveries that are due
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_uuid_idx ON webhook_deliveries(webhook_uuid, created_at);
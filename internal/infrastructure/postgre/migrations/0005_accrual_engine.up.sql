@@ -0,0 +1,12 @@
+-- Reward rules and orders of the built-in accrual engine.
+CREATE TABLE IF NOT EXISTS accrual_reward_rules (
+    match TEXT PRIMARY KEY,
+    reward DOUBLE PRECISION NOT NULL,
+    reward_type TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL);
+
+CREATE TABLE IF NOT EXISTS accrual_orders (
+    number TEXT PRIMARY KEY,
+    goods JSONB NOT NULL,
+    accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
+    registered_at TIMESTAMP NOT NULL);
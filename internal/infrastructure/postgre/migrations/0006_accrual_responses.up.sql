@@ -0,0 +1,10 @@
+-- Raw accrual system responses kept for audit.
+-- The body is kept as bytes, a malformed response may be not even a valid text.
+CREATE TABLE IF NOT EXISTS accrual_responses (
+    provider TEXT NOT NULL,
+    order_number TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    body BYTEA NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS accrual_responses_order_number_idx ON accrual_responses(order_number, received_at);
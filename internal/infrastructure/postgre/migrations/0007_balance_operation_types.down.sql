@@ -0,0 +1,5 @@
+-- fails when an order has several operations, they have to be removed by hand first
+DROP INDEX IF EXISTS balance_operations_user_uuid_idx;
+DROP INDEX IF EXISTS balance_operations_accrual_order_number_idx;
+ALTER TABLE balance_operations ADD CONSTRAINT balance_operations_order_number_key UNIQUE (order_number);
+ALTER TABLE balance_operations DROP COLUMN IF EXISTS type;
@@ -0,0 +1,11 @@
+-- Balance operations get a type, an order may have an accrual and several withdrawals,
+-- only the accrual stays unique per order.
+ALTER TABLE balance_operations ADD COLUMN IF NOT EXISTS type TEXT;
+UPDATE balance_operations SET type = CASE WHEN withdrawal > 0 THEN 'withdrawal' ELSE 'accrual' END WHERE type IS NULL;
+ALTER TABLE balance_operations ALTER COLUMN type SET NOT NULL;
+ALTER TABLE balance_operations DROP CONSTRAINT IF EXISTS balance_operations_type_check;
+ALTER TABLE balance_operations ADD CONSTRAINT balance_operations_type_check CHECK (type IN ('accrual', 'withdrawal'));
+
+ALTER TABLE balance_operations DROP CONSTRAINT IF EXISTS balance_operations_order_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS balance_operations_accrual_order_number_idx ON balance_operations(order_number) WHERE type = 'accrual';
+CREATE INDEX IF NOT EXISTS balance_operations_user_uuid_idx ON balance_operations(user_uuid, processed_at);
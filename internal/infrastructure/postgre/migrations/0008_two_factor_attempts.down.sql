@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS totp_attempts_from;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
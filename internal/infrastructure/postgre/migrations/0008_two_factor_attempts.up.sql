@@ -0,0 +1,5 @@
+-- The last accepted TOTP time step, so a code is not accepted twice,
+-- and the second factor attempts counted in a window from the first one.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_attempts_from TIMESTAMP;
@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
@@ -27,28 +28,6 @@ func NewOrderRepository(db *pgxpool.Pool, log *logger.Logger) *OrderRepository {
 	return storage
 }
 
-// Migrate migrates the database
-func (r *OrderRepository) Migrate(ctx context.Context) error {
-	const op = "infrastructure.postgre.OrderRepository.Migrate"
-	log := r.log.With(r.log.StringField("op", op))
-	_, err := r.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS orders (
-    	user_uuid uuid NOT NULL,
-        number BIGINT PRIMARY KEY,
-        status TEXT NOT NULL,
-        accrual DOUBLE PRECISION NOT NULL DEFAULT 0,
-        uploaded_at TIMESTAMP NOT NULL);`)
-	if err != nil {
-		log.Error("Failed to create table", log.ErrorField(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	_, err = r.db.Exec(ctx, `CREATE INDEX IF NOT EXISTS orders_user_uuid_idx ON orders(user_uuid)`)
-	if err != nil {
-		log.Error("Failed to create index", log.ErrorField(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	return nil
-}
-
 // AddOrderForUser adds a new order to the user.
 func (r *OrderRepository) AddOrderForUser(ctx context.Context, order entity.Order) error {
 	const op = "infrastructure.postgre.OrderRepository.AddOrderForUser"
@@ -59,38 +38,139 @@ func (r *OrderRepository) AddOrderForUser(ctx context.Context, order entity.Orde
 		r.log.AnyField("user_uuid", order.UserUUID),
 	)
 
-	_, err := r.db.Exec(ctx, `INSERT INTO orders (
+	// a conflict is not raised as an error, so the transaction the order may be added in stays usable
+	tag, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO orders (
                     user_uuid,
                     number,
                     status,
                     uploaded_at,
-                    accrual
-                    ) VALUES ($1, $2, $3, $4, 0)`, order.UserUUID, order.Number, order.Status, order.UploadedAt)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" {
-				var dbUserUUID uuid.UUID
-				err = r.db.QueryRow(ctx, `SELECT user_uuid FROM orders WHERE number = $1`, order.Number).Scan(&dbUserUUID)
-				if err != nil {
-					log.Info("Unknown error", log.ErrorField(err))
-					return fmt.Errorf("%s: %w", op, err)
-				}
-				if dbUserUUID == order.UserUUID {
-					log.Info("Order already uploaded from current user")
-					return entity.ErrOrderAlreadyUploaded
-				} else {
-					log.Info("Order already uploaded from another user")
-					return entity.ErrOrderAlreadyUploadedByAnotherUser
-				}
-			}
-		}
+                    accrual,
+                    store
+                    ) VALUES ($1, $2, $3, $4, 0, $5) 
+                    ON CONFLICT (number) DO NOTHING`, order.UserUUID, order.Number, order.Status, order.UploadedAt, order.Store)
+	if err != nil {
 		log.Error("Failed to create order", log.ErrorField(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if tag.RowsAffected() == 0 {
+		var dbUserUUID uuid.UUID
+		err = conn(ctx, r.db).QueryRow(ctx, `SELECT user_uuid FROM orders WHERE number = $1`, order.Number).Scan(&dbUserUUID)
+		if err != nil {
+			log.Info("Unknown error", log.ErrorField(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if dbUserUUID == order.UserUUID {
+			log.Info("Order already uploaded from current user")
+			return entity.ErrOrderAlreadyUploaded
+		}
+		log.Info("Order already uploaded from another user")
+		return entity.ErrOrderAlreadyUploadedByAnotherUser
+	}
 	return nil
 }
 
+// AddOrdersForUser adds new orders to the user in one statement and returns the upload result of every number.
+// Numbers that are already uploaded are left as they are.
+func (r *OrderRepository) AddOrdersForUser(ctx context.Context, userUUID uuid.UUID, orders []entity.Order) (map[entity.OrderNumber]entity.OrderUploadResult, error) {
+	const op = "infrastructure.postgre.OrderRepository.AddOrdersForUser"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("user_uuid", userUUID),
+		r.log.AnyField("count", len(orders)),
+	)
+
+	numbers := make([]string, 0, len(orders))
+	uploadedAt := make([]time.Time, 0, len(orders))
+	stores := make([]string, 0, len(orders))
+	for _, order := range orders {
+		numbers = append(numbers, string(order.Number))
+		uploadedAt = append(uploadedAt, order.UploadedAt)
+		stores = append(stores, order.Store)
+	}
+
+	// existing reads the snapshot taken before the insert, so it holds the owners of the conflicting numbers only
+	rows, err := conn(ctx, r.db).Query(ctx, `WITH input AS (
+			SELECT * FROM unnest($2::text[], $3::timestamp[], $5::text[]) AS t(number, uploaded_at, store)
+		), existing AS (
+			SELECT o.number, o.user_uuid FROM orders o JOIN input USING (number)
+		), inserted AS (
+			INSERT INTO orders (user_uuid, number, status, uploaded_at, accrual, store) 
+			SELECT $1, number, $4, uploaded_at, 0, store FROM input 
+			ON CONFLICT (number) DO NOTHING 
+			RETURNING number, uploaded_at
+		), history AS (
+			INSERT INTO order_status_history (order_number, status, accrual, changed_at) 
+			SELECT number, $4, 0, uploaded_at FROM inserted
+		)
+		SELECT input.number, inserted.number IS NOT NULL, existing.user_uuid 
+		FROM input 
+		LEFT JOIN inserted USING (number) 
+		LEFT JOIN existing USING (number)`,
+		userUUID, numbers, uploadedAt, entity.OrderNew, stores)
+	if err != nil {
+		log.Error("Failed to add orders", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	results := make(map[entity.OrderNumber]entity.OrderUploadResult, len(orders))
+	var raced []string
+	for rows.Next() {
+		var number string
+		var inserted bool
+		var owner uuid.NullUUID
+		err = rows.Scan(&number, &inserted, &owner)
+		if err != nil {
+			log.Error("Failed to scan row", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		switch {
+		case inserted:
+			results[entity.OrderNumber(number)] = entity.OrderUploadAccepted
+		case !owner.Valid:
+			// uploaded by a concurrent request after the snapshot was taken
+			raced = append(raced, number)
+		case owner.UUID == userUUID:
+			results[entity.OrderNumber(number)] = entity.OrderUploadAlreadyUploaded
+		default:
+			results[entity.OrderNumber(number)] = entity.OrderUploadOwnedByAnotherUser
+		}
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to add orders", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(raced) > 0 {
+		rows, err = conn(ctx, r.db).Query(ctx, `SELECT number, user_uuid FROM orders WHERE number = ANY($1)`, raced)
+		if err != nil {
+			log.Error("Failed to get order owners", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var number string
+			var owner uuid.UUID
+			err = rows.Scan(&number, &owner)
+			if err != nil {
+				log.Error("Failed to scan row", log.ErrorField(err))
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			if owner == userUUID {
+				results[entity.OrderNumber(number)] = entity.OrderUploadAlreadyUploaded
+			} else {
+				results[entity.OrderNumber(number)] = entity.OrderUploadOwnedByAnotherUser
+			}
+		}
+		if err = rows.Err(); err != nil {
+			log.Error("Failed to get order owners", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return results, nil
+}
+
 // GetAllUserOrders returns all orders for a user.
 func (r *OrderRepository) GetAllUserOrders(ctx context.Context, userUUID uuid.UUID) ([]entity.Order, error) {
 	const op = "infrastructure.postgre.OrderRepository.GetAllUserOrders"
@@ -98,16 +178,17 @@ func (r *OrderRepository) GetAllUserOrders(ctx context.Context, userUUID uuid.UU
 		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
 		r.log.StringField("user_uuid", userUUID.String()),
 	)
-	rows, _ := r.db.Query(ctx, `SELECT 
+	rows, _ := conn(ctx, r.db).Query(ctx, `SELECT 
     				user_uuid,
                     number,
                     status,
                     uploaded_at,
-                    accrual FROM orders WHERE user_uuid = $1`, userUUID)
+                    accrual,
+                    store FROM orders WHERE user_uuid = $1 ORDER BY uploaded_at, number`, userUUID)
 	defer rows.Close()
 	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Order, error) {
 		var order entity.Order
-		err := row.Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual)
+		err := row.Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual, &order.Store)
 		if err != nil {
 			log.Error("Failed to scan row", log.ErrorField(err))
 			return entity.Order{}, fmt.Errorf("%s: %w", op, err)
@@ -125,6 +206,84 @@ func (r *OrderRepository) GetAllUserOrders(ctx context.Context, userUUID uuid.UU
 	return orders, nil
 }
 
+// GetUserOrdersPage returns a page of the user orders selected by the filter.
+func (r *OrderRepository) GetUserOrdersPage(ctx context.Context, userUUID uuid.UUID, filter entity.OrderFilter) (entity.OrderPage, error) {
+	const op = "infrastructure.postgre.OrderRepository.GetUserOrdersPage"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	where := []string{"user_uuid = $1"}
+	args := []any{userUUID}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		args = append(args, statuses)
+		where = append(where, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	if !filter.UploadedFrom.IsZero() {
+		args = append(args, filter.UploadedFrom)
+		where = append(where, fmt.Sprintf("uploaded_at >= $%d", len(args)))
+	}
+	if !filter.UploadedTo.IsZero() {
+		args = append(args, filter.UploadedTo)
+		where = append(where, fmt.Sprintf("uploaded_at < $%d", len(args)))
+	}
+
+	var page entity.OrderPage
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM orders WHERE `+strings.Join(where, " AND "), args...).Scan(&page.Total)
+	if err != nil {
+		log.Error("Failed to count orders", log.ErrorField(err))
+		return entity.OrderPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.UploadedAt, filter.After.Number)
+		where = append(where, fmt.Sprintf("(uploaded_at, number) %s ($%d, $%d)", comparison, len(args)-1, len(args)))
+	}
+	// one extra row tells whether there is a next page
+	args = append(args, filter.Limit+1)
+	query := `SELECT 
+    				user_uuid,
+                    number,
+                    status,
+                    uploaded_at,
+                    accrual,
+                    store FROM orders WHERE ` + strings.Join(where, " AND ") +
+		fmt.Sprintf(" ORDER BY uploaded_at %s, number %s LIMIT $%d", direction, direction, len(args))
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		log.Error("Failed to get orders", log.ErrorField(err))
+		return entity.OrderPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Order, error) {
+		var order entity.Order
+		err := row.Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual, &order.Store)
+		return order, err
+	})
+	if err != nil {
+		log.Error("Failed to scan orders", log.ErrorField(err))
+		return entity.OrderPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		last := orders[len(orders)-1]
+		page.Next = &entity.OrderCursor{UploadedAt: last.UploadedAt, Number: last.Number}
+	}
+	page.Orders = orders
+	return page, nil
+}
+
 func (r *OrderRepository) UpdateOrderForUser(ctx context.Context, order entity.Order) error {
 	const op = "infrastructure.postgre.OrderRepository.UpdateOrderForUser"
 	log := r.log.With(r.log.StringField("op", op),
@@ -132,15 +291,252 @@ func (r *OrderRepository) UpdateOrderForUser(ctx context.Context, order entity.O
 		r.log.AnyField("order_number", order.Number),
 		r.log.AnyField("user_uuid", order.UserUUID),
 	)
-	_, err := r.db.Exec(ctx, `UPDATE orders SET 
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE orders SET 
                   status = $1,
                   accrual = $2
               WHERE
                   user_uuid = $3 
                 AND
-                  number = $4`, order.Status, order.Accrual, order.UserUUID, order.Number)
+                  number = $4
+                AND
+                  status <> ALL($5)`, order.Status, order.Accrual, order.UserUUID, order.Number,
+		[]string{string(entity.OrderProcessed), string(entity.OrderInvalid)})
 	if err != nil {
 		log.Error("Failed to update order", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// the order is found before the update, a missing row means a final status
+	if tag.RowsAffected() == 0 {
+		log.Info("Order status is already final")
+		return entity.ErrOrderStatusFinal
+	}
+	return nil
+}
+
+// GetUserOrder returns an order of the user by number.
+func (r *OrderRepository) GetUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.Order, error) {
+	const op = "infrastructure.postgre.OrderRepository.GetUserOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+	var order entity.Order
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    				user_uuid,
+                    number,
+                    status,
+                    uploaded_at,
+                    accrual,
+                    store FROM orders WHERE user_uuid = $1 AND number = $2`, userUUID, number).
+		Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual, &order.Store)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("Order not found")
+			return entity.Order{}, entity.ErrOrderNotFound
+		}
+		log.Error("Failed to get order", log.ErrorField(err))
+		return entity.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return order, nil
+}
+
+// GetOrderByNumber returns an order by its number regardless of the owner.
+func (r *OrderRepository) GetOrderByNumber(ctx context.Context, number entity.OrderNumber) (entity.Order, error) {
+	const op = "infrastructure.postgre.OrderRepository.GetOrderByNumber"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+	)
+	var order entity.Order
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    				user_uuid,
+                    number,
+                    status,
+                    uploaded_at,
+                    accrual,
+                    store FROM orders WHERE number = $1`, number).
+		Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual, &order.Store)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("Order not found")
+			return entity.Order{}, entity.ErrOrderNotFound
+		}
+		log.Error("Failed to get order", log.ErrorField(err))
+		return entity.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return order, nil
+}
+
+// GetUnfinishedOrders returns the NEW and PROCESSING orders of all users, the oldest first.
+func (r *OrderRepository) GetUnfinishedOrders(ctx context.Context) ([]entity.Order, error) {
+	const op = "infrastructure.postgre.OrderRepository.GetUnfinishedOrders"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+	rows, _ := conn(ctx, r.db).Query(ctx, `SELECT 
+    				user_uuid,
+                    number,
+                    status,
+                    uploaded_at,
+                    accrual,
+                    store FROM orders WHERE status = ANY($1) ORDER BY uploaded_at, number`,
+		[]string{string(entity.OrderNew), string(entity.OrderProcessing)})
+	defer rows.Close()
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Order, error) {
+		var order entity.Order
+		err := row.Scan(&order.UserUUID, &order.Number, &order.Status, &order.UploadedAt, &order.Accrual, &order.Store)
+		if err != nil {
+			log.Error("Failed to scan row", log.ErrorField(err))
+			return entity.Order{}, fmt.Errorf("%s: %w", op, err)
+		}
+		return order, err
+	})
+	if err != nil {
+		log.Error("Failed to get orders", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return orders, nil
+}
+
+// AddOrderStatusChange records a status transition of an order.
+func (r *OrderRepository) AddOrderStatusChange(ctx context.Context, change entity.OrderStatusChange) error {
+	const op = "infrastructure.postgre.OrderRepository.AddOrderStatusChange"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", change.OrderNumber),
+		r.log.AnyField("status", change.Status),
+	)
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO order_status_history (
+                                  order_number,
+                                  status,
+                                  accrual,
+                                  changed_at
+                                  ) VALUES ($1, $2, $3, $4)`,
+		change.OrderNumber,
+		change.Status,
+		change.Accrual,
+		change.ChangedAt)
+	if err != nil {
+		log.Error("Failed to add order status change", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetOrderStatusHistory returns the status transitions of an order, the oldest first.
+func (r *OrderRepository) GetOrderStatusHistory(ctx context.Context, number entity.OrderNumber) ([]entity.OrderStatusChange, error) {
+	const op = "infrastructure.postgre.OrderRepository.GetOrderStatusHistory"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+	)
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT 
+    				order_number,
+                    status,
+                    accrual,
+                    changed_at FROM order_status_history WHERE order_number = $1 ORDER BY changed_at`, number)
+	if err != nil {
+		log.Error("Failed to get order status history", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	history, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.OrderStatusChange, error) {
+		var change entity.OrderStatusChange
+		err := row.Scan(&change.OrderNumber, &change.Status, &change.Accrual, &change.ChangedAt)
+		return change, err
+	})
+	if err != nil {
+		log.Error("Failed to scan order status history", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return history, nil
+}
+
+// RetractUserOrder removes a retractable order of the user with its status history
+// and records the retraction in one transaction.
+func (r *OrderRepository) RetractUserOrder(ctx context.Context, userUUID uuid.UUID, number entity.OrderNumber) (entity.OrderRetraction, error) {
+	const op = "infrastructure.postgre.OrderRepository.RetractUserOrder"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.AnyField("order_number", number),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		log.Error("Failed to begin transaction", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Error("Failed to rollback transaction", log.ErrorField(err))
+		}
+	}()
+
+	// the row lock keeps the status from changing between the check and the delete
+	retraction := entity.OrderRetraction{OrderNumber: number, UserUUID: userUUID}
+	err = tx.QueryRow(ctx, `SELECT status, uploaded_at FROM orders 
+                	WHERE user_uuid = $1 AND number = $2 FOR UPDATE`, userUUID, number).
+		Scan(&retraction.Status, &retraction.UploadedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("Order not found")
+			return entity.OrderRetraction{}, entity.ErrOrderNotFound
+		}
+		log.Error("Failed to get order", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !retraction.Status.Retractable() {
+		log.Info("Order is not retractable", log.AnyField("status", retraction.Status))
+		return entity.OrderRetraction{}, entity.ErrOrderNotRetractable
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM orders WHERE number = $1`, number)
+	if err != nil {
+		log.Error("Failed to delete order", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// the number may be uploaded again, by its real owner too, so the history starts anew
+	_, err = tx.Exec(ctx, `DELETE FROM order_status_history WHERE order_number = $1`, number)
+	if err != nil {
+		log.Error("Failed to delete order status history", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+	retraction.RetractedAt = time.Now()
+	_, err = tx.Exec(ctx, `INSERT INTO order_retractions (order_number, user_uuid, status, uploaded_at, retracted_at) 
+                	VALUES ($1, $2, $3, $4, $5)`,
+		number, userUUID, retraction.Status, retraction.UploadedAt, retraction.RetractedAt)
+	if err != nil {
+		log.Error("Failed to record order retraction", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("Failed to commit transaction", log.ErrorField(err))
+		return entity.OrderRetraction{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return retraction, nil
+}
+
+// ReassignUserOrders moves all orders of the user to another user UUID.
+func (r *OrderRepository) ReassignUserOrders(ctx context.Context, fromUserUUID, toUserUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.OrderRepository.ReassignUserOrders"
+	log := r.log.With(r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", fromUserUUID.String()),
+	)
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE orders SET user_uuid = $1 WHERE user_uuid = $2`, toUserUUID, fromUserUUID)
+	if err != nil {
+		log.Error("Failed to reassign orders", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = conn(ctx, r.db).Exec(ctx, `UPDATE order_retractions SET user_uuid = $1 WHERE user_uuid = $2`, toUserUUID, fromUserUUID)
+	if err != nil {
+		log.Error("Failed to reassign order retractions", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
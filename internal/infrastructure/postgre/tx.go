@@ -0,0 +1,84 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// txKey is the context key of the transaction started by the TxManager.
+type txKey struct{}
+
+// querier is the part of the pool and of the transaction used by the repositories.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// conn returns the transaction carried in the context or the pool when there is none.
+// Begin on a transaction starts a savepoint, so the repositories may open their own transactions inside.
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// TxManager runs several repository calls in one database transaction.
+type TxManager struct {
+	db  *pgxpool.Pool
+	log *logger.Logger
+}
+
+// NewTxManager returns a new TxManager
+func NewTxManager(db *pgxpool.Pool, log *logger.Logger) *TxManager {
+	return &TxManager{db: db, log: log}
+}
+
+// WithinTransaction calls fn with a context carrying the transaction, commits it when fn succeeds
+// and rolls it back otherwise. A call inside another transaction joins it.
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "infrastructure.postgre.TxManager.WithinTransaction"
+	log := m.log.With(
+		m.log.StringField("op", op),
+		m.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		log.Error("Failed to begin transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Error("Failed to rollback transaction", log.ErrorField(err))
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("Failed to commit transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
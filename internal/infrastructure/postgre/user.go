@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -27,24 +28,6 @@ func NewUserRepository(db *pgxpool.Pool, log *logger.Logger) *UserRepository {
 	return storage
 }
 
-// Migrate migrates the database
-func (r *UserRepository) Migrate(ctx context.Context) error {
-	const op = "infrastructure.postgre.UserRepository.Migrate"
-
-	log := r.log.With(r.log.StringField("op", op))
-
-	_, err := r.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS users (
-        uuid UUID PRIMARY KEY,
-        login TEXT UNIQUE NOT NULL,
-        password_hash TEXT NOT NULL);`)
-	if err != nil {
-		log.Error("Failed to create table", log.ErrorField(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	return nil
-}
-
 // GetUserByLogin returns a user by login.
 func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*entity.User, error) {
 	const op = "infrastructure.postgre.UserRepository.GetUserByLogin"
@@ -55,7 +38,15 @@ func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*ent
 	)
 
 	var user entity.User
-	err := r.db.QueryRow(ctx, `SELECT uuid, login, password_hash FROM users WHERE login = $1`, login).Scan(&user.UUID, &user.Login, &user.PasswordHash)
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    						uuid, 
+    						login, 
+    						password_hash, 
+    						totp_secret, 
+    						totp_enabled, 
+    						role 
+						FROM users WHERE login = $1`, login).
+		Scan(&user.UUID, &user.Login, &user.PasswordHash, &user.TOTPSecret, &user.TOTPEnabled, &user.Role)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Info("User not found", log.StringField("user_login", login))
@@ -78,7 +69,8 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *entity.User) (*en
 		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
 		r.log.StringField("user_login", user.Login),
 	)
-	_, err := r.db.Exec(ctx, `INSERT INTO users (uuid, login, password_hash) VALUES ($1, $2, $3)`, user.UUID, user.Login, user.PasswordHash)
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO users (uuid, login, password_hash, role) VALUES ($1, $2, $3, $4)`,
+		user.UUID, user.Login, user.PasswordHash, user.Role)
 	if err != nil {
 		//check user already exists
 		var pgErr *pgconn.PgError
@@ -104,7 +96,15 @@ func (r *UserRepository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID)
 	)
 
 	var user entity.User
-	err := r.db.QueryRow(ctx, `SELECT uuid, login, password_hash FROM users WHERE uuid = $1`, userUUID).Scan(&user.UUID, &user.Login, &user.PasswordHash)
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    						uuid, 
+    						login, 
+    						password_hash, 
+    						totp_secret, 
+    						totp_enabled, 
+    						role 
+						FROM users WHERE uuid = $1`, userUUID).
+		Scan(&user.UUID, &user.Login, &user.PasswordHash, &user.TOTPSecret, &user.TOTPEnabled, &user.Role)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Info("User not found", log.StringField("user_uuid", userUUID.String()))
@@ -115,3 +115,749 @@ func (r *UserRepository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID)
 	}
 	return &user, nil
 }
+
+// UpdatePassword sets a new password hash for the user.
+func (r *UserRepository) UpdatePassword(ctx context.Context, userUUID uuid.UUID, passwordHash string) error {
+	const op = "infrastructure.postgre.UserRepository.UpdatePassword"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET password_hash = $1 WHERE uuid = $2`, passwordHash, userUUID)
+	if err != nil {
+		log.Error("Failed to update password", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+// CreateSession stores a new session and removes the expired sessions of the user.
+func (r *UserRepository) CreateSession(ctx context.Context, session *entity.Session) error {
+	const op = "infrastructure.postgre.UserRepository.CreateSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", session.UserUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM user_sessions WHERE user_uuid = $1 AND expires_at <= $2`, session.UserUUID, session.CreatedAt)
+	if err != nil {
+		log.Error("Failed to delete expired sessions", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = conn(ctx, r.db).Exec(ctx, `INSERT INTO user_sessions (
+                           uuid,
+                           user_uuid,
+                           created_at,
+                           last_used_at,
+                           expires_at,
+                           ip,
+                           user_agent
+                           ) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		session.UUID,
+		session.UserUUID,
+		session.CreatedAt,
+		session.LastUsedAt,
+		session.ExpiresAt,
+		session.IP,
+		session.UserAgent)
+	if err != nil {
+		log.Error("Failed to create session", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetSession returns an active session by UUID.
+func (r *UserRepository) GetSession(ctx context.Context, sessionUUID uuid.UUID) (*entity.Session, error) {
+	const op = "infrastructure.postgre.UserRepository.GetSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("session_uuid", sessionUUID.String()),
+	)
+
+	var session entity.Session
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						created_at, 
+    						COALESCE(last_used_at, created_at), 
+    						expires_at, 
+    						ip, 
+    						user_agent 
+						FROM 
+						    user_sessions 
+						WHERE 
+						    uuid = $1 
+						  AND 
+						    expires_at > $2`, sessionUUID, time.Now()).
+		Scan(
+			&session.UUID,
+			&session.UserUUID,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+			&session.IP,
+			&session.UserAgent,
+		)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("Session not found")
+			return nil, entity.ErrUserSessionNotFound
+		}
+		log.Error("Failed to get session", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &session, nil
+}
+
+// ListSessions returns the active sessions of the user, the most recently used first.
+func (r *UserRepository) ListSessions(ctx context.Context, userUUID uuid.UUID) ([]entity.Session, error) {
+	const op = "infrastructure.postgre.UserRepository.ListSessions"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						created_at, 
+    						COALESCE(last_used_at, created_at) AS last_used, 
+    						expires_at, 
+    						ip, 
+    						user_agent 
+						FROM 
+						    user_sessions 
+						WHERE 
+						    user_uuid = $1 
+						  AND 
+						    expires_at > $2 
+						ORDER BY last_used DESC`, userUUID, time.Now())
+	if err != nil {
+		log.Error("Failed to get sessions", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var sessions []entity.Session
+	for rows.Next() {
+		var session entity.Session
+		err = rows.Scan(
+			&session.UUID,
+			&session.UserUUID,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+			&session.IP,
+			&session.UserAgent,
+		)
+		if err != nil {
+			log.Error("Failed to scan session", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to read sessions", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sessions, nil
+}
+
+// TouchSession records the last use of a session and the client that used it.
+// To spare writes, a session used by the same client is updated at most once a minute.
+func (r *UserRepository) TouchSession(ctx context.Context, sessionUUID uuid.UUID, ip, userAgent string) error {
+	const op = "infrastructure.postgre.UserRepository.TouchSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("session_uuid", sessionUUID.String()),
+	)
+
+	now := time.Now()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE user_sessions SET 
+                         last_used_at = $4, 
+                         ip = $2, 
+                         user_agent = $3 
+                     WHERE 
+                         uuid = $1 
+                       AND (
+                         last_used_at IS NULL 
+                         OR last_used_at < $5 
+                         OR ip <> $2 
+                         OR user_agent <> $3
+                       )`, sessionUUID, ip, userAgent, now, now.Add(-time.Minute))
+	if err != nil {
+		log.Error("Failed to update session last use", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteSession deletes a session of the user.
+func (r *UserRepository) DeleteSession(ctx context.Context, userUUID uuid.UUID, sessionUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.DeleteSession"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("session_uuid", sessionUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM user_sessions WHERE uuid = $1 AND user_uuid = $2`, sessionUUID, userUUID)
+	if err != nil {
+		log.Error("Failed to delete session", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("Session not found")
+		return entity.ErrUserSessionNotFound
+	}
+	return nil
+}
+
+// DeleteUserSessions deletes all the user sessions except exceptSessionUUID.
+func (r *UserRepository) DeleteUserSessions(ctx context.Context, userUUID uuid.UUID, exceptSessionUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.DeleteUserSessions"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM user_sessions WHERE user_uuid = $1 AND uuid <> $2`, userUUID, exceptSessionUUID)
+	if err != nil {
+		log.Error("Failed to delete sessions", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CreatePasswordResetToken stores a new password reset token.
+// The tokens issued to the user earlier and not used yet are invalidated.
+func (r *UserRepository) CreatePasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error {
+	const op = "infrastructure.postgre.UserRepository.CreatePasswordResetToken"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", token.UserUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM password_reset_tokens WHERE user_uuid = $1 AND used_at IS NULL`, token.UserUUID)
+	if err != nil {
+		log.Error("Failed to invalidate previous tokens", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = conn(ctx, r.db).Exec(ctx, `INSERT INTO password_reset_tokens (
+                                   token_hash,
+                                   user_uuid,
+                                   created_at,
+                                   expires_at
+                                   ) VALUES ($1, $2, $3, $4)`,
+		token.TokenHash,
+		token.UserUUID,
+		token.CreatedAt,
+		token.ExpiresAt)
+	if err != nil {
+		log.Error("Failed to create password reset token", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetPasswordResetToken returns an unused and unexpired password reset token by its hash.
+func (r *UserRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	const op = "infrastructure.postgre.UserRepository.GetPasswordResetToken"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	var token entity.PasswordResetToken
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    						token_hash, 
+    						user_uuid, 
+    						created_at, 
+    						expires_at 
+						FROM 
+						    password_reset_tokens 
+						WHERE 
+						    token_hash = $1 
+						  AND 
+						    used_at IS NULL 
+						  AND 
+						    expires_at > $2`, tokenHash, time.Now()).
+		Scan(&token.TokenHash, &token.UserUUID, &token.CreatedAt, &token.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("Password reset token not found")
+			return nil, entity.ErrPasswordResetTokenInvalid
+		}
+		log.Error("Failed to get password reset token", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks a password reset token as used.
+// Only one of concurrent calls for the same token succeeds.
+func (r *UserRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) error {
+	const op = "infrastructure.postgre.UserRepository.ConsumePasswordResetToken"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE password_reset_tokens SET 
+                                 used_at = $2 
+                             WHERE 
+                                 token_hash = $1 
+                               AND 
+                                 used_at IS NULL 
+                               AND 
+                                 expires_at > $2`, tokenHash, time.Now())
+	if err != nil {
+		log.Error("Failed to consume password reset token", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("Password reset token already used or expired")
+		return entity.ErrPasswordResetTokenInvalid
+	}
+	return nil
+}
+
+// SetTOTP sets the TOTP secret of the user and whether two-factor authentication is enabled.
+func (r *UserRepository) SetTOTP(ctx context.Context, userUUID uuid.UUID, secret string, enabled bool) error {
+	const op = "infrastructure.postgre.UserRepository.SetTOTP"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	// the used TOTP step belongs to the secret, a new secret starts without it
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET 
+                 totp_last_step = CASE WHEN totp_secret = $1 THEN totp_last_step END, 
+                 totp_secret = $1, 
+                 totp_enabled = $2 
+             WHERE uuid = $3`, secret, enabled, userUUID)
+	if err != nil {
+		log.Error("Failed to set TOTP", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all the recovery codes of the user with the given hashes.
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userUUID uuid.UUID, codeHashes []string) error {
+	const op = "infrastructure.postgre.UserRepository.ReplaceRecoveryCodes"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		log.Error("Failed to begin transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Error("Failed to rollback transaction", log.ErrorField(err))
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_uuid = $1`, userUUID)
+	if err != nil {
+		log.Error("Failed to delete recovery codes", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(codeHashes) > 0 {
+		_, err = tx.Exec(ctx, `INSERT INTO user_recovery_codes (user_uuid, code_hash) 
+							SELECT $1, unnest($2::text[])`, userUUID, codeHashes)
+		if err != nil {
+			log.Error("Failed to create recovery codes", log.ErrorField(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("Failed to commit transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code of the user as used.
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, userUUID uuid.UUID, codeHash string) error {
+	const op = "infrastructure.postgre.UserRepository.ConsumeRecoveryCode"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE user_recovery_codes SET 
+                               used_at = now() 
+                           WHERE 
+                               user_uuid = $1 
+                             AND 
+                               code_hash = $2 
+                             AND 
+                               used_at IS NULL`, userUUID, codeHash)
+	if err != nil {
+		log.Error("Failed to consume recovery code", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("Recovery code not found or already used")
+		return entity.ErrUserTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// AddTwoFactorAttempt counts a second factor attempt of the user, the attempts are counted in the window
+// from the first one. It returns ErrUserTwoFactorLocked when the limit is reached.
+func (r *UserRepository) AddTwoFactorAttempt(ctx context.Context, userUUID uuid.UUID, limit int, window time.Duration) error {
+	const op = "infrastructure.postgre.UserRepository.AddTwoFactorAttempt"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	// the times are bound from the application like the stored session and order times,
+	// the zoneless columns keep the application wall clock
+	now := time.Now()
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET 
+                               totp_attempts = CASE 
+                                   WHEN totp_attempts_from IS NULL OR totp_attempts_from <= $4 THEN 1 
+                                   ELSE totp_attempts + 1 END, 
+                               totp_attempts_from = CASE 
+                                   WHEN totp_attempts_from IS NULL OR totp_attempts_from <= $4 THEN $3 
+                                   ELSE totp_attempts_from END 
+                           WHERE 
+                               uuid = $1 
+                             AND (
+                               totp_attempts_from IS NULL 
+                               OR totp_attempts_from <= $4 
+                               OR totp_attempts < $2
+                             )`,
+		userUUID, limit, now, now.Add(-window))
+	if err != nil {
+		log.Error("Failed to count second factor attempt", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("Second factor attempts are used up")
+		return entity.ErrUserTwoFactorLocked
+	}
+	return nil
+}
+
+// ResetTwoFactorAttempts clears the second factor attempts of the user.
+func (r *UserRepository) ResetTwoFactorAttempts(ctx context.Context, userUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.ResetTwoFactorAttempts"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET totp_attempts = 0, totp_attempts_from = NULL WHERE uuid = $1`, userUUID)
+	if err != nil {
+		log.Error("Failed to reset second factor attempts", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code.
+// It returns ErrUserTwoFactorCodeInvalid when the step or a later one is already used.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) error {
+	const op = "infrastructure.postgre.UserRepository.UseTOTPStep"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET 
+                               totp_last_step = $2 
+                           WHERE 
+                               uuid = $1 
+                             AND 
+                               (totp_last_step IS NULL OR totp_last_step < $2)`, userUUID, step)
+	if err != nil {
+		log.Error("Failed to use TOTP step", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("TOTP step already used")
+		return entity.ErrUserTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// CreateAPIKey stores a new API key.
+func (r *UserRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey) error {
+	const op = "infrastructure.postgre.UserRepository.CreateAPIKey"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", key.UserUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO api_keys (
+                      uuid,
+                      user_uuid,
+                      name,
+                      key_hash,
+                      scopes,
+                      created_at
+                      ) VALUES ($1, $2, $3, $4, $5, $6)`,
+		key.UUID,
+		key.UserUUID,
+		key.Name,
+		key.KeyHash,
+		key.Scopes,
+		key.CreatedAt)
+	if err != nil {
+		log.Error("Failed to create API key", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash returns a not revoked API key by its hash.
+func (r *UserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	const op = "infrastructure.postgre.UserRepository.GetAPIKeyByHash"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	var key entity.APIKey
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						name, 
+    						key_hash, 
+    						scopes, 
+    						created_at, 
+    						last_used_at 
+						FROM 
+						    api_keys 
+						WHERE 
+						    key_hash = $1 
+						  AND 
+						    revoked_at IS NULL`, keyHash).
+		Scan(&key.UUID, &key.UserUUID, &key.Name, &key.KeyHash, &key.Scopes, &key.CreatedAt, &key.LastUsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info("API key not found")
+			return nil, entity.ErrAPIKeyNotFound
+		}
+		log.Error("Failed to get API key", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns the not revoked API keys of the user, oldest first.
+func (r *UserRepository) ListAPIKeys(ctx context.Context, userUUID uuid.UUID) ([]entity.APIKey, error) {
+	const op = "infrastructure.postgre.UserRepository.ListAPIKeys"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						name, 
+    						key_hash, 
+    						scopes, 
+    						created_at, 
+    						last_used_at 
+						FROM 
+						    api_keys 
+						WHERE 
+						    user_uuid = $1 
+						  AND 
+						    revoked_at IS NULL 
+						ORDER BY created_at`, userUUID)
+	if err != nil {
+		log.Error("Failed to get API keys", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var keys []entity.APIKey
+	for rows.Next() {
+		var key entity.APIKey
+		err = rows.Scan(&key.UUID, &key.UserUUID, &key.Name, &key.KeyHash, &key.Scopes, &key.CreatedAt, &key.LastUsedAt)
+		if err != nil {
+			log.Error("Failed to scan API key", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to read API keys", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of the user.
+func (r *UserRepository) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.RevokeAPIKey"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("api_key_uuid", keyUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE api_keys SET 
+                    revoked_at = now() 
+                WHERE 
+                    uuid = $1 
+                  AND 
+                    user_uuid = $2 
+                  AND 
+                    revoked_at IS NULL`, keyUUID, userUUID)
+	if err != nil {
+		log.Error("Failed to revoke API key", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("API key not found")
+		return entity.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records the last use of an API key.
+func (r *UserRepository) TouchAPIKey(ctx context.Context, keyUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.TouchAPIKey"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("api_key_uuid", keyUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE api_keys SET last_used_at = now() WHERE uuid = $1`, keyUUID)
+	if err != nil {
+		log.Error("Failed to update API key last use", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// UpdateUserRole sets a new role for the user.
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userUUID uuid.UUID, role entity.Role) error {
+	const op = "infrastructure.postgre.UserRepository.UpdateUserRole"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET role = $1 WHERE uuid = $2`, role, userUUID)
+	if err != nil {
+		log.Error("Failed to update role", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+// AnonymizeUser removes the personal data and credentials of the user and marks the user deleted.
+// The row itself is kept, so the login cannot be used and the UUID is not reused.
+func (r *UserRepository) AnonymizeUser(ctx context.Context, userUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.UserRepository.AnonymizeUser"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		log.Error("Failed to begin transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Error("Failed to rollback transaction", log.ErrorField(err))
+		}
+	}()
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET 
+                 login = 'deleted-' || uuid::text, 
+                 password_hash = '', 
+                 totp_secret = '', 
+                 totp_enabled = false, 
+                 role = $2, 
+                 deleted_at = now() 
+             WHERE 
+                 uuid = $1 
+               AND 
+                 deleted_at IS NULL`, userUUID, entity.RoleUser)
+	if err != nil {
+		log.Error("Failed to anonymize user", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("User not found")
+		return entity.ErrUserNotFound
+	}
+
+	queries := []string{
+		`DELETE FROM user_sessions WHERE user_uuid = $1`,
+		`DELETE FROM user_recovery_codes WHERE user_uuid = $1`,
+		`DELETE FROM password_reset_tokens WHERE user_uuid = $1`,
+		`UPDATE api_keys SET revoked_at = now() WHERE user_uuid = $1 AND revoked_at IS NULL`,
+		`DELETE FROM webhooks WHERE user_uuid = $1`,
+	}
+	for _, query := range queries {
+		_, err = tx.Exec(ctx, query, userUUID)
+		if err != nil {
+			log.Error("Failed to remove user credentials", log.ErrorField(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("Failed to commit transaction", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package postgre
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mbiwapa/gophermart.git/internal/domain/entity"
+	"github.com/mbiwapa/gophermart.git/internal/lib/contexter"
+	"github.com/mbiwapa/gophermart.git/internal/lib/logger"
+)
+
+// WebhookRepository is an implementation of webhook repository.
+type WebhookRepository struct {
+	db  *pgxpool.Pool
+	log *logger.Logger
+}
+
+// NewWebhookRepository returns a new postgre webhook repository
+func NewWebhookRepository(db *pgxpool.Pool, log *logger.Logger) *WebhookRepository {
+	storage := &WebhookRepository{db: db, log: log}
+	return storage
+}
+
+// CreateWebhook stores a new webhook.
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	const op = "infrastructure.postgre.WebhookRepository.CreateWebhook"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", webhook.UserUUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO webhooks (
+                      uuid,
+                      user_uuid,
+                      url,
+                      event_types,
+                      secret,
+                      created_at
+                      ) VALUES ($1, $2, $3, $4, $5, $6)`,
+		webhook.UUID,
+		webhook.UserUUID,
+		webhook.URL,
+		eventTypesToText(webhook.EventTypes),
+		webhook.Secret,
+		webhook.CreatedAt,
+	)
+	if err != nil {
+		log.Error("Failed to create webhook", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ListWebhooks returns the webhooks of the user, oldest first.
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, userUUID uuid.UUID) ([]entity.Webhook, error) {
+	const op = "infrastructure.postgre.WebhookRepository.ListWebhooks"
+	return r.queryWebhooks(ctx, op, userUUID, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						url, 
+    						event_types, 
+    						secret, 
+    						created_at 
+						FROM 
+						    webhooks 
+						WHERE 
+						    user_uuid = $1 
+						ORDER BY created_at`, userUUID)
+}
+
+// ListEventWebhooks returns the webhooks of the user subscribed to the event type.
+func (r *WebhookRepository) ListEventWebhooks(ctx context.Context, userUUID uuid.UUID, eventType entity.EventType) ([]entity.Webhook, error) {
+	const op = "infrastructure.postgre.WebhookRepository.ListEventWebhooks"
+	return r.queryWebhooks(ctx, op, userUUID, `SELECT 
+    						uuid, 
+    						user_uuid, 
+    						url, 
+    						event_types, 
+    						secret, 
+    						created_at 
+						FROM 
+						    webhooks 
+						WHERE 
+						    user_uuid = $1 
+						  AND 
+						    $2 = ANY(event_types) 
+						ORDER BY created_at`, userUUID, string(eventType))
+}
+
+// queryWebhooks runs a query selecting webhooks.
+func (r *WebhookRepository) queryWebhooks(ctx context.Context, op string, userUUID uuid.UUID, query string, args ...any) ([]entity.Webhook, error) {
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+	)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		log.Error("Failed to get webhooks", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var webhooks []entity.Webhook
+	for rows.Next() {
+		var webhook entity.Webhook
+		var eventTypes []string
+		err = rows.Scan(&webhook.UUID, &webhook.UserUUID, &webhook.URL, &eventTypes, &webhook.Secret, &webhook.CreatedAt)
+		if err != nil {
+			log.Error("Failed to scan webhook", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, eventType := range eventTypes {
+			webhook.EventTypes = append(webhook.EventTypes, entity.EventType(eventType))
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to read webhooks", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a webhook of the user, its deliveries are deleted with it.
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, userUUID, webhookUUID uuid.UUID) error {
+	const op = "infrastructure.postgre.WebhookRepository.DeleteWebhook"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("webhook_uuid", webhookUUID.String()),
+	)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM webhooks WHERE uuid = $1 AND user_uuid = $2`, webhookUUID, userUUID)
+	if err != nil {
+		log.Error("Failed to delete webhook", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.Info("Webhook not found")
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// AddDeliveries stores new deliveries in one batch.
+func (r *WebhookRepository) AddDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	const op = "infrastructure.postgre.WebhookRepository.AddDeliveries"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	batch := &pgx.Batch{}
+	for _, delivery := range deliveries {
+		batch.Queue(`INSERT INTO webhook_deliveries (
+                      uuid,
+                      webhook_uuid,
+                      event_type,
+                      payload,
+                      status,
+                      attempts,
+                      next_attempt_at,
+                      created_at
+                      ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			delivery.UUID,
+			delivery.WebhookUUID,
+			delivery.EventType,
+			delivery.Payload,
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			delivery.CreatedAt,
+		)
+	}
+	err := conn(ctx, r.db).SendBatch(ctx, batch).Close()
+	if err != nil {
+		log.Error("Failed to add webhook deliveries", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due, with their target.
+// The claimed deliveries are postponed by lease, so concurrent workers skip them.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	const op = "infrastructure.postgre.WebhookRepository.ClaimDueDeliveries"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+	)
+
+	rows, err := conn(ctx, r.db).Query(ctx, `WITH due AS (
+			SELECT uuid FROM webhook_deliveries 
+			WHERE status = $1 AND next_attempt_at <= $2 
+			ORDER BY next_attempt_at 
+			LIMIT $3 
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET 
+			next_attempt_at = $4 
+		FROM due, webhooks w 
+		WHERE d.uuid = due.uuid AND w.uuid = d.webhook_uuid 
+		RETURNING 
+			d.uuid, 
+			d.webhook_uuid, 
+			d.event_type, 
+			d.payload, 
+			d.status, 
+			d.attempts, 
+			d.next_attempt_at, 
+			d.last_error, 
+			d.response_code, 
+			d.created_at, 
+			d.delivered_at, 
+			w.url, 
+			w.secret`,
+		entity.WebhookDeliveryPending, time.Now(), limit, time.Now().Add(lease))
+	if err != nil {
+		log.Error("Failed to claim webhook deliveries", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		var delivery entity.WebhookDelivery
+		err = rows.Scan(
+			&delivery.UUID,
+			&delivery.WebhookUUID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastError,
+			&delivery.ResponseCode,
+			&delivery.CreatedAt,
+			&delivery.DeliveredAt,
+			&delivery.URL,
+			&delivery.Secret,
+		)
+		if err != nil {
+			log.Error("Failed to scan webhook delivery", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to read webhook deliveries", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	const op = "infrastructure.postgre.WebhookRepository.UpdateDelivery"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("delivery_uuid", delivery.UUID.String()),
+	)
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE webhook_deliveries SET 
+                    status = $2, 
+                    attempts = $3, 
+                    next_attempt_at = $4, 
+                    last_error = $5, 
+                    response_code = $6, 
+                    delivered_at = $7 
+                WHERE 
+                    uuid = $1`,
+		delivery.UUID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.ResponseCode,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		log.Error("Failed to update webhook delivery", log.ErrorField(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ListDeliveries returns up to limit latest deliveries of a webhook of the user, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, userUUID, webhookUUID uuid.UUID, limit int) ([]entity.WebhookDelivery, error) {
+	const op = "infrastructure.postgre.WebhookRepository.ListDeliveries"
+	log := r.log.With(
+		r.log.StringField("op", op),
+		r.log.StringField("request_id", contexter.GetRequestID(ctx)),
+		r.log.StringField("user_uuid", userUUID.String()),
+		r.log.StringField("webhook_uuid", webhookUUID.String()),
+	)
+
+	var exists bool
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhooks WHERE uuid = $1 AND user_uuid = $2)`,
+		webhookUUID, userUUID).Scan(&exists)
+	if err != nil {
+		log.Error("Failed to get webhook", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		log.Info("Webhook not found")
+		return nil, entity.ErrWebhookNotFound
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT 
+    						uuid, 
+    						webhook_uuid, 
+    						event_type, 
+    						payload, 
+    						status, 
+    						attempts, 
+    						next_attempt_at, 
+    						last_error, 
+    						response_code, 
+    						created_at, 
+    						delivered_at 
+						FROM 
+						    webhook_deliveries 
+						WHERE 
+						    webhook_uuid = $1 
+						ORDER BY created_at DESC 
+						LIMIT $2`, webhookUUID, limit)
+	if err != nil {
+		log.Error("Failed to get webhook deliveries", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		var delivery entity.WebhookDelivery
+		err = rows.Scan(
+			&delivery.UUID,
+			&delivery.WebhookUUID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastError,
+			&delivery.ResponseCode,
+			&delivery.CreatedAt,
+			&delivery.DeliveredAt,
+		)
+		if err != nil {
+			log.Error("Failed to scan webhook delivery", log.ErrorField(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to read webhook deliveries", log.ErrorField(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// eventTypesToText converts event types to a text array.
+func eventTypesToText(eventTypes []entity.EventType) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package contexter
+
+import "context"
+
+var (
+	// RequiredScope is the context key for the API key scope required by the route.
+	RequiredScope = ctxKey("required_scope")
+	// RequiredPermission is the context key for the role permission required by the route.
+	RequiredPermission = ctxKey("required_permission")
+)
+
+// GetRequiredScope returns the API key scope required by the route, or an empty string if the route
+// does not accept API keys.
+func GetRequiredScope(ctx context.Context) string {
+	scope, _ := ctx.Value(RequiredScope).(string)
+	return scope
+}
+
+// GetRequiredPermission returns the role permission required by the route, or an empty string if the route
+// is available to every authenticated user.
+func GetRequiredPermission(ctx context.Context) string {
+	permission, _ := ctx.Value(RequiredPermission).(string)
+	return permission
+}
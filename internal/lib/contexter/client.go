@@ -0,0 +1,22 @@
+package contexter
+
+import "context"
+
+var (
+	// ClientIP is the context key for the IP address of the client.
+	ClientIP = ctxKey("client_ip")
+	// UserAgent is the context key for the User-Agent of the client.
+	UserAgent = ctxKey("user_agent")
+)
+
+// GetClientIP returns the IP address of the client, or an empty string if it is unknown.
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIP).(string)
+	return ip
+}
+
+// GetUserAgent returns the User-Agent of the client, or an empty string if it is unknown.
+func GetUserAgent(ctx context.Context) string {
+	userAgent, _ := ctx.Value(UserAgent).(string)
+	return userAgent
+}
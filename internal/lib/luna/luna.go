@@ -1,16 +1,24 @@
 package luna
 
-func Valid(number int) bool {
-	return (number%10+checksum(number/10))%10 == 0
+// Valid reports whether the number is a string of digits with a valid Luhn checksum.
+// The number is checked digit by digit, so it may be of any length and have leading zeros.
+func Valid(number string) bool {
+	if number == "" {
+		return false
+	}
+	return checksum(number) == 0
 }
 
-func checksum(number int) int {
+func checksum(number string) int {
 	var luhn int
 
-	for i := 0; number > 0; i++ {
-		cur := number % 10
+	for i := 0; i < len(number); i++ {
+		cur := int(number[len(number)-1-i] - '0')
+		if cur < 0 || cur > 9 {
+			return -1
+		}
 
-		if i%2 == 0 { // even
+		if i%2 == 1 { // every second digit from the check digit
 			cur = cur * 2
 			if cur > 9 {
 				cur = cur%10 + cur/10
@@ -18,7 +26,6 @@ func checksum(number int) int {
 		}
 
 		luhn += cur
-		number = number / 10
 	}
 	return luhn % 10
 }
@@ -0,0 +1,21 @@
+package luna_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/luna"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, luna.Valid("12345678903"))
+	assert.True(t, luna.Valid("79927398713"))
+	// leading zeros do not change the checksum
+	assert.True(t, luna.Valid("0079927398713"))
+	// longer than an int64
+	assert.True(t, luna.Valid("12345678901234567890123456789012345678902"))
+	assert.False(t, luna.Valid("79927398710"))
+	assert.False(t, luna.Valid(""))
+	assert.False(t, luna.Valid("7992739871a"))
+}
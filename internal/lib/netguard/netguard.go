@@ -0,0 +1,46 @@
+// Package netguard keeps the requests sent to user given URLs away from the internal network.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"syscall"
+)
+
+// ErrAddressForbidden is returned for a loopback, private, link-local or unspecified address.
+var ErrAddressForbidden = errors.New("address is not public")
+
+// reserved are the ranges not covered by the netip checks that still reach hosts inside the network.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Public reports whether the address is a public unicast address.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control is a net.Dialer control function refusing connections to the addresses that are not public.
+// It runs with the resolved address right before connecting, so a host resolved again to another address is checked too.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressForbidden, address)
+	}
+	if !Public(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressForbidden, addrPort.Addr())
+	}
+	return nil
+}
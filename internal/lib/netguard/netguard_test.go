@@ -0,0 +1,26 @@
+package netguard_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/netguard"
+)
+
+func TestPublic(t *testing.T) {
+	for _, address := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1", "::1", "::", "fe80::1", "fc00::1", "::ffff:127.0.0.1"} {
+		assert.False(t, netguard.Public(netip.MustParseAddr(address)), address)
+	}
+	for _, address := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111"} {
+		assert.True(t, netguard.Public(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestControl(t *testing.T) {
+	assert.ErrorIs(t, netguard.Control("tcp4", "127.0.0.1:5432", nil), netguard.ErrAddressForbidden)
+	assert.ErrorIs(t, netguard.Control("tcp6", "[fe80::1]:80", nil), netguard.ErrAddressForbidden)
+	assert.ErrorIs(t, netguard.Control("tcp", "localhost:80", nil), netguard.ErrAddressForbidden)
+	assert.NoError(t, netguard.Control("tcp4", "93.184.216.34:443", nil))
+}
@@ -0,0 +1,75 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mbiwapa/gophermart.git/internal/lib/totp"
+)
+
+// rfcSecret is the SHA1 key from the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 Appendix B, the last 6 digits of the 8-digit SHA1 codes.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tc := range tests {
+		got, err := totp.Code(rfcSecret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "time %d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current, err := totp.Code(rfcSecret, now)
+	require.NoError(t, err)
+	previous, err := totp.Code(rfcSecret, now.Add(-totp.Period))
+	require.NoError(t, err)
+	old, err := totp.Code(rfcSecret, now.Add(-3*totp.Period))
+	require.NoError(t, err)
+
+	assert.True(t, totp.Validate(rfcSecret, current, now))
+	assert.True(t, totp.Validate(rfcSecret, previous, now), "one step of clock skew is accepted")
+	assert.False(t, totp.Validate(rfcSecret, old, now))
+	assert.False(t, totp.Validate(rfcSecret, "12345", now))
+	assert.False(t, totp.Validate("not base32!", current, now))
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / int64(totp.Period.Seconds())
+	previous, err := totp.Code(rfcSecret, now.Add(-totp.Period))
+	require.NoError(t, err)
+
+	got, ok := totp.Match(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, got)
+	_, ok = totp.Match(rfcSecret, "000000", now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	_, err = totp.Code(secret, time.Now())
+	assert.NoError(t, err)
+
+	uri := totp.URI("Gophermart", "user@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Gophermart:user@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+}